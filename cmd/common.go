@@ -3,7 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/purpledb/purple"
+	_ "github.com/purpledb/purple/internal/backend" // registers the built-in backends
+	"github.com/purpledb/purple/services"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -14,6 +19,160 @@ func BindFlagsToCmd(cmd *cobra.Command, flags *pflag.FlagSet, v *viper.Viper) {
 	ExitOnError(v.BindPFlags(flags))
 }
 
+// AddBackendFlags registers the flag for selecting one of the registered backends, a flag per service for routing that
+// service to a different backend (e.g. --kv-backend), and a repeatable flag for passing settings to backends that
+// don't have dedicated flags of their own.
+func AddBackendFlags(flags *pflag.FlagSet, v *viper.Viper) {
+	names := strings.Join(services.Names(), ", ")
+
+	flags.String("backend", "disk", fmt.Sprintf("Data backend (options are %s)", names))
+
+	for _, svc := range purple.Services {
+		flag := svc + "-backend"
+		flags.String(flag, "", fmt.Sprintf("Data backend for the %s service, if different from --backend (options are %s)", svc, names))
+		ExitOnError(v.BindPFlag("services."+svc, flags.Lookup(flag)))
+	}
+
+	flags.StringToString("backend-option", nil, "Backend setting as <backend>.<setting>=<value> (repeatable)")
+
+	ExitOnError(v.BindPFlag("options", flags.Lookup("backend-option")))
+}
+
+// AddDiskFlags registers the disk backend flags and binds each of them to its key in the "disk" config section, which
+// also makes them settable via environment variables such as PURPLE_GRPC_DISK_PATH.
+func AddDiskFlags(flags *pflag.FlagSet, v *viper.Viper) {
+	addDiskFlags(flags, v, "")
+}
+
+// AddMemoryFlags registers the memory backend's expiry, eviction, and persistence flags and binds each of them to its
+// key in the "memory" config section.
+func AddMemoryFlags(flags *pflag.FlagSet, v *viper.Viper) {
+	addMemoryFlags(flags, v, "")
+}
+
+// AddSqliteFlags registers the SQLite backend flags and binds each of them to its key in the "sqlite" config section.
+func AddSqliteFlags(flags *pflag.FlagSet, v *viper.Viper) {
+	addSqliteFlags(flags, v, "")
+}
+
+// AddBoltFlags registers the bolt backend flags and binds each of them to its key in the "bolt" config section.
+func AddBoltFlags(flags *pflag.FlagSet, v *viper.Viper) {
+	addBoltFlags(flags, v, "")
+}
+
+// AddBackendSettingFlags registers the settings of every built-in backend under a prefix, for commands that open more
+// than one backend. The flags are named like the servers' with the prefix in front (e.g. --from-disk-path) and are
+// bound to the same keys within the prefix's config section (e.g. from.disk.path).
+func AddBackendSettingFlags(flags *pflag.FlagSet, v *viper.Viper, prefix string) {
+	p := flagPrefix(prefix)
+
+	flags.String(p.flag("redis-url"), "redis://127.0.0.1:6379", "Redis connection URL (if redis backend is used)")
+	flags.String(p.flag("redis-prefix"), "purple", "Prefix for all keys stored in Redis (if redis backend is used)")
+
+	bindFlags(flags, v, p, map[string]string{
+		"redisurl":    "redis-url",
+		"redisprefix": "redis-prefix",
+	})
+
+	addDiskFlags(flags, v, p)
+	addMemoryFlags(flags, v, p)
+	addSqliteFlags(flags, v, p)
+	addBoltFlags(flags, v, p)
+}
+
+// A prefix that's put in front of flag names and config keys, or nothing if it's empty
+type flagPrefix string
+
+func (p flagPrefix) flag(name string) string {
+	if p == "" {
+		return name
+	}
+
+	return string(p) + "-" + name
+}
+
+func (p flagPrefix) key(key string) string {
+	if p == "" {
+		return key
+	}
+
+	return string(p) + "." + key
+}
+
+// Binds each config key to the flag that sets it
+func bindFlags(flags *pflag.FlagSet, v *viper.Viper, p flagPrefix, keys map[string]string) {
+	for key, flag := range keys {
+		ExitOnError(v.BindPFlag(p.key(key), flags.Lookup(p.flag(flag))))
+	}
+}
+
+func addDiskFlags(flags *pflag.FlagSet, v *viper.Viper, p flagPrefix) {
+	flags.String(p.flag("disk-path"), "tmp/purple", "Root data directory (if disk backend is used)")
+	flags.Bool(p.flag("disk-sync-writes"), true, "Sync every write to disk before acknowledging it (if disk backend is used)")
+	flags.Int64(p.flag("disk-value-log-file-size"), 0, "Maximum size of each value log file in bytes, 0 for the Badger default (if disk backend is used)")
+	flags.Bool(p.flag("disk-in-memory"), false, "Keep data in a temporary directory that's removed on shutdown (if disk backend is used)")
+	flags.Bool(p.flag("disk-read-only"), false, "Open the data directory in read-only mode (if disk backend is used)")
+	flags.String(p.flag("disk-backup-dir"), "tmp/purple-backups", "Directory that online backups are written to (if disk backend is used)")
+
+	bindFlags(flags, v, p, map[string]string{
+		"disk.path":             "disk-path",
+		"disk.syncwrites":       "disk-sync-writes",
+		"disk.valuelogfilesize": "disk-value-log-file-size",
+		"disk.inmemory":         "disk-in-memory",
+		"disk.readonly":         "disk-read-only",
+		"disk.backupdir":        "disk-backup-dir",
+	})
+}
+
+func addMemoryFlags(flags *pflag.FlagSet, v *viper.Viper, p flagPrefix) {
+	flags.Duration(p.flag("memory-expiry-interval"), time.Minute, "How often expired cache items are deleted (if memory backend is used)")
+	flags.Int(p.flag("memory-cache-max-entries"), 0, "Maximum number of cache items, 0 for no limit (if memory backend is used)")
+	flags.String(p.flag("memory-cache-eviction"), purple.CacheEvictionLRU, "Which cache items are evicted at the limit: lru or lfu (if memory backend is used)")
+	flags.String(p.flag("memory-snapshot-path"), "", "File that snapshots are written to, empty to disable snapshots (if memory backend is used)")
+	flags.Duration(p.flag("memory-snapshot-interval"), 0, "How often a snapshot is written, 0 to only write one on shutdown (if memory backend is used)")
+	flags.String(p.flag("memory-aof-path"), "", "Append-only file that every mutation is logged to, empty to disable the log (if memory backend is used)")
+	flags.String(p.flag("memory-aof-sync"), purple.AOFSyncEverySec, "When the append-only file is synced: always, everysec, or no (if memory backend is used)")
+	flags.Int64(p.flag("memory-aof-rewrite-size"), 64<<20, "Size in bytes past which the append-only file is compacted, 0 to disable (if memory backend is used)")
+
+	bindFlags(flags, v, p, map[string]string{
+		"memory.expiryinterval":   "memory-expiry-interval",
+		"memory.cachemaxentries":  "memory-cache-max-entries",
+		"memory.cacheeviction":    "memory-cache-eviction",
+		"memory.snapshotpath":     "memory-snapshot-path",
+		"memory.snapshotinterval": "memory-snapshot-interval",
+		"memory.aofpath":          "memory-aof-path",
+		"memory.aofsync":          "memory-aof-sync",
+		"memory.aofrewritesize":   "memory-aof-rewrite-size",
+	})
+}
+
+func addSqliteFlags(flags *pflag.FlagSet, v *viper.Viper, p flagPrefix) {
+	flags.String(p.flag("sqlite-path"), "tmp/purple.db", "Database file (if sqlite backend is used)")
+	flags.Duration(p.flag("sqlite-cleanup-interval"), time.Minute, "How often expired cache entries are deleted (if sqlite backend is used)")
+
+	bindFlags(flags, v, p, map[string]string{
+		"sqlite.path":            "sqlite-path",
+		"sqlite.cleanupinterval": "sqlite-cleanup-interval",
+	})
+}
+
+func addBoltFlags(flags *pflag.FlagSet, v *viper.Viper, p flagPrefix) {
+	flags.String(p.flag("bolt-path"), "tmp/purple.bolt", "Database file (if bolt backend is used)")
+	flags.Duration(p.flag("bolt-cleanup-interval"), time.Minute, "How often expired cache items are deleted (if bolt backend is used)")
+
+	bindFlags(flags, v, p, map[string]string{
+		"bolt.path":            "bolt-path",
+		"bolt.cleanupinterval": "bolt-cleanup-interval",
+	})
+}
+
+// AddTieredFlags registers the flag for placing a size-bounded in-memory LRU in front of the configured backend.
+func AddTieredFlags(flags *pflag.FlagSet, v *viper.Viper) {
+	flags.Int("tiered-size", 0, "Number of entries kept in an in-memory LRU in front of the backend, 0 to disable")
+
+	ExitOnError(v.BindPFlag("tiered.size", flags.Lookup("tiered-size")))
+}
+
 func ExitOnError(err error) {
 	if err != nil {
 		fmt.Println(err)
@@ -25,5 +184,6 @@ func NewConfig(envPrefix string) *viper.Viper {
 	v := viper.New()
 	v.AutomaticEnv()
 	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
 	return v
 }
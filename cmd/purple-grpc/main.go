@@ -28,10 +28,20 @@ func command() *cobra.Command {
 	flags := pflag.NewFlagSet("purple-grpc", pflag.ExitOnError)
 	flags.IntP("port", "p", 8081, "Purple server port")
 	flags.Bool("debug", false, "Debug mode")
-	flags.String("backend", "disk", `Data backend (options are "disk" and "memory")`)
+	cmd.AddBackendFlags(flags, v)
 	flags.String("redis-url", "redis://127.0.0.1:6379", "Redis connection URL (if redis backend is used)")
+	flags.String("redis-prefix", "purple", "Prefix for all keys stored in Redis (if redis backend is used)")
+
+	cmd.AddDiskFlags(flags, v)
+	cmd.AddMemoryFlags(flags, v)
+	cmd.AddSqliteFlags(flags, v)
+	cmd.AddBoltFlags(flags, v)
+	cmd.AddTieredFlags(flags, v)
+	flags.Int("txn-max-sessions", 10000, "Maximum number of transaction sessions open at once, 0 for no limit")
 
 	v.RegisterAlias("redisurl", "redis-url")
+	v.RegisterAlias("redisprefix", "redis-prefix")
+	v.RegisterAlias("txnmaxsessions", "txn-max-sessions")
 
 	cmd.BindFlagsToCmd(command, flags, v)
 
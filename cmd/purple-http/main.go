@@ -28,10 +28,18 @@ func command() *cobra.Command {
 	flags := pflag.NewFlagSet("purple-http", pflag.ExitOnError)
 	flags.IntP("port", "p", 8080, "purple HTTP server port")
 	flags.Bool("debug", false, "Debug mode")
-	flags.String("backend", "disk", `Data backend (options are disk, memory, and redis)`)
+	cmd.AddBackendFlags(flags, v)
 	flags.String("redis-url", "redis://127.0.0.1:6379", "Redis connection URL (if redis backend is used)")
+	flags.String("redis-prefix", "purple", "Prefix for all keys stored in Redis (if redis backend is used)")
+
+	cmd.AddDiskFlags(flags, v)
+	cmd.AddMemoryFlags(flags, v)
+	cmd.AddSqliteFlags(flags, v)
+	cmd.AddBoltFlags(flags, v)
+	cmd.AddTieredFlags(flags, v)
 
 	v.RegisterAlias("redisurl", "redis-url")
+	v.RegisterAlias("redisprefix", "redis-prefix")
 
 	cmd.BindFlagsToCmd(command, flags, v)
 
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/cmd"
+	"github.com/purpledb/purple/internal/backend"
+	"github.com/purpledb/purple/internal/backend/disk"
+	"github.com/purpledb/purple/internal/migrate"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// The source and destination each have their own backend settings, which are set with the --from-* and --to-* flags
+// (e.g. --from-disk-path), so data can also be moved between two stores of the same backend.
+type config struct {
+	From   purple.ServerConfig
+	To     purple.ServerConfig
+	DryRun bool
+	Verify bool
+}
+
+func command() *cobra.Command {
+	var cfg config
+
+	v := cmd.NewConfig("purple_migrate")
+
+	command := &cobra.Command{
+		Use:   "purple-migrate",
+		Short: "Copy all data from one purple backend to another",
+		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+			cmd.ExitOnError(v.Unmarshal(&cfg))
+		},
+		Run: func(_ *cobra.Command, _ []string) {
+			cmd.ExitOnError(run(&cfg))
+		},
+	}
+
+	flags := pflag.NewFlagSet("purple-migrate", pflag.ExitOnError)
+	flags.String("from", "", "Backend to copy or export data from")
+	flags.String("to", "", "Backend to copy or import data to")
+
+	cmd.ExitOnError(v.BindPFlag("from.backend", flags.Lookup("from")))
+	cmd.ExitOnError(v.BindPFlag("to.backend", flags.Lookup("to")))
+
+	cmd.AddBackendSettingFlags(flags, v, "from")
+	cmd.AddBackendSettingFlags(flags, v, "to")
+
+	// The backend flags are shared with the export and import subcommands
+	command.PersistentFlags().AddFlagSet(flags)
+
+	copyFlags := pflag.NewFlagSet("copy", pflag.ExitOnError)
+	copyFlags.Bool("dry-run", false, "Read and count the source's data without writing anything")
+	copyFlags.Bool("verify", true, "Compare entry counts and checksums of both backends after copying")
+
+	v.RegisterAlias("dryrun", "dry-run")
+
+	cmd.BindFlagsToCmd(command, copyFlags, v)
+
+	command.AddCommand(exportCommand(&cfg), importCommand(&cfg), backupCommand(&cfg), restoreCommand(&cfg))
+
+	return command
+}
+
+// The export and import flags are only read by their own subcommand, so they aren't bound to the shared config.
+func exportCommand(cfg *config) *cobra.Command {
+	var file string
+
+	command := &cobra.Command{
+		Use:   "export",
+		Short: "Write all data in the --from backend to a newline-delimited JSON dump",
+		Run: func(_ *cobra.Command, _ []string) {
+			cmd.ExitOnError(export(cfg, file))
+		},
+	}
+
+	command.Flags().StringVar(&file, "file", "-", "Dump file to write, - for stdout")
+
+	return command
+}
+
+func importCommand(cfg *config) *cobra.Command {
+	var file, mode string
+
+	command := &cobra.Command{
+		Use:   "import",
+		Short: "Write the records of a newline-delimited JSON dump into the --to backend",
+		Run: func(_ *cobra.Command, _ []string) {
+			cmd.ExitOnError(importDump(cfg, file, migrate.ImportMode(mode)))
+		},
+	}
+
+	command.Flags().StringVar(&file, "file", "-", "Dump file to read, - for stdin")
+	command.Flags().StringVar(&mode, "mode", string(migrate.Merge), "merge to keep existing data or replace to flush the backend first")
+
+	return command
+}
+
+// Backups taken here open the DB directly, so the server must not be running against it. Use the admin backup endpoint
+// to back up a running server.
+func backupCommand(cfg *config) *cobra.Command {
+	var since uint64
+
+	command := &cobra.Command{
+		Use:   "backup",
+		Short: "Write a full or incremental backup of the disk backend at --from-disk-path to --from-disk-backup-dir",
+		Run: func(_ *cobra.Command, _ []string) {
+			cmd.ExitOnError(backup(cfg, since))
+		},
+	}
+
+	command.Flags().Uint64Var(&since, "since", 0, "Version to start from (the next version reported by a previous backup), 0 for a full backup")
+
+	return command
+}
+
+func restoreCommand(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore",
+		Short: "Rebuild the disk backend at --to-disk-path from the backup files in --to-disk-backup-dir",
+		Run: func(_ *cobra.Command, _ []string) {
+			cmd.ExitOnError(restore(cfg))
+		},
+	}
+}
+
+func run(cfg *config) error {
+	if cfg.From.Backend == "" || cfg.To.Backend == "" {
+		return purple.ErrNoBackend
+	}
+
+	if sameStore(&cfg.From, &cfg.To) {
+		return purple.ErrSameBackend
+	}
+
+	from, err := open(&cfg.From)
+	if err != nil {
+		return err
+	}
+	defer from.Close()
+
+	to, err := open(&cfg.To)
+	if err != nil {
+		return err
+	}
+	defer to.Close()
+
+	m := &migrate.Migration{
+		From:   from,
+		To:     to,
+		DryRun: cfg.DryRun,
+		Progress: func(service string, count int) {
+			fmt.Printf("%s: %d entries\n", service, count)
+		},
+	}
+
+	counts, err := m.Run()
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	if cfg.DryRun {
+		fmt.Printf("dry run complete: %d entries would be copied from %s to %s\n", total, cfg.From.Backend, cfg.To.Backend)
+		return nil
+	}
+
+	fmt.Printf("copied %d entries from %s to %s\n", total, cfg.From.Backend, cfg.To.Backend)
+
+	if cfg.Verify {
+		if err := m.Verify(); err != nil {
+			return err
+		}
+
+		fmt.Println("verification passed")
+	}
+
+	return nil
+}
+
+func export(cfg *config, file string) error {
+	if cfg.From.Backend == "" {
+		return purple.ErrNoBackend
+	}
+
+	from, err := open(&cfg.From)
+	if err != nil {
+		return err
+	}
+	defer from.Close()
+
+	w := os.Stdout
+
+	if file != "-" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	count, err := migrate.WriteDump(from, w)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d records from %s\n", count, cfg.From.Backend)
+
+	return nil
+}
+
+func importDump(cfg *config, file string, mode migrate.ImportMode) error {
+	if cfg.To.Backend == "" {
+		return purple.ErrNoBackend
+	}
+
+	r := os.Stdin
+
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	to, err := open(&cfg.To)
+	if err != nil {
+		return err
+	}
+	defer to.Close()
+
+	count, err := migrate.Import(to, r, mode)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "imported %d records into %s (%s)\n", count, cfg.To.Backend, mode)
+
+	return nil
+}
+
+func backup(cfg *config, since uint64) error {
+	d, err := disk.NewDiskBackend(&cfg.From.Disk)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	info, err := d.Backup(since)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %s, pass --since %d for the next incremental backup\n", info.Path, info.Next)
+
+	return nil
+}
+
+func restore(cfg *config) error {
+	count, err := disk.Restore(cfg.To.Disk.BackupDir, cfg.To.Disk.Path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("restored %d backup files from %s into %s\n", count, cfg.To.Disk.BackupDir, cfg.To.Disk.Path)
+
+	return nil
+}
+
+// Opens the backend that cfg describes. A memory backend only holds data that outlives this command if it's restored
+// from and persisted to a snapshot or append-only file, so it's rejected without one.
+func open(cfg *purple.ServerConfig) (*backend.Backend, error) {
+	if cfg.Backend == "memory" && cfg.Memory.SnapshotPath == "" && cfg.Memory.AOFPath == "" {
+		return nil, purple.ErrMemoryNotPersistent
+	}
+
+	return backend.NewBackend(cfg)
+}
+
+// Reports whether two configs point at the same store, which would make a copy read and write the same data.
+func sameStore(a, b *purple.ServerConfig) bool {
+	if a.Backend != b.Backend {
+		return false
+	}
+
+	switch a.Backend {
+	case "disk":
+		return a.Disk.Path == b.Disk.Path
+	case "sqlite":
+		return a.Sqlite.Path == b.Sqlite.Path
+	case "bolt":
+		return a.Bolt.Path == b.Bolt.Path
+	case "redis":
+		return a.RedisUrl == b.RedisUrl && a.RedisPrefix == b.RedisPrefix
+	case "memory":
+		return a.Memory.SnapshotPath == b.Memory.SnapshotPath && a.Memory.AOFPath == b.Memory.AOFPath
+	default:
+		return reflect.DeepEqual(a.Section(a.Backend), b.Section(b.Backend))
+	}
+}
+
+func main() {
+	cmd.ExitOnError(command().Execute())
+}
@@ -1,12 +1,136 @@
 package purple
 
+import (
+	"os"
+	"strings"
+	"time"
+)
+
 type ServerConfig struct {
 	Port     int
 	Debug    bool
 	Backend  string
 	RedisUrl string
+	// The prefix that namespaces all of purple's keys in Redis
+	RedisPrefix string
+	Disk        DiskConfig
+	Memory      MemoryConfig
+	Sqlite      SqliteConfig
+	Bolt        BoltConfig
+	Tiered      TieredConfig
+	// Per-service overrides of Backend
+	Services ServiceBackends
+	// Settings for backends without a typed section of their own, keyed as "<backend>.<setting>"
+	Options map[string]string
+	// The maximum number of transaction sessions open at once. Zero means no limit.
+	TxnMaxSessions int
+}
+
+// ServiceBackends routes individual services to a backend other than the server's default backend. Empty fields fall
+// back to the default.
+type ServiceBackends struct {
+	Cache   string
+	Counter string
+	Flag    string
+	KV      string
+	Set     string
+}
+
+// DiskConfig holds the settings for the Badger-based disk backend.
+type DiskConfig struct {
+	// The root directory under which the database is stored. Relative paths are resolved against the working
+	// directory of the server process.
+	Path string
+	// Whether Badger syncs every write to disk before acknowledging it.
+	SyncWrites bool
+	// The maximum size of each value log file in bytes. Zero means Badger's default.
+	ValueLogFileSize int64
+	// Whether data is kept in a throwaway directory that's removed when the backend is closed.
+	InMemory bool
+	// Whether the databases are opened in read-only mode.
+	ReadOnly bool
+	// The directory that online backups are written to.
+	BackupDir string
+}
+
+// Services lists the names of all data services, which are used to route services to backends.
+var Services = []string{"cache", "counter", "flag", "kv", "set"}
+
+// backendRegistered reports whether a backend is registered under a name. The registry itself lives in the services
+// package, which imports this one, so the services package installs its lookup with SetBackendRegistry.
+var backendRegistered = func(string) bool { return false }
+
+// SetBackendRegistry installs the lookup that Validate uses to check backend names. It's called when the services
+// package is loaded and doesn't need to be called directly.
+func SetBackendRegistry(registered func(name string) bool) {
+	backendRegistered = registered
+}
+
+// SqliteConfig holds the settings for the SQLite backend.
+type SqliteConfig struct {
+	// The path of the database file, which is created if it doesn't exist
+	Path string
+	// How often expired cache entries are deleted. Zero means once a minute.
+	CleanupInterval time.Duration
 }
 
+// BoltConfig holds the settings for the bbolt backend.
+type BoltConfig struct {
+	// The path of the database file, which is created if it doesn't exist
+	Path string
+	// How often expired cache items are deleted. Zero means once a minute.
+	CleanupInterval time.Duration
+}
+
+// MemoryConfig holds the expiry, eviction, and persistence settings for the memory backend. With neither a snapshot
+// path nor an AOF path, all data is lost when the backend is closed.
+type MemoryConfig struct {
+	// How often expired cache items are deleted. Zero means once a minute.
+	ExpiryInterval time.Duration
+	// The maximum number of cache items held. Zero means no limit.
+	CacheMaxEntries int
+	// How the cache items to evict are picked once the maximum is reached: "lru" for the least recently used or "lfu"
+	// for the least frequently used. Empty means lru.
+	CacheEviction string
+	// The file that snapshots of all data are written to. Empty disables snapshots.
+	SnapshotPath string
+	// How often a snapshot is written. Zero means a snapshot is only written when the backend is closed.
+	SnapshotInterval time.Duration
+	// The append-only file (AOF) that every mutation is logged to. Empty disables the log. When set, the AOF rather than
+	// the snapshot is replayed on startup.
+	AOFPath string
+	// When the AOF is synced to disk: "always" after every mutation, "everysec" once a second, or "no" to leave it to
+	// the operating system. Empty means everysec.
+	AOFSync string
+	// The size in bytes past which the AOF is compacted, provided it has also doubled since it was last compacted.
+	// Zero disables automatic compaction.
+	AOFRewriteSize int64
+}
+
+// The cache eviction policies of the memory backend
+const (
+	CacheEvictionLRU = "lru"
+	CacheEvictionLFU = "lfu"
+)
+
+// The fsync policies for the memory backend's AOF
+const (
+	AOFSyncAlways   = "always"
+	AOFSyncEverySec = "everysec"
+	AOFSyncNo       = "no"
+)
+
+// TieredConfig holds the settings for the in-memory LRU that can be placed in front of the configured backend.
+type TieredConfig struct {
+	// The maximum number of entries held in memory. Zero disables the tier.
+	Size int
+}
+
+const (
+	minValueLogFileSize = 1 << 20
+	maxValueLogFileSize = 2 << 30
+)
+
 func (c *ServerConfig) Validate() error {
 	if c.Port == 0 {
 		return ErrNoPort
@@ -20,5 +144,226 @@ func (c *ServerConfig) Validate() error {
 		return ErrNoBackend
 	}
 
+	if c.Tiered.Size < 0 {
+		return ErrNegativeTieredSize
+	}
+
+	if c.TxnMaxSessions < 0 {
+		return ErrNegativeTxnMaxSessions
+	}
+
+	for _, name := range c.BackendsInUse() {
+		if !backendRegistered(name) {
+			return ErrBackendNotRecognized
+		}
+
+		switch name {
+		case "memory":
+			if err := c.Memory.Validate(); err != nil {
+				return err
+			}
+		case "disk":
+			if err := c.Disk.Validate(); err != nil {
+				return err
+			}
+		case "sqlite":
+			if err := c.Sqlite.Validate(); err != nil {
+				return err
+			}
+		case "bolt":
+			if err := c.Bolt.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// BackendFor returns the name of the backend that the supplied service ("cache", "counter", "flag", "kv", or "set")
+// is routed to.
+func (c *ServerConfig) BackendFor(service string) string {
+	var override string
+
+	switch service {
+	case "cache":
+		override = c.Services.Cache
+	case "counter":
+		override = c.Services.Counter
+	case "flag":
+		override = c.Services.Flag
+	case "kv":
+		override = c.Services.KV
+	case "set":
+		override = c.Services.Set
+	}
+
+	if override != "" {
+		return override
+	}
+
+	return c.Backend
+}
+
+// BackendsInUse returns the distinct names of all backends that at least one service is routed to, in service order.
+func (c *ServerConfig) BackendsInUse() []string {
+	var names []string
+
+	seen := make(map[string]bool)
+
+	for _, svc := range Services {
+		name := c.BackendFor(svc)
+
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Section returns the settings supplied for the named backend via Options, with the "<backend>." prefix removed.
+func (c *ServerConfig) Section(backend string) map[string]string {
+	prefix := backend + "."
+
+	section := make(map[string]string)
+
+	for k, v := range c.Options {
+		if strings.HasPrefix(k, prefix) {
+			section[strings.TrimPrefix(k, prefix)] = v
+		}
+	}
+
+	return section
+}
+
+func (c *DiskConfig) Validate() error {
+	if c.InMemory && c.ReadOnly {
+		return ErrDiskInMemoryReadOnly
+	}
+
+	if c.ValueLogFileSize != 0 && (c.ValueLogFileSize < minValueLogFileSize || c.ValueLogFileSize > maxValueLogFileSize) {
+		return ErrValueLogFileSizeOutOfRange
+	}
+
+	if c.InMemory {
+		return nil
+	}
+
+	if c.Path == "" {
+		return ErrNoDiskPath
+	}
+
+	info, err := os.Stat(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// A read-only database can't be created from scratch
+			if c.ReadOnly {
+				return ErrDiskPathNotFound
+			}
+
+			return nil
+		}
+
+		return err
+	}
+
+	if !info.IsDir() {
+		return ErrDiskPathNotDir
+	}
+
+	return nil
+}
+
+func (c *MemoryConfig) Validate() error {
+	if c.ExpiryInterval < 0 {
+		return ErrNegativeCleanupInterval
+	}
+
+	if c.CacheMaxEntries < 0 {
+		return ErrNegativeCacheMaxEntries
+	}
+
+	switch c.CacheEviction {
+	case "", CacheEvictionLRU, CacheEvictionLFU:
+	default:
+		return ErrInvalidCacheEviction
+	}
+
+	if c.SnapshotInterval < 0 {
+		return ErrNegativeSnapshotInterval
+	}
+
+	if c.AOFRewriteSize < 0 {
+		return ErrNegativeAOFRewriteSize
+	}
+
+	switch c.AOFSync {
+	case "", AOFSyncAlways, AOFSyncEverySec, AOFSyncNo:
+	default:
+		return ErrInvalidAOFSync
+	}
+
+	for _, path := range []string{c.SnapshotPath, c.AOFPath} {
+		if path == "" {
+			continue
+		}
+
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return ErrMemoryPathIsDir
+		}
+	}
+
+	return nil
+}
+
+func (c *SqliteConfig) Validate() error {
+	if c.Path == "" {
+		return ErrNoSqlitePath
+	}
+
+	if c.CleanupInterval < 0 {
+		return ErrNegativeCleanupInterval
+	}
+
+	info, err := os.Stat(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if info.IsDir() {
+		return ErrSqlitePathIsDir
+	}
+
+	return nil
+}
+
+func (c *BoltConfig) Validate() error {
+	if c.Path == "" {
+		return ErrNoBoltPath
+	}
+
+	if c.CleanupInterval < 0 {
+		return ErrNegativeCleanupInterval
+	}
+
+	info, err := os.Stat(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if info.IsDir() {
+		return ErrBoltPathIsDir
+	}
+
 	return nil
 }
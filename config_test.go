@@ -1,6 +1,8 @@
 package purple
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,6 +11,11 @@ import (
 func TestConfigInstantiation(t *testing.T) {
 	is := assert.New(t)
 
+	// The backend registry is installed when the services package is loaded, which doesn't happen here
+	SetBackendRegistry(func(name string) bool {
+		return name == "disk" || name == "memory"
+	})
+
 	t.Run("Server", func(t *testing.T) {
 		testCases := []struct {
 			config *ServerConfig
@@ -17,7 +24,105 @@ func TestConfigInstantiation(t *testing.T) {
 			{&ServerConfig{}, ErrNoPort},
 			{&ServerConfig{Port: 1234}, ErrNoBackend},
 			{&ServerConfig{Port: 10}, ErrPortOutOfRange},
-			{&ServerConfig{Port: 1234, Backend: "disk"}, nil},
+			{&ServerConfig{Port: 1234, Backend: "does-not-exist"}, ErrBackendNotRecognized},
+			{&ServerConfig{Port: 1234, Backend: "disk"}, ErrNoDiskPath},
+			{&ServerConfig{Port: 1234, Backend: "disk", Disk: DiskConfig{Path: "tmp/purple"}}, nil},
+			{&ServerConfig{Port: 1234, Backend: "memory"}, nil},
+			{&ServerConfig{Port: 1234, Backend: "memory", Services: ServiceBackends{KV: "does-not-exist"}}, ErrBackendNotRecognized},
+			{&ServerConfig{Port: 1234, Backend: "memory", Services: ServiceBackends{KV: "disk"}}, ErrNoDiskPath},
+			{&ServerConfig{Port: 1234, Backend: "memory", Tiered: TieredConfig{Size: -1}}, ErrNegativeTieredSize},
+			{&ServerConfig{Port: 1234, Backend: "memory", Tiered: TieredConfig{Size: 100}}, nil},
+			{&ServerConfig{Port: 1234, Backend: "memory", TxnMaxSessions: -1}, ErrNegativeTxnMaxSessions},
+		}
+
+		for _, tc := range testCases {
+			is.Equal(tc.config.Validate(), tc.err)
+		}
+	})
+
+	t.Run("Routing", func(t *testing.T) {
+		cfg := &ServerConfig{
+			Backend: "memory",
+			Services: ServiceBackends{
+				KV:   "disk",
+				Flag: "disk",
+			},
+		}
+
+		is.Equal(cfg.BackendFor("cache"), "memory")
+		is.Equal(cfg.BackendFor("kv"), "disk")
+		is.Equal(cfg.BackendFor("flag"), "disk")
+		is.Equal(cfg.BackendsInUse(), []string{"memory", "disk"})
+	})
+
+	t.Run("Sections", func(t *testing.T) {
+		cfg := &ServerConfig{
+			Options: map[string]string{
+				"acme.endpoint": "localhost:1234",
+				"acme.timeout":  "5s",
+				"other.setting": "value",
+			},
+		}
+
+		is.Equal(cfg.Section("acme"), map[string]string{"endpoint": "localhost:1234", "timeout": "5s"})
+		is.Empty(cfg.Section("does-not-exist"))
+	})
+
+	t.Run("Sqlite", func(t *testing.T) {
+		dir := t.TempDir()
+
+		testCases := []struct {
+			config *SqliteConfig
+			err    error
+		}{
+			{&SqliteConfig{}, ErrNoSqlitePath},
+			{&SqliteConfig{Path: filepath.Join(dir, "purple.db")}, nil},
+			{&SqliteConfig{Path: dir}, ErrSqlitePathIsDir},
+			{&SqliteConfig{Path: filepath.Join(dir, "purple.db"), CleanupInterval: -1}, ErrNegativeCleanupInterval},
+		}
+
+		for _, tc := range testCases {
+			is.Equal(tc.config.Validate(), tc.err)
+		}
+	})
+
+	t.Run("Bolt", func(t *testing.T) {
+		dir := t.TempDir()
+
+		testCases := []struct {
+			config *BoltConfig
+			err    error
+		}{
+			{&BoltConfig{}, ErrNoBoltPath},
+			{&BoltConfig{Path: filepath.Join(dir, "purple.bolt")}, nil},
+			{&BoltConfig{Path: dir}, ErrBoltPathIsDir},
+			{&BoltConfig{Path: filepath.Join(dir, "purple.bolt"), CleanupInterval: -1}, ErrNegativeCleanupInterval},
+		}
+
+		for _, tc := range testCases {
+			is.Equal(tc.config.Validate(), tc.err)
+		}
+	})
+
+	t.Run("Disk", func(t *testing.T) {
+		dir := t.TempDir()
+
+		file := filepath.Join(dir, "file")
+		is.NoError(os.WriteFile(file, []byte("not a directory"), 0644))
+
+		testCases := []struct {
+			config *DiskConfig
+			err    error
+		}{
+			{&DiskConfig{}, ErrNoDiskPath},
+			{&DiskConfig{Path: dir}, nil},
+			{&DiskConfig{Path: filepath.Join(dir, "does-not-exist-yet")}, nil},
+			{&DiskConfig{Path: file}, ErrDiskPathNotDir},
+			{&DiskConfig{Path: filepath.Join(dir, "does-not-exist"), ReadOnly: true}, ErrDiskPathNotFound},
+			{&DiskConfig{InMemory: true}, nil},
+			{&DiskConfig{InMemory: true, ReadOnly: true}, ErrDiskInMemoryReadOnly},
+			{&DiskConfig{Path: dir, ValueLogFileSize: 1024}, ErrValueLogFileSizeOutOfRange},
+			{&DiskConfig{Path: dir, ValueLogFileSize: 64 << 20}, nil},
 		}
 
 		for _, tc := range testCases {
@@ -16,6 +16,62 @@ var (
 	ErrPortOutOfRange       = errors.New("port must be between 1024 and 49151")
 	ErrBackendNotRecognized = errors.New("backend key not recognized")
 	ErrNoBackend            = errors.New("no backend specified")
+	ErrInvalidCursor        = errors.New("list cursor is not valid")
+	ErrInvalidKVValue       = errors.New("stored KV value is malformed")
+	ErrNegativeKVTTL        = errors.New("KV TTL can't be negative")
+	ErrNonPositiveKVTTL     = errors.New("KV expiry TTL must be positive")
+	ErrKVBatchTooLarge      = errors.New("KV batch has too many keys")
+	ErrNoSets               = errors.New("no sets provided")
+	ErrNegativeSetPopCount  = errors.New("number of set items to pop can't be negative")
+	ErrTxnTooLarge          = errors.New("transaction has too many operations")
+	ErrUnknownTxnOp         = errors.New("transaction contains an unknown operation")
+	ErrTxnSpansBackends     = errors.New("transactions need KV values, counters, flags, and sets on the same backend")
+	ErrTxnSessionNotFound   = errors.New("transaction session not found or expired")
+	ErrTxnSessionWrite      = errors.New("transaction sessions can only read until they're committed")
+	ErrTooManyTxnSessions   = errors.New("too many transaction sessions are open")
+
+	ErrNoDiskPath                 = errors.New("no disk backend data path provided")
+	ErrDiskPathNotDir             = errors.New("disk backend data path is not a directory")
+	ErrDiskPathNotFound           = errors.New("disk backend data path must exist in read-only mode")
+	ErrDiskInMemoryReadOnly       = errors.New("disk backend can't be both in-memory and read-only")
+	ErrValueLogFileSizeOutOfRange = errors.New("value log file size must be between 1MB and 2GB")
+	ErrDiskMigrationReadOnly      = errors.New("disk backend data must be migrated to the current layout, which can't be done in read-only mode")
+	ErrDiskWriteContention        = errors.New("disk backend write kept conflicting with concurrent writes and was abandoned")
+	ErrInvalidSetHeader           = errors.New("stored disk backend set header is malformed")
+	ErrNoBackupDir                = errors.New("no disk backend backup directory provided")
+	ErrBackupNotSupported         = errors.New("backups are only supported by the disk backend")
+	ErrNoBackups                  = errors.New("no backup files found")
+	ErrRestoreTargetNotEmpty      = errors.New("disk backend data directory to restore into isn't empty")
+
+	ErrNegativeCacheMaxEntries  = errors.New("maximum number of cache entries can't be negative")
+	ErrInvalidCacheEviction     = errors.New("cache eviction policy must be lru or lfu")
+	ErrNegativeSnapshotInterval = errors.New("snapshot interval can't be negative")
+	ErrNegativeAOFRewriteSize   = errors.New("AOF rewrite size can't be negative")
+	ErrInvalidAOFSync           = errors.New("AOF sync policy must be always, everysec, or no")
+	ErrMemoryPathIsDir          = errors.New("memory backend snapshot or AOF path is a directory")
+	ErrInvalidLogEntry          = errors.New("memory backend snapshot or AOF contains an unknown operation")
+	ErrNoSnapshotPath           = errors.New("no memory backend snapshot path configured")
+	ErrNoAOFPath                = errors.New("no memory backend AOF path configured")
+
+	ErrNoSqlitePath            = errors.New("no SQLite database path provided")
+	ErrSqlitePathIsDir         = errors.New("SQLite database path is a directory")
+	ErrNegativeCleanupInterval = errors.New("cleanup interval can't be negative")
+
+	ErrRedisWrongType      = errors.New("key holds the wrong type of value for a Redis transaction operation")
+	ErrRedisCounterInvalid = errors.New("counter in a Redis transaction isn't an integer or the increment would overflow it")
+
+	ErrNoBoltPath    = errors.New("no bolt database path provided")
+	ErrBoltPathIsDir = errors.New("bolt database path is a directory")
+
+	ErrNegativeTieredSize = errors.New("tiered cache size can't be negative")
+
+	ErrNegativeTxnMaxSessions = errors.New("maximum number of transaction sessions can't be negative")
+
+	ErrSameBackend         = errors.New("source and destination backends must be different")
+	ErrMemoryNotPersistent = errors.New("memory backend needs a snapshot or AOF path to be migrated from or to")
+	ErrVerificationFailed  = errors.New("source and destination backends differ")
+	ErrInvalidDumpRecord   = errors.New("dump record is missing its key or value or names an unknown service")
+	ErrInvalidImportMode   = errors.New("import mode must be merge or replace")
 )
 
 type NotFoundError struct {
@@ -38,3 +94,25 @@ func IsNotFound(err error) bool {
 	_, ok := err.(NotFoundError)
 	return ok
 }
+
+// ConflictError is returned by conditional KV writes when the key's current version isn't the expected one.
+type ConflictError struct {
+	string
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf(`version conflict for %s`, e.string)
+}
+
+func (e ConflictError) AsProtoStatus() error {
+	return status.Error(codes.FailedPrecondition, e.Error())
+}
+
+func Conflict(key string) ConflictError {
+	return ConflictError{key}
+}
+
+func IsConflict(err error) bool {
+	_, ok := err.(ConflictError)
+	return ok
+}
@@ -36,7 +36,7 @@
       };
 
       # The same across all packages
-      vendorHash = "sha256-fSdq6j63GbTHAZE93gtHeMPYCLT3PdiVrG4aqWAD2QE=";
+      vendorHash = "sha256-KD90fR2tLV1T9Prdia9N8bILckdBLYpknI0kNY4g2Xg=";
     in
     {
       devShells = forEachSupportedSystem (
@@ -61,6 +61,7 @@
         {
           http = pkgs.buildGo "purple-http";
           grpc = pkgs.buildGo "purple-grpc";
+          migrate = pkgs.buildGo "purple-migrate";
         }
       );
 
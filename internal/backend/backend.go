@@ -2,28 +2,18 @@ package backend
 
 import (
 	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/backend/bolt"
 	"github.com/purpledb/purple/internal/backend/disk"
 	"github.com/purpledb/purple/internal/backend/memory"
 	"github.com/purpledb/purple/internal/backend/redis"
-	"github.com/purpledb/purple/internal/services/cache"
-	"github.com/purpledb/purple/internal/services/counter"
-	"github.com/purpledb/purple/internal/services/flag"
-	"github.com/purpledb/purple/internal/services/kv"
-	"github.com/purpledb/purple/internal/services/set"
+	"github.com/purpledb/purple/internal/backend/sqlite"
+	"github.com/purpledb/purple/services"
 )
 
 type (
-	Service interface {
-		cache.Cache
-		counter.Counter
-		flag.Flag
-		kv.KV
-		set.Set
-
-		Close() error
-		Flush() error
-		Name() string
-	}
+	// Service is the public services.Service interface, which is what backends registered from outside this module
+	// implement
+	Service = services.Service
 
 	// Backend wraps a Service and thereby provides specific instantiations access to the Close() and Flush() methods
 	Backend struct {
@@ -32,37 +22,111 @@ type (
 )
 
 var (
+	_ Service = (*bolt.Bolt)(nil)
 	_ Service = (*disk.Disk)(nil)
 	_ Service = (*memory.Memory)(nil)
 	_ Service = (*redis.Redis)(nil)
+	_ Service = (*sqlite.Sqlite)(nil)
 )
 
+func init() {
+	services.Register("bolt", func(cfg *purple.ServerConfig) (Service, error) {
+		backend, err := bolt.NewBoltBackend(&cfg.Bolt)
+		if err != nil {
+			return nil, err
+		}
+		return backend, nil
+	})
+
+	services.Register("disk", func(cfg *purple.ServerConfig) (Service, error) {
+		backend, err := disk.NewDiskBackend(&cfg.Disk)
+		if err != nil {
+			return nil, err
+		}
+		return backend, nil
+	})
+
+	services.Register("memory", func(cfg *purple.ServerConfig) (Service, error) {
+		backend, err := memory.NewMemoryBackendFromConfig(&cfg.Memory)
+		if err != nil {
+			return nil, err
+		}
+		return backend, nil
+	})
+
+	services.Register("redis", func(cfg *purple.ServerConfig) (Service, error) {
+		backend, err := redis.NewRedisBackend(cfg.RedisUrl, cfg.RedisPrefix)
+		if err != nil {
+			return nil, err
+		}
+		return backend, nil
+	})
+
+	services.Register("sqlite", func(cfg *purple.ServerConfig) (Service, error) {
+		backend, err := sqlite.NewSqliteBackend(&cfg.Sqlite)
+		if err != nil {
+			return nil, err
+		}
+		return backend, nil
+	})
+}
+
+// NewBackend instantiates the configured backend. If any services are routed to a backend other than the default, each
+// distinct backend is instantiated once and the services are combined into a Composite. If a tiered size is configured,
+// the result is placed behind an in-memory LRU.
 func NewBackend(cfg *purple.ServerConfig) (*Backend, error) {
-	switch cfg.Backend {
-	case "disk":
-		backend, err := disk.NewDiskBackend()
+	names := cfg.BackendsInUse()
+
+	backends := make(map[string]Service, len(names))
+
+	for _, name := range names {
+		factory, ok := services.Lookup(name)
+		if !ok {
+			closeAll(backends)
+			return nil, purple.ErrBackendNotRecognized
+		}
+
+		svc, err := factory(cfg)
 		if err != nil {
+			closeAll(backends)
 			return nil, err
 		}
-		return &Backend{
-			backend,
-		}, nil
-	case "memory":
-		backend := memory.NewMemoryBackend()
-
-		return &Backend{
-			backend,
-		}, nil
-	case "redis":
-		backend, err := redis.NewRedisBackend(cfg.RedisUrl)
+
+		backends[name] = svc
+	}
+
+	var svc Service
+
+	if len(names) == 1 {
+		svc = backends[names[0]]
+	} else {
+		routed := make([]Service, len(purple.Services))
+
+		for i, name := range purple.Services {
+			routed[i] = backends[cfg.BackendFor(name)]
+		}
+
+		svc = NewComposite(routed[0], routed[1], routed[2], routed[3], routed[4])
+	}
+
+	if cfg.Tiered.Size > 0 {
+		tiered, err := NewTiered(svc, cfg.Tiered.Size)
 		if err != nil {
+			_ = svc.Close()
 			return nil, err
 		}
-		return &Backend{
-			backend,
-		}, nil
-	default:
-		return nil, purple.ErrBackendNotRecognized
+
+		svc = tiered
+	}
+
+	return &Backend{
+		svc,
+	}, nil
+}
+
+func closeAll(backends map[string]Service) {
+	for _, bk := range backends {
+		_ = bk.Close()
 	}
 }
 
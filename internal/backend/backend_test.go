@@ -2,14 +2,28 @@ package backend
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/backend/bolt"
 	"github.com/purpledb/purple/internal/backend/disk"
 	"github.com/purpledb/purple/internal/backend/memory"
-	"github.com/purpledb/purple/internal/services/kv"
+	"github.com/purpledb/purple/internal/backend/redis"
+	"github.com/purpledb/purple/internal/backend/sqlite"
+	"github.com/purpledb/purple/internal/data"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
+	"github.com/purpledb/purple/services/txn"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dgraph-io/badger"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,16 +35,979 @@ func TestServices(t *testing.T) {
 	}
 }
 
+// Instantiates every backend in a temporary directory of the test's own and closes them when the test is done.
 func getServices(t *testing.T) []Service {
 	is := assert.New(t)
 
+	dir := t.TempDir()
+
 	mem := memory.NewMemoryBackend()
 
-	ds, err := disk.NewDiskBackend()
+	ds, err := disk.NewDiskBackend(&purple.DiskConfig{Path: filepath.Join(dir, "purple")})
+	is.NoError(err)
+	is.NotNil(ds)
+
+	rd, err := redis.NewRedisBackend(newMiniRedis(t), redis.DefaultPrefix)
+	is.NoError(err)
+	is.NotNil(rd)
+
+	sq, err := sqlite.NewSqliteBackend(&purple.SqliteConfig{Path: filepath.Join(dir, "purple.db")})
+	is.NoError(err)
+	is.NotNil(sq)
+
+	bt, err := bolt.NewBoltBackend(&purple.BoltConfig{Path: filepath.Join(dir, "purple.bolt")})
+	is.NoError(err)
+	is.NotNil(bt)
+
+	// Shares the instances above since the disk backend can only be opened once
+	comp := NewComposite(mem, mem, ds, ds, ds)
+
+	tr, err := NewTiered(rd, 100)
+	is.NoError(err)
+
+	t.Cleanup(func() {
+		for _, svc := range []Service{mem, ds, rd, sq, bt} {
+			is.NoError(svc.Close())
+		}
+	})
+
+	return []Service{mem, ds, rd, sq, bt, comp, tr}
+}
+
+// Starts an in-process Redis server that's stopped when the test is done and returns its URL. Keys in miniredis only
+// expire when its clock is advanced, so the clock is kept in step with real time.
+func newMiniRedis(t *testing.T) string {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				mr.FastForward(100 * time.Millisecond)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	t.Cleanup(func() {
+		ticker.Stop()
+		close(done)
+		mr.Close()
+	})
+
+	return "redis://" + mr.Addr()
+}
+
+func TestRedisNamespacing(t *testing.T) {
+	is := assert.New(t)
+
+	mr, err := miniredis.Run()
+	is.NoError(err)
+	defer mr.Close()
+
+	url := "redis://" + mr.Addr()
+
+	rd, err := redis.NewRedisBackend(url, "")
+	is.NoError(err)
+
+	other, err := redis.NewRedisBackend(url, "other")
+	is.NoError(err)
+
+	is.NoError(mr.Set("unrelated", "value"))
+
+	is.NoError(rd.KVPut("key", &kv.Value{Content: []byte("purple")}, 0))
+	is.NoError(other.KVPut("key", &kv.Value{Content: []byte("other")}, 0))
+	_, err = rd.SetAdd("set", "item")
+	is.NoError(err)
+
+	is.True(mr.Exists("purple:kv:key"))
+	is.True(mr.Exists("purple:set:set"))
+	is.True(mr.Exists("other:kv:key"))
+	// KV versions are kept in the KV hash itself, so writes don't touch any other key
+	is.Len(mr.Keys(), 4)
+
+	val, err := rd.KVGet("key")
+	is.NoError(err)
+	is.Equal(val.Content, []byte("purple"))
+
+	is.NoError(rd.Flush())
+
+	is.False(mr.Exists("purple:kv:key"))
+	is.False(mr.Exists("purple:set:set"))
+	is.True(mr.Exists("other:kv:key"))
+	is.True(mr.Exists("unrelated"))
+
+	is.NoError(rd.Close())
+	is.NoError(other.Close())
+}
+
+func TestRedisTxnPrecheck(t *testing.T) {
+	is := assert.New(t)
+
+	mr, err := miniredis.Run()
+	is.NoError(err)
+	defer mr.Close()
+
+	rd, err := redis.NewRedisBackend("redis://"+mr.Addr(), "")
+	is.NoError(err)
+	defer rd.Close()
+
+	put := &txn.Op{Kind: txn.KVPut, Key: "key", Value: &kv.Value{Content: []byte("purple")}}
+
+	// Commands that would fail inside MULTI/EXEC fail the whole transaction before anything is applied
+	is.NoError(mr.Set("purple:counter:broken", "not a number"))
+	_, err = rd.Txn([]*txn.Op{put, {Kind: txn.CounterIncr, Key: "broken", Amount: 1}})
+	is.Equal(err, purple.ErrRedisCounterInvalid)
+
+	is.NoError(mr.Set("purple:counter:full", strconv.FormatInt(math.MaxInt64, 10)))
+	_, err = rd.Txn([]*txn.Op{put, {Kind: txn.CounterIncr, Key: "full", Amount: 1}})
+	is.Equal(err, purple.ErrRedisCounterInvalid)
+
+	_, err = mr.SAdd("purple:flag:wrong", "item")
+	is.NoError(err)
+	_, err = rd.Txn([]*txn.Op{put, {Kind: txn.FlagSet, Key: "wrong", Flag: true}})
+	is.Equal(err, purple.ErrRedisWrongType)
+
+	is.False(mr.Exists("purple:kv:key"))
+
+	results, err := rd.Txn([]*txn.Op{put, {Kind: txn.CounterIncr, Key: "ok", Amount: 1}})
+	is.NoError(err)
+	is.Equal(results[1].Count, int64(1))
+	is.True(mr.Exists("purple:kv:key"))
+}
+
+func TestDiskMigration(t *testing.T) {
+	is := assert.New(t)
+
+	root := t.TempDir()
+
+	// Write data using the legacy layout of one DB per service
+	legacy := map[string]map[string][]byte{
+		"counter": {"my-counter": data.Int64ToBytes(42)},
+		"flag":    {"my-flag": data.BoolAsBytes(true)},
+		"kv":      {"my-key": []byte("my-value")},
+		"set":     {"my-set": []byte(`["a","b"]`)},
+	}
+
+	for subDir, entries := range legacy {
+		db, err := badger.Open(badger.DefaultOptions(filepath.Join(root, subDir)))
+		is.NoError(err)
+
+		is.NoError(db.Update(func(tx *badger.Txn) error {
+			for k, v := range entries {
+				if err := tx.Set([]byte(k), v); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+
+		is.NoError(db.Close())
+	}
+
+	cacheDb, err := badger.Open(badger.DefaultOptions(filepath.Join(root, "cache")))
+	is.NoError(err)
+	is.NoError(cacheDb.Update(func(tx *badger.Txn) error {
+		return tx.SetEntry(badger.NewEntry([]byte("my-cache"), []byte("cached")).WithTTL(time.Minute))
+	}))
+	is.NoError(cacheDb.Close())
+
+	ds, err := disk.NewDiskBackend(&purple.DiskConfig{Path: root})
+	is.NoError(err)
+
+	for _, subDir := range []string{"cache", "counter", "flag", "kv", "set"} {
+		_, err := os.Stat(filepath.Join(root, subDir))
+		is.True(os.IsNotExist(err))
+	}
+
+	cached, err := ds.CacheGet("my-cache")
+	is.NoError(err)
+	is.Equal(cached, "cached")
+
+	count, err := ds.CounterGet("my-counter")
+	is.NoError(err)
+	is.Equal(count, int64(42))
+
+	flag, err := ds.FlagGet("my-flag")
+	is.NoError(err)
+	is.True(flag)
+
+	val, err := ds.KVGet("my-key")
+	is.NoError(err)
+	is.Equal(val.Content, []byte("my-value"))
+
+	items, err := ds.SetGet("my-set")
+	is.NoError(err)
+	is.Equal(items, []string{"a", "b"})
+
+	// Keys are isolated by service
+	_, err = ds.KVGet("my-counter")
+	is.True(purple.IsNotFound(err))
+
+	is.NoError(ds.Close())
+}
+
+func TestDiskSetMigration(t *testing.T) {
+	is := assert.New(t)
+
+	root := t.TempDir()
+
+	// Sets used to be stored as a JSON array of their items
+	db, err := badger.Open(badger.DefaultOptions(filepath.Join(root, "data")))
+	is.NoError(err)
+	is.NoError(db.Update(func(tx *badger.Txn) error {
+		if err := tx.Set([]byte("set:my-set"), []byte(`["b","a"]`)); err != nil {
+			return err
+		}
+		if err := tx.Set([]byte("set:empty"), []byte(`[]`)); err != nil {
+			return err
+		}
+		// Later, the header held a single byte rather than the number of items, whose keys are preceded by the
+		// length of the set's name
+		for _, item := range []string{"x", "y", "z"} {
+			if err := tx.Set([]byte("setmember:\x07counted"+item), nil); err != nil {
+				return err
+			}
+		}
+		return tx.Set([]byte("set:counted"), []byte{1})
+	}))
+	is.NoError(db.Close())
+
+	_, err = disk.NewDiskBackend(&purple.DiskConfig{Path: root, ReadOnly: true})
+	is.Equal(err, purple.ErrDiskMigrationReadOnly)
+
+	// Migrating again on the next start changes nothing
+	for i := 0; i < 2; i++ {
+		ds, err := disk.NewDiskBackend(&purple.DiskConfig{Path: root})
+		is.NoError(err)
+
+		items, err := ds.SetGet("my-set")
+		is.NoError(err)
+		is.Equal(items, []string{"a", "b"})
+
+		contains, err := ds.SetContains("my-set", "b")
+		is.NoError(err)
+		is.True(contains)
+
+		size, err := ds.SetSize("my-set")
+		is.NoError(err)
+		is.Equal(size, 2)
+
+		items, err = ds.SetGet("empty")
+		is.NoError(err)
+		is.Empty(items)
+
+		size, err = ds.SetSize("counted")
+		is.NoError(err)
+		is.Equal(size, 3)
+
+		summary, err := ds.SetAddSummary("counted", "w")
+		is.NoError(err)
+		is.Equal(summary, &set.Summary{Changed: true, Size: 4})
+		_, err = ds.SetRemoveSummary("counted", "w")
+		is.NoError(err)
+
+		sets, _, err := ds.SetList("", "", 0)
+		is.NoError(err)
+		is.Equal(sets, []string{"counted", "empty", "my-set"})
+
+		is.NoError(ds.Close())
+	}
+
+	// The items of sets whose names share a prefix are kept apart
+	ds, err := disk.NewDiskBackend(&purple.DiskConfig{Path: root})
+	is.NoError(err)
+
+	items, err := ds.SetAdd("my-se", "tmy-set")
+	is.NoError(err)
+	is.Equal(items, []string{"tmy-set"})
+
+	items, err = ds.SetGet("my-set")
+	is.NoError(err)
+	is.Equal(items, []string{"a", "b"})
+
+	is.NoError(ds.Close())
+}
+
+func TestDiskBackup(t *testing.T) {
+	is := assert.New(t)
+
+	backupDir := t.TempDir()
+
+	ds, err := disk.NewDiskBackend(&purple.DiskConfig{InMemory: true, BackupDir: backupDir})
+	is.NoError(err)
+	defer ds.Close()
+
+	is.NoError(ds.KVPut("kept", &kv.Value{Content: []byte("v1")}, 0))
+	is.NoError(ds.KVPut("deleted", &kv.Value{Content: []byte("gone")}, 0))
+	_, err = ds.CounterIncrement("counter", 1)
+	is.NoError(err)
+
+	_, err = Backup(memory.NewMemoryBackend(), 0)
+	is.Equal(err, purple.ErrBackupNotSupported)
+
+	// A composite is only backed up if every service is on a backend that takes backups, and the error names the
+	// services that aren't
+	mem := memory.NewMemoryBackend()
+	_, err = Backup(NewComposite(mem, ds, ds, ds, mem), 0)
+	is.ErrorIs(err, purple.ErrBackupNotSupported)
+	is.Contains(err.Error(), "not backed up: cache, set")
+
+	// Wrappers such as Tiered are looked through
+	tiered, err := NewTiered(ds, 10)
+	is.NoError(err)
+
+	first, err := Backup(tiered, 0)
+	is.NoError(err)
+	is.Equal(first.Since, uint64(0))
+	is.FileExists(first.Path)
+
+	whole, err := Backup(NewComposite(ds, ds, ds, tiered, ds), 0)
+	is.NoError(err)
+	is.FileExists(whole.Path)
+
+	is.NoError(ds.KVPut("kept", &kv.Value{Content: []byte("v2")}, 0))
+	is.NoError(ds.KVDelete("deleted"))
+	is.NoError(ds.FlagSet("flag", true))
+
+	second, err := ds.Backup(first.Next)
+	is.NoError(err)
+	is.Equal(second.Since, first.Next)
+	is.Greater(second.Next, first.Next)
+
+	// An incremental backup without any new writes is empty
+	third, err := ds.Backup(second.Next)
+	is.NoError(err)
+	is.Equal(third.Next, second.Next)
+
+	root := t.TempDir()
+
+	count, err := disk.Restore(backupDir, root)
+	is.NoError(err)
+	is.Equal(count, 3)
+
+	_, err = disk.Restore(backupDir, root)
+	is.Equal(err, purple.ErrRestoreTargetNotEmpty)
+
+	_, err = disk.Restore(t.TempDir(), t.TempDir())
+	is.Equal(err, purple.ErrNoBackups)
+
+	restored, err := disk.NewDiskBackend(&purple.DiskConfig{Path: root})
+	is.NoError(err)
+	defer restored.Close()
+
+	val, err := restored.KVGet("kept")
+	is.NoError(err)
+	is.Equal(val.Content, []byte("v2"))
+
+	_, err = restored.KVGet("deleted")
+	is.True(purple.IsNotFound(err))
+
+	count64, err := restored.CounterGet("counter")
+	is.NoError(err)
+	is.Equal(count64, int64(1))
+
+	flag, err := restored.FlagGet("flag")
+	is.NoError(err)
+	is.True(flag)
+}
+
+func TestDiskTxnSession(t *testing.T) {
+	is := assert.New(t)
+
+	ds, err := disk.NewDiskBackend(&purple.DiskConfig{InMemory: true})
+	is.NoError(err)
+	defer ds.Close()
+
+	_, err = ds.SetAdd("members", "alice")
+	is.NoError(err)
+
+	// Badger catches writes that are undone before the commit, which comparing values wouldn't
+	for _, write := range []func() error{
+		func() error {
+			if _, err := ds.CounterIncrement("count", 1); err != nil {
+				return err
+			}
+
+			_, err := ds.CounterIncrement("count", -1)
+			return err
+		},
+		func() error {
+			if _, err := ds.SetRemove("members", "alice"); err != nil {
+				return err
+			}
+
+			_, err := ds.SetAdd("members", "alice")
+			return err
+		},
+	} {
+		s, err := ds.TxnBegin()
+		is.NoError(err)
+		_, err = s.Read([]*txn.Op{{Kind: txn.CounterGet, Key: "count"}, {Kind: txn.SetGet, Key: "members"}})
+		is.NoError(err)
+
+		is.NoError(write())
+
+		_, err = s.Commit([]*txn.Op{{Kind: txn.FlagSet, Key: "done", Flag: true}})
+		is.Equal(err, purple.Conflict("count"))
+	}
+
+	// The key that changed is reported when there is one
+	s, err := ds.TxnBegin()
+	is.NoError(err)
+	_, err = s.Read([]*txn.Op{{Kind: txn.CounterGet, Key: "count"}, {Kind: txn.SetGet, Key: "members"}})
+	is.NoError(err)
+
+	_, err = ds.SetAdd("members", "bob")
+	is.NoError(err)
+
+	_, err = s.Commit([]*txn.Op{{Kind: txn.FlagSet, Key: "done", Flag: true}})
+	is.Equal(err, purple.Conflict("members"))
+
+	done, err := ds.FlagGet("done")
+	is.NoError(err)
+	is.False(done)
+}
+
+func TestMemoryPersistence(t *testing.T) {
+	is := assert.New(t)
+
+	dir := t.TempDir()
+
+	populate := func(m *memory.Memory) {
+		is.NoError(m.CacheSet("cached", "value", 60))
+		_, err := m.CounterIncrement("counter", 5)
+		is.NoError(err)
+		_, err = m.CounterIncrement("counter", -2)
+		is.NoError(err)
+		is.NoError(m.FlagSet("flag", true))
+		is.NoError(m.KVPut("key", &kv.Value{Content: []byte("v1")}, 0))
+		is.NoError(m.KVPut("key", &kv.Value{Content: []byte("v2")}, 0))
+		is.NoError(m.KVPut("deleted", &kv.Value{Content: []byte("gone")}, 0))
+		is.NoError(m.KVDelete("deleted"))
+		is.NoError(m.KVPut("expiring", &kv.Value{Content: []byte("session")}, 60))
+		is.NoError(m.KVPut("persisted", &kv.Value{Content: []byte("session")}, 60))
+		is.NoError(m.KVPersist("persisted"))
+		is.NoError(m.KVPutMany([]*kv.Entry{
+			{Key: "batched", Value: &kv.Value{Content: []byte("kept")}},
+			{Key: "batch-deleted", Value: &kv.Value{Content: []byte("gone")}},
+		}))
+		_, err = m.KVDeleteMany([]string{"batch-deleted"})
+		is.NoError(err)
+		_, err = m.Txn([]*txn.Op{
+			{Kind: txn.CounterIncr, Key: "txn-counter", Amount: 7},
+			{Kind: txn.SetAdd, Key: "txn-set", Item: "x"},
+			{Kind: txn.KVPut, Key: "txn-key", Value: &kv.Value{Content: []byte("committed")}},
+		})
+		is.NoError(err)
+		_, err = m.SetAdd("set", "a")
+		is.NoError(err)
+		_, err = m.SetAdd("set", "b")
+		is.NoError(err)
+		_, err = m.SetRemove("set", "a")
+		is.NoError(err)
+		_, err = m.SetUnionStore("union", "set", "txn-set")
+		is.NoError(err)
+		_, err = m.SetUnionStore("emptied", "set")
+		is.NoError(err)
+		_, err = m.SetDiffStore("emptied", "set", "set")
+		is.NoError(err)
+		_, err = m.SetAddSummary("popped", "p")
+		is.NoError(err)
+		_, err = m.SetPop("popped", 1)
+		is.NoError(err)
+	}
+
+	check := func(m *memory.Memory) {
+		val, err := m.CacheGet("cached")
+		is.NoError(err)
+		is.Equal(val, "value")
+
+		count, err := m.CounterGet("counter")
+		is.NoError(err)
+		is.Equal(count, int64(3))
+
+		flag, err := m.FlagGet("flag")
+		is.NoError(err)
+		is.True(flag)
+
+		kvVal, err := m.KVGet("key")
+		is.NoError(err)
+		is.Equal(kvVal.Content, []byte("v2"))
+
+		_, err = m.KVGet("deleted")
+		is.True(purple.IsNotFound(err))
+
+		kvVal, err = m.KVGet("batched")
+		is.NoError(err)
+		is.Equal(kvVal.Content, []byte("kept"))
+
+		_, err = m.KVGet("batch-deleted")
+		is.True(purple.IsNotFound(err))
+
+		count, err = m.CounterGet("txn-counter")
+		is.NoError(err)
+		is.Equal(count, int64(7))
+
+		kvVal, err = m.KVGet("txn-key")
+		is.NoError(err)
+		is.Equal(kvVal.Content, []byte("committed"))
+
+		items, err := m.SetGet("txn-set")
+		is.NoError(err)
+		is.Equal(items, []string{"x"})
+
+		ttl, err := m.KVTTL("expiring")
+		is.NoError(err)
+		is.InDelta(60, ttl, 1)
+
+		ttl, err = m.KVTTL("persisted")
+		is.NoError(err)
+		is.Equal(ttl, kv.NoTTL)
+
+		items, err = m.SetGet("set")
+		is.NoError(err)
+		is.Equal(items, []string{"b"})
+
+		items, err = m.SetGet("union")
+		is.NoError(err)
+		is.Equal(items, []string{"b", "x"})
+
+		_, err = m.SetGet("emptied")
+		is.True(purple.IsNotFound(err))
+
+		items, err = m.SetGet("popped")
+		is.NoError(err)
+		is.Empty(items)
+	}
+
+	t.Run("Snapshot", func(t *testing.T) {
+		cfg := &purple.MemoryConfig{SnapshotPath: filepath.Join(dir, "snapshot.json")}
+
+		m, err := memory.NewMemoryBackendFromConfig(cfg)
+		is.NoError(err)
+		populate(m)
+		is.Equal(m.RewriteAOF(), purple.ErrNoAOFPath)
+
+		// A snapshot is written on Close
+		is.NoError(m.Close())
+
+		restored, err := memory.NewMemoryBackendFromConfig(cfg)
+		is.NoError(err)
+		check(restored)
+		is.NoError(restored.Close())
+	})
+
+	t.Run("AOF", func(t *testing.T) {
+		cfg := &purple.MemoryConfig{AOFPath: filepath.Join(dir, "purple.aof"), AOFSync: purple.AOFSyncAlways}
+
+		m, err := memory.NewMemoryBackendFromConfig(cfg)
+		is.NoError(err)
+		populate(m)
+
+		// Mutations are logged before Close
+		replayed, err := memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{AOFPath: cfg.AOFPath})
+		is.NoError(err)
+		check(replayed)
+		is.NoError(replayed.Close())
+
+		before, err := os.Stat(cfg.AOFPath)
+		is.NoError(err)
+
+		is.NoError(m.RewriteAOF())
+
+		after, err := os.Stat(cfg.AOFPath)
+		is.NoError(err)
+		is.Less(after.Size(), before.Size())
+
+		// Mutations after a rewrite are appended to the compacted AOF
+		is.NoError(m.FlagSet("flag", false))
+		is.Equal(m.Snapshot(), purple.ErrNoSnapshotPath)
+		is.NoError(m.Close())
+
+		// A partly written entry at the end of the AOF is dropped
+		f, err := os.OpenFile(cfg.AOFPath, os.O_WRONLY|os.O_APPEND, 0600)
+		is.NoError(err)
+		_, err = f.WriteString(`{"op":"flag.set","key":"fl`)
+		is.NoError(err)
+		is.NoError(f.Close())
+
+		restored, err := memory.NewMemoryBackendFromConfig(cfg)
+		is.NoError(err)
+
+		flag, err := restored.FlagGet("flag")
+		is.NoError(err)
+		is.False(flag)
+
+		is.NoError(restored.Flush())
+		is.NoError(restored.Close())
+
+		flushed, err := memory.NewMemoryBackendFromConfig(cfg)
+		is.NoError(err)
+
+		_, err = flushed.KVGet("key")
+		is.True(purple.IsNotFound(err))
+		is.NoError(flushed.Close())
+	})
+
+	t.Run("SnapshotWithNewAOF", func(t *testing.T) {
+		cfg := &purple.MemoryConfig{SnapshotPath: filepath.Join(dir, "existing.json")}
+
+		m, err := memory.NewMemoryBackendFromConfig(cfg)
+		is.NoError(err)
+		populate(m)
+		is.NoError(m.Close())
+
+		// Turning on the AOF restores from the snapshot, since the AOF doesn't hold anything yet
+		cfg.AOFPath = filepath.Join(dir, "new.aof")
+
+		restored, err := memory.NewMemoryBackendFromConfig(cfg)
+		is.NoError(err)
+		check(restored)
+		is.NoError(restored.FlagSet("flag", false))
+		is.NoError(restored.Close())
+
+		// The AOF was started off with the snapshot's data, so it can be restored from without the snapshot
+		is.NoError(os.Remove(cfg.SnapshotPath))
+
+		replayed, err := memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{AOFPath: cfg.AOFPath})
+		is.NoError(err)
+
+		kvVal, err := replayed.KVGet("key")
+		is.NoError(err)
+		is.Equal(kvVal.Content, []byte("v2"))
+
+		flag, err := replayed.FlagGet("flag")
+		is.NoError(err)
+		is.False(flag)
+		is.NoError(replayed.Close())
+
+		// An AOF compacted while there's no data is still restored from rather than an older snapshot
+		cfg.SnapshotPath = filepath.Join(dir, "older.json")
+
+		m, err = memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{SnapshotPath: cfg.SnapshotPath})
+		is.NoError(err)
+		populate(m)
+		is.NoError(m.Close())
+
+		m, err = memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{AOFPath: cfg.AOFPath})
+		is.NoError(err)
+		is.NoError(m.Flush())
+		is.NoError(m.RewriteAOF())
+		is.NoError(m.Close())
+
+		emptied, err := memory.NewMemoryBackendFromConfig(cfg)
+		is.NoError(err)
+
+		_, err = emptied.KVGet("key")
+		is.True(purple.IsNotFound(err))
+		is.NoError(emptied.Close())
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{AOFPath: dir})
+		is.Equal(err, purple.ErrMemoryPathIsDir)
+
+		_, err = memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{AOFSync: "sometimes"})
+		is.Equal(err, purple.ErrInvalidAOFSync)
+	})
+}
+
+func TestMemoryExpiry(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("Sweep", func(t *testing.T) {
+		// Items replayed with their original timestamps have long expired
+		aof := filepath.Join(t.TempDir(), "purple.aof")
+		is.NoError(os.WriteFile(aof, []byte(fmt.Sprintf(
+			`{"op":"cache.set","key":"old","value":"v","ts":1,"ttl":1}`+"\n"+
+				`{"op":"cache.set","key":"older","value":"v","ts":1,"ttl":1}`+"\n"+
+				`{"op":"cache.set","key":"fresh","value":"v","ts":%d,"ttl":60}`+"\n", time.Now().Unix())), 0600))
+
+		m, err := memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{AOFPath: aof, ExpiryInterval: 10 * time.Millisecond})
+		is.NoError(err)
+		defer m.Close()
+
+		is.Eventually(func() bool {
+			return m.Stats() == memory.Stats{CacheEntries: 1, Expired: 2}
+		}, time.Second, 10*time.Millisecond)
+
+		val, err := m.CacheGet("fresh")
+		is.NoError(err)
+		is.Equal(val, "v")
+	})
+
+	t.Run("LRU", func(t *testing.T) {
+		m, err := memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{CacheMaxEntries: 3})
+		is.NoError(err)
+		defer m.Close()
+
+		for _, key := range []string{"a", "b", "c"} {
+			is.NoError(m.CacheSet(key, "v", 60))
+		}
+
+		_, err = m.CacheGet("a")
+		is.NoError(err)
+
+		is.NoError(m.CacheSet("d", "v", 60))
+
+		is.Equal(m.Stats(), memory.Stats{CacheEntries: 3, Evicted: 1})
+
+		_, err = m.CacheGet("b")
+		is.True(purple.IsNotFound(err))
+
+		keys, _, err := m.CacheList("", "", 0)
+		is.NoError(err)
+		is.Equal(keys, []string{"a", "c", "d"})
+	})
+
+	t.Run("LFU", func(t *testing.T) {
+		m, err := memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{CacheMaxEntries: 3, CacheEviction: purple.CacheEvictionLFU})
+		is.NoError(err)
+		defer m.Close()
+
+		for _, key := range []string{"a", "b", "c"} {
+			is.NoError(m.CacheSet(key, "v", 60))
+		}
+
+		for _, key := range []string{"a", "a", "b"} {
+			_, err = m.CacheGet(key)
+			is.NoError(err)
+		}
+
+		// c and d have never been read, and c is the older of the two
+		is.NoError(m.CacheSet("d", "v", 60))
+
+		keys, _, err := m.CacheList("", "", 0)
+		is.NoError(err)
+		is.Equal(keys, []string{"a", "b", "d"})
+	})
+
+	t.Run("EvictionIsLogged", func(t *testing.T) {
+		cfg := &purple.MemoryConfig{CacheMaxEntries: 1, AOFPath: filepath.Join(t.TempDir(), "purple.aof")}
+
+		m, err := memory.NewMemoryBackendFromConfig(cfg)
+		is.NoError(err)
+		is.NoError(m.CacheSet("a", "v", 60))
+		is.NoError(m.CacheSet("b", "v", 60))
+		is.NoError(m.Close())
+
+		// Without a limit, only the item that wasn't evicted is replayed
+		replayed, err := memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{AOFPath: cfg.AOFPath})
+		is.NoError(err)
+		defer replayed.Close()
+
+		keys, _, err := replayed.CacheList("", "", 0)
+		is.NoError(err)
+		is.Equal(keys, []string{"b"})
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{CacheEviction: "random"})
+		is.Equal(err, purple.ErrInvalidCacheEviction)
+
+		_, err = memory.NewMemoryBackendFromConfig(&purple.MemoryConfig{CacheMaxEntries: -1})
+		is.Equal(err, purple.ErrNegativeCacheMaxEntries)
+	})
+}
+
+func TestCompositeRouting(t *testing.T) {
+	is := assert.New(t)
+
+	cfg := &purple.ServerConfig{
+		Backend: "memory",
+		Disk:    purple.DiskConfig{InMemory: true},
+		Services: purple.ServiceBackends{
+			KV:  "disk",
+			Set: "disk",
+		},
+	}
+
+	bk, err := NewBackend(cfg)
+	is.NoError(err)
+
+	comp, ok := bk.Service.(*Composite)
+	is.True(ok)
+	is.Len(comp.backends, 2)
+
+	_, ok = comp.Cache.(*memory.Memory)
+	is.True(ok)
+	_, ok = comp.Flag.(*memory.Memory)
+	is.True(ok)
+	_, ok = comp.KV.(*disk.Disk)
+	is.True(ok)
+	_, ok = comp.Set.(*disk.Disk)
+	is.True(ok)
+
+	is.NoError(bk.KVPut("key", &kv.Value{Content: []byte("value")}, 0))
+	_, err = comp.backends[0].KVGet("key")
+	is.True(purple.IsNotFound(err))
+	_, err = comp.backends[1].KVGet("key")
+	is.NoError(err)
+
+	// Counters and flags are in memory while KV values and sets are on disk
+	_, err = bk.Txn([]*txn.Op{{Kind: txn.CounterIncr, Key: "count", Amount: 1}})
+	is.Equal(err, purple.ErrTxnSpansBackends)
+	_, err = bk.TxnBegin()
+	is.Equal(err, purple.ErrTxnSpansBackends)
+
+	is.NoError(bk.Flush())
+	_, err = bk.KVGet("key")
+	is.True(purple.IsNotFound(err))
+
+	is.NoError(bk.Close())
+
+	cfg.Services.KV = "does-not-exist"
+	_, err = NewBackend(cfg)
+	is.Equal(err, purple.ErrBackendNotRecognized)
+}
+
+func TestTiered(t *testing.T) {
+	is := assert.New(t)
+
+	backing := memory.NewMemoryBackend()
+
+	tr, err := NewTiered(backing, 2)
+	is.NoError(err)
+
+	// Writes go through to the backing store
+	is.NoError(tr.KVPut("key", &kv.Value{Content: []byte("value")}, 0))
+	val, err := backing.KVGet("key")
+	is.NoError(err)
+	is.Equal(val.Content, []byte("value"))
+
+	// KV writes leave the version to the backing store, so the first read after one misses and later reads hit
+	_, err = tr.KVGet("key")
+	is.NoError(err)
+	val, err = tr.KVGet("key")
+	is.NoError(err)
+	is.Equal(val.Content, []byte("value"))
+	is.Equal(tr.Stats(), TieredStats{Hits: 1, Misses: 1, Size: 1})
+
+	// Values read from the LRU are copies, so changing them leaves the cached value alone
+	val.Content[0] = 'V'
+	val.Metadata = map[string]string{"changed": "true"}
+	val, err = tr.KVGet("key")
+	is.NoError(err)
+	is.Equal(val.Content, []byte("value"))
+	is.Empty(val.Metadata)
+	is.Equal(tr.Stats(), TieredStats{Hits: 2, Misses: 1, Size: 1})
+
+	// Conditional writes invalidate the LRU entry so that the new version is read from the backing store
+	is.NoError(tr.KVPutIfVersion("key", &kv.Value{Content: []byte("updated")}, val.Version, 0))
+	updated, err := tr.KVGet("key")
+	is.NoError(err)
+	is.Equal(updated.Content, []byte("updated"))
+	is.True(updated.Version > val.Version)
+	// Failed writes invalidate it as well
+	is.True(purple.IsConflict(tr.KVPutIfVersion("key", &kv.Value{Content: []byte("stale")}, val.Version, 0)))
+	is.Equal(tr.Stats(), TieredStats{Hits: 2, Misses: 2, Size: 0})
+
+	// Deletes invalidate the LRU entry
+	is.NoError(tr.KVDelete("key"))
+	_, err = tr.KVGet("key")
+	is.True(purple.IsNotFound(err))
+	is.Equal(tr.Stats(), TieredStats{Hits: 2, Misses: 3, Size: 0})
+
+	// Counter increments replace the cached count
+	_, err = tr.CounterGet("counter")
+	is.NoError(err)
+	count, err := tr.CounterIncrement("counter", 5)
+	is.NoError(err)
+	is.Equal(count, int64(5))
+	count, err = tr.CounterGet("counter")
+	is.NoError(err)
+	is.Equal(count, int64(5))
+
+	// Set removals replace the cached members
+	_, err = tr.SetAdd("set", "a")
+	is.NoError(err)
+	_, err = tr.SetAdd("set", "b")
+	is.NoError(err)
+	_, err = tr.SetRemove("set", "a")
+	is.NoError(err)
+	items, err := tr.SetGet("set")
+	is.NoError(err)
+	is.Equal(items, []string{"b"})
+
+	// Callers can't modify the cached members
+	items[0] = "modified"
+	items, err = tr.SetGet("set")
+	is.NoError(err)
+	is.Equal(items, []string{"b"})
+
+	// The least recently used entry (the counter) is evicted once the LRU is full
+	is.NoError(tr.FlagSet("flag", true))
+	stats := tr.Stats()
+	is.Equal(stats.Evictions, uint64(1))
+	is.Equal(stats.Size, 2)
+
+	count, err = tr.CounterGet("counter")
+	is.NoError(err)
+	is.Equal(count, int64(5))
+	is.Equal(tr.Stats().Misses, stats.Misses+1)
+
+	// Flushing empties both tiers
+	is.NoError(tr.Flush())
+	is.Equal(tr.Stats().Size, 0)
+	flag, err := backing.FlagGet("flag")
+	is.NoError(err)
+	is.False(flag)
+
+	cfg := &purple.ServerConfig{
+		Backend: "memory",
+		Tiered:  purple.TieredConfig{Size: 10},
+	}
+
+	bk, err := NewBackend(cfg)
+	is.NoError(err)
+	_, ok := bk.Service.(*Tiered)
+	is.True(ok)
+
+	// Stats are found behind the Backend wrapper
+	_, err = bk.CounterGet("counter")
+	is.NoError(err)
+	collected := CollectStats(bk)
+	is.Equal(collected.Tiered, &TieredStats{Misses: 1, Size: 1})
+	is.Equal(collected.Memory, &memory.Stats{})
+	is.Nil(CollectStats(backing).Tiered)
+
+	is.NoError(bk.Close())
+
+	// A value whose expiry changes while it's being loaded is returned but not cached with the wrong TTL
+	racing := &expiringBackend{Memory: memory.NewMemoryBackend()}
+	is.NoError(racing.KVPut("key", &kv.Value{Content: []byte("value")}, 0))
+
+	tr, err = NewTiered(racing, 2)
+	is.NoError(err)
+
+	val, err = tr.KVGet("key")
+	is.NoError(err)
+	is.Equal(val.Content, []byte("value"))
+	is.Equal(tr.Stats().Size, 0)
+
+	ttl, err := racing.KVTTL("key")
 	is.NoError(err)
-	is.NotNil(ds)
+	is.InDelta(60, ttl, 1)
+}
+
+// Sets a TTL on the key whenever its TTL is read, as another client might have just before.
+type expiringBackend struct {
+	*memory.Memory
+}
+
+func (b *expiringBackend) KVTTL(key string) (int32, error) {
+	ttl, err := b.Memory.KVTTL(key)
+	if err != nil {
+		return 0, err
+	}
 
-	return []Service{mem, ds}
+	return ttl, b.Memory.KVExpire(key, 60)
 }
 
 func testSvc(svc Service, t *testing.T) {
@@ -48,6 +1025,13 @@ func testSvc(svc Service, t *testing.T) {
 		is.NotNil(val)
 		is.Equal(val, value)
 
+		ttl, err := svc.CacheTTL(key)
+		is.NoError(err)
+		is.InDelta(5, ttl, 1)
+
+		_, err = svc.CacheTTL("does-not-exist")
+		is.True(purple.IsNotFound(err))
+
 		is.NoError(svc.CacheSet(key, value, int32(1)))
 		time.Sleep(2 * time.Second)
 		val, err = svc.CacheGet(key)
@@ -124,7 +1108,7 @@ func testSvc(svc Service, t *testing.T) {
 			Content: []byte("here is a value"),
 		}
 
-		is.NoError(svc.KVPut(key, val))
+		is.NoError(svc.KVPut(key, val, 0))
 
 		fetched, err := svc.KVGet("does-not-exist")
 		is.True(purple.IsNotFound(err))
@@ -133,13 +1117,382 @@ func testSvc(svc Service, t *testing.T) {
 		fetched, err = svc.KVGet(key)
 		is.NoError(err)
 		is.NotNil(fetched)
-		is.Equal(fetched, val)
+		is.Equal(fetched.Content, val.Content)
+		is.NotZero(fetched.Version)
 
 		is.NoError(svc.KVDelete(key))
 		fetched, err = svc.KVGet(key)
 		is.True(purple.IsNotFound(err))
 		is.Nil(fetched)
 
+		// Empty content is a value like any other, whether it's empty or nil, as it is when it comes in over gRPC
+		for _, content := range [][]byte{{}, nil} {
+			is.NoError(svc.KVPut(key, &kv.Value{Content: content}, 0))
+
+			fetched, err = svc.KVGet(key)
+			is.NoError(err)
+			is.Empty(fetched.Content)
+		}
+
+		is.NoError(svc.Flush())
+	})
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "KVMetadata"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		key := "described"
+
+		val := &kv.Value{
+			Content:     []byte(`{"debug":true}`),
+			ContentType: "application/json",
+			Metadata:    map[string]string{"owner": "ops", "env": "prod"},
+		}
+
+		before := time.Now()
+		is.NoError(svc.KVPut(key, val, 0))
+
+		fetched, err := svc.KVGet(key)
+		is.NoError(err)
+		is.Equal(fetched.Content, val.Content)
+		is.Equal(fetched.ContentType, "application/json")
+		is.Equal(fetched.Metadata, val.Metadata)
+		is.False(fetched.Created.Before(before.Round(0)))
+		is.True(fetched.Created.Equal(fetched.Updated))
+
+		// Overwriting keeps the creation time and replaces the content type and metadata
+		time.Sleep(time.Millisecond)
+		is.NoError(svc.KVPut(key, &kv.Value{Content: []byte("plain")}, 0))
+
+		updated, err := svc.KVGet(key)
+		is.NoError(err)
+		is.Empty(updated.ContentType)
+		is.Empty(updated.Metadata)
+		is.True(updated.Created.Equal(fetched.Created))
+		is.True(updated.Updated.After(fetched.Updated))
+
+		// Deleting a key resets its creation time
+		is.NoError(svc.KVDelete(key))
+		is.NoError(svc.KVPutIfAbsent(key, val, 0))
+
+		recreated, err := svc.KVGet(key)
+		is.NoError(err)
+		is.True(recreated.Created.After(fetched.Created))
+
+		is.NoError(svc.Flush())
+	})
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "KVTTL"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		key, val := "expiring", &kv.Value{Content: []byte("session")}
+
+		is.NoError(svc.KVPut(key, val, 5))
+		ttl, err := svc.KVTTL(key)
+		is.NoError(err)
+		is.InDelta(5, ttl, 1)
+
+		// Writing without a TTL removes the expiry
+		is.NoError(svc.KVPut(key, val, 0))
+		ttl, err = svc.KVTTL(key)
+		is.NoError(err)
+		is.Equal(ttl, kv.NoTTL)
+
+		before, err := svc.KVGet(key)
+		is.NoError(err)
+
+		is.NoError(svc.KVExpire(key, 10))
+		ttl, err = svc.KVTTL(key)
+		is.NoError(err)
+		is.InDelta(10, ttl, 1)
+
+		// Changing the expiry gives the key a new version but keeps its value
+		after, err := svc.KVGet(key)
+		is.NoError(err)
+		is.Equal(after.Content, before.Content)
+		is.True(after.Version > before.Version)
+
+		is.NoError(svc.KVPersist(key))
+		ttl, err = svc.KVTTL(key)
+		is.NoError(err)
+		is.Equal(ttl, kv.NoTTL)
+
+		_, err = svc.KVTTL("does-not-exist")
+		is.True(purple.IsNotFound(err))
+		is.True(purple.IsNotFound(svc.KVExpire("does-not-exist", 5)))
+		is.True(purple.IsNotFound(svc.KVPersist("does-not-exist")))
+		is.Equal(svc.KVExpire(key, 0), purple.ErrNonPositiveKVTTL)
+		is.Equal(svc.KVPut(key, val, -1), purple.ErrNegativeKVTTL)
+
+		// Expired keys are gone for every operation
+		is.NoError(svc.KVPut(key, val, 1))
+		time.Sleep(2 * time.Second)
+
+		_, err = svc.KVGet(key)
+		is.True(purple.IsNotFound(err))
+		_, err = svc.KVTTL(key)
+		is.True(purple.IsNotFound(err))
+		keys, _, err := svc.KVList("", "", 0)
+		is.NoError(err)
+		is.NotContains(keys, key)
+		is.NoError(svc.KVPutIfAbsent(key, val, 0))
+
+		is.NoError(svc.Flush())
+	})
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "KVBatch"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		is.NoError(svc.KVPut("b", &kv.Value{Content: []byte("old")}, 0))
+
+		before, err := svc.KVGet("b")
+		is.NoError(err)
+
+		is.NoError(svc.KVPutMany([]*kv.Entry{
+			{Key: "a", Value: &kv.Value{Content: []byte("1"), ContentType: "text/plain"}},
+			{Key: "b", Value: &kv.Value{Content: []byte("2")}, TTL: 30},
+		}))
+
+		values, err := svc.KVGetMany([]string{"a", "missing", "b"})
+		is.NoError(err)
+		is.Len(values, 3)
+		is.Equal(values[0].Content, []byte("1"))
+		is.Equal(values[0].ContentType, "text/plain")
+		is.Nil(values[1])
+		is.Equal(values[2].Content, []byte("2"))
+		is.True(values[2].Version > before.Version)
+		is.True(values[2].Created.Equal(before.Created))
+
+		ttl, err := svc.KVTTL("b")
+		is.NoError(err)
+		is.InDelta(30, ttl, 1)
+
+		existed, err := svc.KVDeleteMany([]string{"a", "missing", "a"})
+		is.NoError(err)
+		is.Equal(existed, []bool{true, false, false})
+
+		_, err = svc.KVGet("a")
+		is.True(purple.IsNotFound(err))
+
+		values, err = svc.KVGetMany(nil)
+		is.NoError(err)
+		is.Empty(values)
+
+		// Invalid batches are rejected as a whole
+		is.Equal(svc.KVPutMany([]*kv.Entry{
+			{Key: "c", Value: &kv.Value{Content: []byte("3")}},
+			{Key: "d", Value: &kv.Value{Content: []byte("4")}, TTL: -1},
+		}), purple.ErrNegativeKVTTL)
+		_, err = svc.KVGet("c")
+		is.True(purple.IsNotFound(err))
+
+		_, err = svc.KVGetMany(make([]string, kv.MaxBatchSize+1))
+		is.Equal(err, purple.ErrKVBatchTooLarge)
+
+		is.NoError(svc.Flush())
+	})
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "Txn"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		if comp, ok := svc.(*Composite); ok && comp.txn == nil {
+			_, err := svc.Txn([]*txn.Op{{Kind: txn.CounterGet, Key: "count"}})
+			is.Equal(err, purple.ErrTxnSpansBackends)
+			return
+		}
+
+		// Reading first puts the values into the LRU of a tiered backend, which the transaction must invalidate
+		is.NoError(svc.KVPut("doc", &kv.Value{Content: []byte("v1")}, 0))
+		_, err := svc.KVGet("doc")
+		is.NoError(err)
+		_, err = svc.CounterGet("count")
+		is.NoError(err)
+
+		results, err := svc.Txn([]*txn.Op{
+			{Kind: txn.SetAdd, Key: "members", Item: "alice"},
+			{Kind: txn.CounterIncr, Key: "count", Amount: 1},
+			{Kind: txn.KVPut, Key: "doc", Value: &kv.Value{Content: []byte("v2")}, TTL: 60},
+			{Kind: txn.FlagSet, Key: "dirty", Flag: true},
+			{Kind: txn.KVGet, Key: "doc"},
+			{Kind: txn.CounterIncr, Key: "count", Amount: 2},
+			{Kind: txn.SetGet, Key: "members"},
+			{Kind: txn.FlagGet, Key: "dirty"},
+			{Kind: txn.KVDelete, Key: "missing"},
+			{Kind: txn.SetRemove, Key: "no-set", Item: "x"},
+			{Kind: txn.CounterGet, Key: "count"},
+		})
+		is.NoError(err)
+		is.Len(results, 11)
+
+		// Each operation sees the effects of the ones before it
+		is.Equal(results[0].Items, []string{"alice"})
+		is.Equal(results[1].Count, int64(1))
+		is.True(results[4].Found)
+		is.Equal(results[4].Value.Content, []byte("v2"))
+		is.Equal(results[5].Count, int64(3))
+		is.True(results[6].Found)
+		is.Equal(results[6].Items, []string{"alice"})
+		is.True(results[7].Flag)
+		is.False(results[8].Found)
+		is.False(results[9].Found)
+		is.Empty(results[9].Items)
+		is.Equal(results[10].Count, int64(3))
+
+		doc, err := svc.KVGet("doc")
+		is.NoError(err)
+		is.Equal(doc.Content, []byte("v2"))
+		ttl, err := svc.KVTTL("doc")
+		is.NoError(err)
+		is.InDelta(60, ttl, 1)
+		count, err := svc.CounterGet("count")
+		is.NoError(err)
+		is.Equal(count, int64(3))
+		flag, err := svc.FlagGet("dirty")
+		is.NoError(err)
+		is.True(flag)
+		items, err := svc.SetGet("members")
+		is.NoError(err)
+		is.Equal(items, []string{"alice"})
+
+		// Invalid transactions are rejected before any of their operations are applied
+		_, err = svc.Txn([]*txn.Op{
+			{Kind: txn.CounterIncr, Key: "count", Amount: 1},
+			{Kind: "counter.reset", Key: "count"},
+		})
+		is.Equal(err, purple.ErrUnknownTxnOp)
+		_, err = svc.Txn([]*txn.Op{
+			{Kind: txn.CounterIncr, Key: "count", Amount: 1},
+			{Kind: txn.KVPut, Key: "doc", Value: &kv.Value{Content: []byte("v3")}, TTL: -1},
+		})
+		is.Equal(err, purple.ErrNegativeKVTTL)
+
+		count, err = svc.CounterGet("count")
+		is.NoError(err)
+		is.Equal(count, int64(3))
+
+		results, err = svc.Txn(nil)
+		is.NoError(err)
+		is.Empty(results)
+
+		is.NoError(svc.Flush())
+	})
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "TxnSession"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		if comp, ok := svc.(*Composite); ok && comp.txn == nil {
+			_, err := svc.TxnBegin()
+			is.Equal(err, purple.ErrTxnSpansBackends)
+			return
+		}
+
+		is.NoError(svc.KVPut("balance", &kv.Value{Content: []byte("100")}, 0))
+		_, err := svc.CounterIncrement("count", 5)
+		is.NoError(err)
+		_, err = svc.SetAdd("members", "alice")
+		is.NoError(err)
+
+		reads := []*txn.Op{
+			{Kind: txn.KVGet, Key: "balance"},
+			{Kind: txn.CounterGet, Key: "count"},
+			{Kind: txn.SetGet, Key: "members"},
+			{Kind: txn.FlagGet, Key: "dirty"},
+		}
+
+		s, err := svc.TxnBegin()
+		is.NoError(err)
+		results, err := s.Read(reads)
+		is.NoError(err)
+		is.Len(results, 4)
+		is.Equal(results[0].Value.Content, []byte("100"))
+		is.Equal(results[1].Count, int64(5))
+		is.Equal(results[2].Items, []string{"alice"})
+		is.False(results[3].Flag)
+
+		// Writing keys the session didn't read doesn't conflict
+		is.NoError(svc.KVPut("other", &kv.Value{Content: []byte("x")}, 0))
+
+		results, err = s.Commit([]*txn.Op{
+			{Kind: txn.KVPut, Key: "balance", Value: &kv.Value{Content: []byte("90")}},
+			{Kind: txn.CounterIncr, Key: "count", Amount: 1},
+			{Kind: txn.FlagSet, Key: "dirty", Flag: true},
+		})
+		is.NoError(err)
+		is.Len(results, 3)
+		is.Equal(results[1].Count, int64(6))
+
+		balance, err := svc.KVGet("balance")
+		is.NoError(err)
+		is.Equal(balance.Content, []byte("90"))
+
+		// Every kind of read conflicts with a write made after it
+		for _, write := range []func() error{
+			func() error { return svc.KVPut("balance", &kv.Value{Content: []byte("80")}, 0) },
+			func() error { _, err := svc.CounterIncrement("count", 1); return err },
+			func() error { _, err := svc.SetAdd("members", "bob"); return err },
+			func() error { return svc.FlagSet("dirty", false) },
+		} {
+			s, err := svc.TxnBegin()
+			is.NoError(err)
+			_, err = s.Read(reads)
+			is.NoError(err)
+
+			is.NoError(write())
+
+			_, err = s.Commit([]*txn.Op{{Kind: txn.KVPut, Key: "balance", Value: &kv.Value{Content: []byte("0")}}})
+			is.True(purple.IsConflict(err))
+		}
+
+		balance, err = svc.KVGet("balance")
+		is.NoError(err)
+		is.Equal(balance.Content, []byte("80"))
+
+		// Aborted sessions apply nothing
+		s, err = svc.TxnBegin()
+		is.NoError(err)
+		_, err = s.Read(reads)
+		is.NoError(err)
+		s.Abort()
+
+		is.NoError(svc.Flush())
+	})
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "KVVersions"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		key := "versioned"
+
+		is.NoError(svc.KVPutIfAbsent(key, &kv.Value{Content: []byte("v1")}, 0))
+		is.True(purple.IsConflict(svc.KVPutIfAbsent(key, &kv.Value{Content: []byte("v2")}, 0)))
+
+		first, err := svc.KVGet(key)
+		is.NoError(err)
+		is.Equal(first.Content, []byte("v1"))
+
+		// Only a write against the current version succeeds, and it moves the version forward
+		is.NoError(svc.KVPutIfVersion(key, &kv.Value{Content: []byte("v2")}, first.Version, 0))
+		is.True(purple.IsConflict(svc.KVPutIfVersion(key, &kv.Value{Content: []byte("v3")}, first.Version, 0)))
+
+		second, err := svc.KVGet(key)
+		is.NoError(err)
+		is.Equal(second.Content, []byte("v2"))
+		is.True(second.Version > first.Version)
+
+		// Missing keys have no version to match
+		is.True(purple.IsConflict(svc.KVPutIfVersion("missing", &kv.Value{Content: []byte("v1")}, second.Version, 0)))
+		is.True(purple.IsConflict(svc.KVDeleteIfVersion("missing", second.Version)))
+
+		is.True(purple.IsConflict(svc.KVDeleteIfVersion(key, first.Version)))
+		is.NoError(svc.KVDeleteIfVersion(key, second.Version))
+		_, err = svc.KVGet(key)
+		is.True(purple.IsNotFound(err))
+
+		// Versions aren't reused once a key is deleted and written again, even across a flush
+		is.NoError(svc.Flush())
+		is.NoError(svc.KVPut(key, &kv.Value{Content: []byte("v4")}, 0))
+		third, err := svc.KVGet(key)
+		is.NoError(err)
+		is.True(third.Version > second.Version)
+
 		is.NoError(svc.Flush())
 	})
 
@@ -180,4 +1533,270 @@ func testSvc(svc Service, t *testing.T) {
 
 		is.NoError(svc.Flush())
 	})
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "SetAlgebra"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		for set, items := range map[string][]string{
+			"a": {"1", "2", "3"},
+			"b": {"2", "3", "4"},
+			"c": {"3", "5"},
+		} {
+			for _, item := range items {
+				_, err := svc.SetAdd(set, item)
+				is.NoError(err)
+			}
+		}
+
+		ok, err := svc.SetContains("a", "1")
+		is.NoError(err)
+		is.True(ok)
+		ok, err = svc.SetContains("a", "4")
+		is.NoError(err)
+		is.False(ok)
+		ok, err = svc.SetContains("missing", "1")
+		is.NoError(err)
+		is.False(ok)
+
+		size, err := svc.SetSize("a")
+		is.NoError(err)
+		is.Equal(size, 3)
+		size, err = svc.SetSize("missing")
+		is.NoError(err)
+		is.Zero(size)
+
+		// Redis returns the items in no particular order
+		items, err := svc.SetUnion("a", "b", "c", "missing")
+		is.NoError(err)
+		is.ElementsMatch(items, []string{"1", "2", "3", "4", "5"})
+		items, err = svc.SetIntersect("a", "b", "c")
+		is.NoError(err)
+		is.ElementsMatch(items, []string{"3"})
+		items, err = svc.SetIntersect("a", "a", "b")
+		is.NoError(err)
+		is.ElementsMatch(items, []string{"2", "3"})
+		items, err = svc.SetIntersect("a", "missing")
+		is.NoError(err)
+		is.Empty(items)
+		is.NotNil(items)
+		items, err = svc.SetDiff("a", "b")
+		is.NoError(err)
+		is.ElementsMatch(items, []string{"1"})
+		items, err = svc.SetDiff("b", "a", "c")
+		is.NoError(err)
+		is.ElementsMatch(items, []string{"4"})
+		items, err = svc.SetDiff("a")
+		is.NoError(err)
+		is.ElementsMatch(items, []string{"1", "2", "3"})
+
+		_, err = svc.SetUnion()
+		is.Equal(err, purple.ErrNoSets)
+
+		// Reading the destination first puts it into the LRU of a tiered backend, which storing must invalidate
+		_, err = svc.SetAdd("dest", "stale")
+		is.NoError(err)
+		_, err = svc.SetGet("dest")
+		is.NoError(err)
+
+		size, err = svc.SetUnionStore("dest", "a", "b")
+		is.NoError(err)
+		is.Equal(size, 4)
+		items, err = svc.SetGet("dest")
+		is.NoError(err)
+		is.ElementsMatch(items, []string{"1", "2", "3", "4"})
+
+		// The destination may be one of the sets
+		size, err = svc.SetIntersectStore("dest", "dest", "c")
+		is.NoError(err)
+		is.Equal(size, 1)
+		items, err = svc.SetGet("dest")
+		is.NoError(err)
+		is.Equal(items, []string{"3"})
+
+		size, err = svc.SetDiffStore("dest", "a", "b", "c")
+		is.NoError(err)
+		is.Equal(size, 1)
+		items, err = svc.SetGet("dest")
+		is.NoError(err)
+		is.Equal(items, []string{"1"})
+
+		size, err = svc.SetDiffStore("dest", "c", "a", "b", "c")
+		is.NoError(err)
+		is.Zero(size)
+		size, err = svc.SetSize("dest")
+		is.NoError(err)
+		is.Zero(size)
+
+		_, err = svc.SetUnionStore("", "a")
+		is.Equal(err, purple.ErrNoKey)
+
+		is.NoError(svc.Flush())
+	})
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "SetSampling"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		var all []string
+
+		for i := 0; i < 25; i++ {
+			item := fmt.Sprintf("item-%02d", i)
+			all = append(all, item)
+			_, err := svc.SetAdd("big", item)
+			is.NoError(err)
+		}
+
+		// Redis may return more items per page than the limit
+		var paged []string
+
+		for cursor, pages := "", 0; pages == 0 || cursor != ""; pages++ {
+			page, next, err := svc.SetGetPage("big", cursor, 10)
+			is.NoError(err)
+			is.NotEmpty(page)
+			paged = append(paged, page...)
+			cursor = next
+		}
+
+		is.ElementsMatch(paged, all)
+
+		// Reading the set first puts it into the LRU of a tiered backend, which the mutations below must invalidate
+		_, err := svc.SetGet("big")
+		is.NoError(err)
+
+		summary, err := svc.SetAddSummary("big", "new")
+		is.NoError(err)
+		is.Equal(summary, &set.Summary{Changed: true, Size: 26})
+		summary, err = svc.SetAddSummary("big", "new")
+		is.NoError(err)
+		is.Equal(summary, &set.Summary{Changed: false, Size: 26})
+
+		items, err := svc.SetGet("big")
+		is.NoError(err)
+		is.Contains(items, "new")
+
+		summary, err = svc.SetRemoveSummary("big", "new")
+		is.NoError(err)
+		is.Equal(summary, &set.Summary{Changed: true, Size: 25})
+		summary, err = svc.SetRemoveSummary("big", "new")
+		is.NoError(err)
+		is.Equal(summary, &set.Summary{Changed: false, Size: 25})
+
+		items, err = svc.SetGet("big")
+		is.NoError(err)
+		is.NotContains(items, "new")
+
+		random, err := svc.SetRandom("big", 5)
+		is.NoError(err)
+		is.Len(random, 5)
+		is.Len(data.NewSet(random...).Get(), 5)
+		is.Subset(all, random)
+
+		// A negative count may repeat items
+		random, err = svc.SetRandom("big", -30)
+		is.NoError(err)
+		is.Len(random, 30)
+		is.Subset(all, random)
+
+		// Every backend treats sets that don't exist as empty here
+		page, next, err := svc.SetGetPage("missing", "", 10)
+		is.NoError(err)
+		is.Empty(page)
+		is.NotNil(page)
+		is.Empty(next)
+
+		summary, err = svc.SetRemoveSummary("missing", "item")
+		is.NoError(err)
+		is.Equal(summary, &set.Summary{Changed: false, Size: 0})
+
+		size, err := svc.SetSize("missing")
+		is.NoError(err)
+		is.Zero(size)
+
+		random, err = svc.SetRandom("missing", 3)
+		is.NoError(err)
+		is.Empty(random)
+		is.NotNil(random)
+
+		random, err = svc.SetRandom("missing", -3)
+		is.NoError(err)
+		is.Empty(random)
+		is.NotNil(random)
+
+		popped, err := svc.SetPop("big", 3)
+		is.NoError(err)
+		is.Len(popped, 3)
+		is.Subset(all, popped)
+
+		items, err = svc.SetGet("big")
+		is.NoError(err)
+		is.Len(items, 22)
+		for _, item := range popped {
+			is.NotContains(items, item)
+		}
+
+		_, err = svc.SetPop("big", -1)
+		is.Equal(err, purple.ErrNegativeSetPopCount)
+
+		popped, err = svc.SetPop("missing", 2)
+		is.NoError(err)
+		is.Empty(popped)
+
+		is.NoError(svc.Flush())
+	})
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "List"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		keys := []string{"user:1", "user:2", "user:3", "user:4", "user:5", "other"}
+		expected := keys[:5]
+
+		for _, key := range keys {
+			is.NoError(svc.CacheSet(key, "value", 30))
+			_, err := svc.CounterIncrement(key, 1)
+			is.NoError(err)
+			is.NoError(svc.FlagSet(key, true))
+			is.NoError(svc.KVPut(key, &kv.Value{Content: []byte("value")}, 0))
+			_, err = svc.SetAdd(key, "item")
+			is.NoError(err)
+		}
+
+		lists := map[string]func(prefix, cursor string, limit int) ([]string, string, error){
+			"cache":   svc.CacheList,
+			"counter": svc.CounterList,
+			"flag":    svc.FlagList,
+			"kv":      svc.KVList,
+			"set":     svc.SetList,
+		}
+
+		for name, list := range lists {
+			var (
+				listed []string
+				cursor string
+				pages  int
+			)
+
+			for {
+				page, next, err := list("user:", cursor, 2)
+				is.NoError(err, name)
+
+				listed = append(listed, page...)
+				pages++
+
+				if next == "" || pages > len(keys) {
+					break
+				}
+
+				cursor = next
+			}
+
+			sort.Strings(listed)
+			is.Equal(listed, expected, name)
+
+			page, next, err := list("does-not-exist:", "", 2)
+			is.NoError(err, name)
+			is.Empty(page, name)
+			is.Empty(next, name)
+		}
+
+		is.NoError(svc.Flush())
+	})
 }
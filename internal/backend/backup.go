@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/backend/disk"
+)
+
+// Backuper is implemented by backends that can take online backups.
+type Backuper interface {
+	Backup(since uint64) (*disk.BackupInfo, error)
+}
+
+var _ Backuper = (*disk.Disk)(nil)
+
+// Backup takes an online backup of the backend behind svc, looking through Backend, Tiered, and Composite wrappers. A
+// Composite can only be backed up if every service is routed to the same backend and that backend supports backups;
+// otherwise the error names the services that would be left out.
+func Backup(svc Service, since uint64) (*disk.BackupInfo, error) {
+	if c, ok := unwrap(svc).(*Composite); ok {
+		return backupComposite(c, since)
+	}
+
+	b := backuper(svc)
+	if b == nil {
+		return nil, purple.ErrBackupNotSupported
+	}
+
+	return b.Backup(since)
+}
+
+func backupComposite(c *Composite, since uint64) (*disk.BackupInfo, error) {
+	routed := map[string]interface{}{
+		"cache":   c.Cache,
+		"counter": c.Counter,
+		"flag":    c.Flag,
+		"kv":      c.KV,
+		"set":     c.Set,
+	}
+
+	var (
+		target  Backuper
+		skipped []string
+	)
+
+	for _, name := range purple.Services {
+		b := backuper(routed[name].(Service))
+
+		if target == nil {
+			target = b
+		}
+
+		if b == nil || b != target {
+			skipped = append(skipped, name)
+		}
+	}
+
+	if len(skipped) > 0 {
+		return nil, fmt.Errorf("%w (not backed up: %s)", purple.ErrBackupNotSupported, strings.Join(skipped, ", "))
+	}
+
+	return target.Backup(since)
+}
+
+// Returns the backend behind the Backend and Tiered wrappers.
+func unwrap(svc Service) Service {
+	switch s := svc.(type) {
+	case *Backend:
+		return unwrap(s.Service)
+	case *Tiered:
+		return unwrap(s.Service)
+	}
+
+	return svc
+}
+
+// Returns the backend behind svc that takes backups, or nil if there isn't one.
+func backuper(svc Service) Backuper {
+	b, _ := unwrap(svc).(Backuper)
+
+	return b
+}
@@ -0,0 +1,1064 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"time"
+
+	"github.com/purpledb/purple/internal/util"
+	"github.com/purpledb/purple/services/flag"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/services/cache"
+	"github.com/purpledb/purple/services/counter"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
+
+	"github.com/purpledb/purple/internal/data"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// How long to wait for the file lock when another process has the database open
+	openTimeout = time.Second
+
+	defaultCleanupInterval = time.Minute
+)
+
+var (
+	cacheBucket   = []byte("cache")
+	counterBucket = []byte("counter")
+	flagBucket    = []byte("flag")
+	kvBucket      = []byte("kv")
+	setBucket     = []byte("set")
+
+	buckets = [][]byte{cacheBucket, counterBucket, flagBucket, kvBucket, setBucket}
+)
+
+// Bolt stores all services in a single bbolt file with a bucket per service. Each set is a nested bucket inside the
+// set bucket with one key per member.
+type Bolt struct {
+	db   *bolt.DB
+	done chan struct{}
+}
+
+func (b *Bolt) Name() string {
+	return "bolt"
+}
+
+var (
+	_ cache.Cache     = (*Bolt)(nil)
+	_ counter.Counter = (*Bolt)(nil)
+	_ flag.Flag       = (*Bolt)(nil)
+	_ kv.KV           = (*Bolt)(nil)
+	_ set.Set         = (*Bolt)(nil)
+)
+
+func NewBoltBackend(cfg *purple.BoltConfig) (*Bolt, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	path, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := util.MkDirIfNotExists(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(createBuckets); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	b := &Bolt{
+		db:   db,
+		done: make(chan struct{}),
+	}
+
+	interval := cfg.CleanupInterval
+	if interval == 0 {
+		interval = defaultCleanupInterval
+	}
+
+	go b.cleanup(interval)
+
+	return b, nil
+}
+
+// Periodically deletes expired cache items and KV keys, which are otherwise only skipped when read.
+func (b *Bolt) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = b.db.Update(func(tx *bolt.Tx) error {
+				n := now()
+
+				if err := deleteExpired(tx.Bucket(cacheBucket), func(v []byte) bool {
+					_, expiresAt := decodeCacheItem(v)
+					return expiresAt <= n
+				}); err != nil {
+					return err
+				}
+
+				return deleteExpired(tx.Bucket(kvBucket), func(v []byte) bool {
+					return kvExpired(v, n)
+				})
+			})
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func createBuckets(tx *bolt.Tx) error {
+	for _, name := range buckets {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Service methods
+func (b *Bolt) Close() error {
+	close(b.done)
+
+	return b.db.Close()
+}
+
+// Flushes every bucket. The KV bucket's sequence is kept so that KV versions never repeat.
+func (b *Bolt) Flush() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		kvSequence := tx.Bucket(kvBucket).Sequence()
+
+		for _, name := range buckets {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+
+		if err := createBuckets(tx); err != nil {
+			return err
+		}
+
+		return tx.Bucket(kvBucket).SetSequence(kvSequence)
+	})
+}
+
+// Cache items are stored as an 8-byte expiry timestamp (Unix milliseconds) followed by the value
+func encodeCacheItem(value string, expiresAt int64) []byte {
+	bs := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(bs, uint64(expiresAt))
+	copy(bs[8:], value)
+	return bs
+}
+
+func decodeCacheItem(bs []byte) (string, int64) {
+	return string(bs[8:]), int64(binary.BigEndian.Uint64(bs[:8]))
+}
+
+// KV values are stored as an 8-byte version and an 8-byte expiry timestamp (Unix milliseconds, 0 if the key doesn't
+// expire) followed by the kv.Value encoding of the rest of the value
+const kvHeaderSize = 16
+
+func encodeKVValue(value *kv.Value, expiresAt int64) ([]byte, error) {
+	bs, err := value.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, kvHeaderSize, kvHeaderSize+len(bs))
+	binary.BigEndian.PutUint64(header, value.Version)
+	binary.BigEndian.PutUint64(header[8:], uint64(expiresAt))
+
+	return append(header, bs...), nil
+}
+
+// Copies the value out of bs, since byte slices returned by bbolt are only valid for the life of the transaction.
+func decodeKVValue(bs []byte) (*kv.Value, error) {
+	if len(bs) < kvHeaderSize {
+		return nil, purple.ErrInvalidKVValue
+	}
+
+	value, err := kv.BytesToValue(bs[kvHeaderSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	value.Version = binary.BigEndian.Uint64(bs[:8])
+
+	return value, nil
+}
+
+func kvExpiresAt(bs []byte) int64 {
+	if len(bs) < kvHeaderSize {
+		return 0
+	}
+
+	return int64(binary.BigEndian.Uint64(bs[8:kvHeaderSize]))
+}
+
+func kvExpired(bs []byte, n int64) bool {
+	expiresAt := kvExpiresAt(bs)
+
+	return expiresAt != 0 && expiresAt <= n
+}
+
+// Returns the KV value stored under key, or nil if it doesn't exist or has expired. Expired values are only skipped
+// here and deleted by the cleanup.
+func liveKV(bk *bolt.Bucket, key string) []byte {
+	bs := bk.Get([]byte(key))
+	if bs == nil || kvExpired(bs, now()) {
+		return nil
+	}
+
+	return bs
+}
+
+func now() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+// Cache
+func (b *Bolt) CacheGet(key string) (string, error) {
+	var value string
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		bs := tx.Bucket(cacheBucket).Get([]byte(key))
+		if bs == nil {
+			return purple.NotFound(key)
+		}
+
+		val, expiresAt := decodeCacheItem(bs)
+		if expiresAt <= now() {
+			return purple.NotFound(key)
+		}
+
+		value = val
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+func (b *Bolt) CacheSet(key, value string, ttl int32) error {
+	if key == "" {
+		return purple.ErrNoKey
+	}
+
+	if value == "" {
+		return purple.ErrNoValue
+	}
+
+	if ttl == 0 {
+		ttl = cache.DefaultTtl
+	}
+
+	expiresAt := now() + int64(ttl)*1000
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(cacheBucket).Put([]byte(key), encodeCacheItem(value, expiresAt))
+	})
+}
+
+// Returns the number of seconds until a cache item expires, rounded up.
+func (b *Bolt) CacheTTL(key string) (int32, error) {
+	var ttl int32
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		bs := tx.Bucket(cacheBucket).Get([]byte(key))
+		if bs == nil {
+			return purple.NotFound(key)
+		}
+
+		_, expiresAt := decodeCacheItem(bs)
+
+		n := now()
+		if expiresAt <= n {
+			return purple.NotFound(key)
+		}
+
+		ttl = int32((expiresAt - n + 999) / 1000)
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return ttl, nil
+}
+
+func (b *Bolt) CacheList(prefix, cursor string, limit int) ([]string, string, error) {
+	n := now()
+
+	return b.list(cacheBucket, prefix, cursor, limit, func(v []byte) bool {
+		_, expiresAt := decodeCacheItem(v)
+		return expiresAt > n
+	})
+}
+
+func deleteExpired(bk *bolt.Bucket, isExpired func(v []byte) bool) error {
+	var expired [][]byte
+
+	if err := bk.ForEach(func(k, v []byte) error {
+		if isExpired(v) {
+			expired = append(expired, k)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, k := range expired {
+		if err := bk.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Counter
+func (b *Bolt) CounterGet(key string) (int64, error) {
+	var count int64
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		if bs := tx.Bucket(counterBucket).Get([]byte(key)); bs != nil {
+			count = data.BytesToInt64(bs)
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (b *Bolt) CounterIncrement(key string, increment int64) (int64, error) {
+	k := []byte(key)
+
+	var count int64
+
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(counterBucket)
+
+		count = increment
+
+		if bs := bk.Get(k); bs != nil {
+			count += data.BytesToInt64(bs)
+		}
+
+		return bk.Put(k, data.Int64ToBytes(count))
+	}); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (b *Bolt) CounterList(prefix, cursor string, limit int) ([]string, string, error) {
+	return b.list(counterBucket, prefix, cursor, limit, nil)
+}
+
+// Flag
+func (b *Bolt) FlagGet(key string) (bool, error) {
+	var value bool
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		bs := tx.Bucket(flagBucket).Get([]byte(key))
+		if bs == nil {
+			return nil
+		}
+
+		val, err := data.BoolFromBytes(bs)
+		if err != nil {
+			return err
+		}
+
+		value = val
+
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return value, nil
+}
+
+func (b *Bolt) FlagSet(key string, value bool) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(flagBucket).Put([]byte(key), data.BoolAsBytes(value))
+	})
+}
+
+func (b *Bolt) FlagList(prefix, cursor string, limit int) ([]string, string, error) {
+	return b.list(flagBucket, prefix, cursor, limit, nil)
+}
+
+// KV
+func (b *Bolt) KVGet(key string) (*kv.Value, error) {
+	var value *kv.Value
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		bs := liveKV(tx.Bucket(kvBucket), key)
+		if bs == nil {
+			return purple.NotFound(key)
+		}
+
+		var err error
+
+		value, err = decodeKVValue(bs)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (b *Bolt) KVPut(key string, value *kv.Value, ttl int32) error {
+	return b.putKV(key, value, ttl, func([]byte) bool { return true })
+}
+
+func (b *Bolt) KVPutIfVersion(key string, value *kv.Value, version uint64, ttl int32) error {
+	return b.putKV(key, value, ttl, hasVersion(version))
+}
+
+func (b *Bolt) KVPutIfAbsent(key string, value *kv.Value, ttl int32) error {
+	return b.putKV(key, value, ttl, func(current []byte) bool { return current == nil })
+}
+
+// Stores the value with its timestamps and expiry if check accepts the stored value, which is nil if the key doesn't exist, and fails with a conflict error otherwise.
+func (b *Bolt) putKV(key string, value *kv.Value, ttl int32, check func(current []byte) bool) error {
+	if err := kv.CheckTTL(ttl); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(kvBucket)
+
+		bs := liveKV(bk, key)
+
+		if !check(bs) {
+			return purple.Conflict(key)
+		}
+
+		return storeKV(bk, key, bs, value, ttl)
+	})
+}
+
+// Stores the value in place of current, the live value stored under key or nil, under the next sequence number of the
+// KV bucket.
+func storeKV(bk *bolt.Bucket, key string, current []byte, value *kv.Value, ttl int32) error {
+	var (
+		currentValue *kv.Value
+		err          error
+	)
+
+	if current != nil {
+		if currentValue, err = decodeKVValue(current); err != nil {
+			return err
+		}
+	}
+
+	stored := value.Stamped(currentValue, time.Now())
+
+	if stored.Version, err = bk.NextSequence(); err != nil {
+		return err
+	}
+
+	bs, err := encodeKVValue(stored, expiresAt(ttl))
+	if err != nil {
+		return err
+	}
+
+	return bk.Put([]byte(key), bs)
+}
+
+func (b *Bolt) KVDelete(key string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(kvBucket).Delete([]byte(key))
+	})
+}
+
+func (b *Bolt) KVDeleteIfVersion(key string, version uint64) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(kvBucket)
+
+		if !hasVersion(version)(liveKV(bk, key)) {
+			return purple.Conflict(key)
+		}
+
+		return bk.Delete([]byte(key))
+	})
+}
+
+func hasVersion(version uint64) func(current []byte) bool {
+	return func(current []byte) bool {
+		return len(current) >= kvHeaderSize && binary.BigEndian.Uint64(current[:8]) == version
+	}
+}
+
+// Returns the number of seconds until a key expires, rounded up, or kv.NoTTL.
+func (b *Bolt) KVTTL(key string) (int32, error) {
+	var ttl int32
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		bs := liveKV(tx.Bucket(kvBucket), key)
+		if bs == nil {
+			return purple.NotFound(key)
+		}
+
+		ttl = kv.NoTTL
+
+		if expiresAt := kvExpiresAt(bs); expiresAt != 0 {
+			ttl = int32((expiresAt - now() + 999) / 1000)
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return ttl, nil
+}
+
+func (b *Bolt) KVExpire(key string, ttl int32) error {
+	if err := kv.CheckExpireTTL(ttl); err != nil {
+		return err
+	}
+
+	return b.expireKV(key, ttl)
+}
+
+func (b *Bolt) KVPersist(key string) error {
+	return b.expireKV(key, 0)
+}
+
+// Rewrites the header of the value stored under key with a new version and expiry.
+func (b *Bolt) expireKV(key string, ttl int32) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(kvBucket)
+
+		current := liveKV(bk, key)
+		if current == nil {
+			return purple.NotFound(key)
+		}
+
+		version, err := bk.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		bs := make([]byte, len(current))
+		copy(bs, current)
+		binary.BigEndian.PutUint64(bs, version)
+		binary.BigEndian.PutUint64(bs[8:], uint64(expiresAt(ttl)))
+
+		return bk.Put([]byte(key), bs)
+	})
+}
+
+// Returns when a KV key written now with the TTL expires, in Unix milliseconds, or 0 if the TTL is 0.
+func expiresAt(ttl int32) int64 {
+	if ttl == 0 {
+		return 0
+	}
+
+	return now() + int64(ttl)*1000
+}
+
+func (b *Bolt) KVList(prefix, cursor string, limit int) ([]string, string, error) {
+	n := now()
+
+	return b.list(kvBucket, prefix, cursor, limit, func(v []byte) bool {
+		return !kvExpired(v, n)
+	})
+}
+
+// The batch operations each run in a single transaction, so a batch is applied atomically.
+func (b *Bolt) KVGetMany(keys []string) ([]*kv.Value, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
+		return nil, err
+	}
+
+	values := make([]*kv.Value, len(keys))
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(kvBucket)
+
+		for i, key := range keys {
+			if bs := liveKV(bk, key); bs != nil {
+				var err error
+
+				if values[i], err = decodeKVValue(bs); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (b *Bolt) KVPutMany(entries []*kv.Entry) error {
+	if err := kv.CheckEntries(entries); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(kvBucket)
+
+		for _, e := range entries {
+			if err := storeKV(bk, e.Key, liveKV(bk, e.Key), e.Value, e.TTL); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (b *Bolt) KVDeleteMany(keys []string) ([]bool, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
+		return nil, err
+	}
+
+	existed := make([]bool, len(keys))
+
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(kvBucket)
+
+		for i, key := range keys {
+			existed[i] = liveKV(bk, key) != nil
+
+			if err := bk.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return existed, nil
+}
+
+// Set
+func (b *Bolt) SetGet(set string) ([]string, error) {
+	var items []string
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(setBucket).Bucket([]byte(set))
+		if bk == nil {
+			return purple.NotFound(set)
+		}
+
+		var err error
+		items, err = members(bk)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (b *Bolt) SetAdd(set, item string) ([]string, error) {
+	var items []string
+
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		bk, err := tx.Bucket(setBucket).CreateBucketIfNotExists([]byte(set))
+		if err != nil {
+			return err
+		}
+
+		if err := bk.Put([]byte(item), []byte{}); err != nil {
+			return err
+		}
+
+		items, err = members(bk)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (b *Bolt) SetRemove(set, item string) ([]string, error) {
+	var items []string
+
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(setBucket).Bucket([]byte(set))
+		if bk == nil {
+			return purple.NotFound(set)
+		}
+
+		if err := bk.Delete([]byte(item)); err != nil {
+			return err
+		}
+
+		var err error
+		items, err = members(bk)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (b *Bolt) SetGetPage(key, cursor string, limit int) ([]string, string, error) {
+	var (
+		page []string
+		next string
+	)
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(setBucket).Bucket([]byte(key))
+		if bk == nil {
+			page = make([]string, 0)
+			return nil
+		}
+
+		page, next = listBucket(bk, "", cursor, limit, nil)
+
+		return nil
+	}); err != nil {
+		return nil, "", err
+	}
+
+	return page, next, nil
+}
+
+func (b *Bolt) SetAddSummary(key, item string) (*set.Summary, error) {
+	var changed bool
+
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		bk, err := tx.Bucket(setBucket).CreateBucketIfNotExists([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		changed = bk.Get([]byte(item)) == nil
+
+		return bk.Put([]byte(item), []byte{})
+	}); err != nil {
+		return nil, err
+	}
+
+	return b.summary(key, changed)
+}
+
+func (b *Bolt) SetRemoveSummary(key, item string) (*set.Summary, error) {
+	var changed bool
+
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(setBucket).Bucket([]byte(key))
+		if bk == nil {
+			return nil
+		}
+
+		changed = bk.Get([]byte(item)) != nil
+
+		return bk.Delete([]byte(item))
+	}); err != nil {
+		return nil, err
+	}
+
+	return b.summary(key, changed)
+}
+
+// The size is read once the change has committed, since a bucket's stats don't include the uncommitted changes of a
+// write transaction.
+func (b *Bolt) summary(key string, changed bool) (*set.Summary, error) {
+	size, err := b.SetSize(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &set.Summary{Changed: changed, Size: size}, nil
+}
+
+func (b *Bolt) SetRandom(key string, count int) ([]string, error) {
+	items := make([]string, 0)
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		found, err := readSets(tx, []string{key})
+		if err != nil {
+			return err
+		}
+
+		items = found[0].Random(count)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (b *Bolt) SetPop(key string, count int) ([]string, error) {
+	if err := set.CheckPopCount(count); err != nil {
+		return nil, err
+	}
+
+	popped := make([]string, 0)
+
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(setBucket).Bucket([]byte(key))
+		if bk == nil {
+			return nil
+		}
+
+		items, err := members(bk)
+		if err != nil {
+			return err
+		}
+
+		popped = data.NewSet(items...).Random(count)
+
+		for _, item := range popped {
+			if err := bk.Delete([]byte(item)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return popped, nil
+}
+
+func (b *Bolt) SetContains(set, item string) (bool, error) {
+	var contains bool
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if bk := tx.Bucket(setBucket).Bucket([]byte(set)); bk != nil {
+			contains = bk.Get([]byte(item)) != nil
+		}
+		return nil
+	})
+
+	return contains, err
+}
+
+func (b *Bolt) SetSize(set string) (int, error) {
+	var size int
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if bk := tx.Bucket(setBucket).Bucket([]byte(set)); bk != nil {
+			size = bk.Stats().KeyN
+		}
+		return nil
+	})
+
+	return size, err
+}
+
+func (b *Bolt) SetUnion(sets ...string) ([]string, error) {
+	return b.combineSets(set.Union, sets)
+}
+
+func (b *Bolt) SetIntersect(sets ...string) ([]string, error) {
+	return b.combineSets(set.Intersect, sets)
+}
+
+func (b *Bolt) SetDiff(sets ...string) ([]string, error) {
+	return b.combineSets(set.Diff, sets)
+}
+
+func (b *Bolt) SetUnionStore(dest string, sets ...string) (int, error) {
+	return b.storeSets(set.Union, dest, sets)
+}
+
+func (b *Bolt) SetIntersectStore(dest string, sets ...string) (int, error) {
+	return b.storeSets(set.Intersect, dest, sets)
+}
+
+func (b *Bolt) SetDiffStore(dest string, sets ...string) (int, error) {
+	return b.storeSets(set.Diff, dest, sets)
+}
+
+func (b *Bolt) combineSets(op string, sets []string) ([]string, error) {
+	if err := set.CheckSets(sets); err != nil {
+		return nil, err
+	}
+
+	var items []string
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		found, err := readSets(tx, sets)
+		if err != nil {
+			return err
+		}
+
+		items = data.Combine(op, found).Get()
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Combines the sets and replaces the destination's bucket with the result within one transaction.
+func (b *Bolt) storeSets(op, dest string, sets []string) (int, error) {
+	if err := set.CheckSets(sets, dest); err != nil {
+		return 0, err
+	}
+
+	var size int
+
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		found, err := readSets(tx, sets)
+		if err != nil {
+			return err
+		}
+
+		result := data.Combine(op, found)
+		size = result.Len()
+
+		parent := tx.Bucket(setBucket)
+
+		if err := parent.DeleteBucket([]byte(dest)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+
+		if size == 0 {
+			return nil
+		}
+
+		bk, err := parent.CreateBucket([]byte(dest))
+		if err != nil {
+			return err
+		}
+
+		for _, item := range result.Get() {
+			if err := bk.Put([]byte(item), []byte{}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// Returns the sets, with empty ones for those that don't exist.
+func readSets(tx *bolt.Tx, sets []string) ([]*data.Set, error) {
+	found := make([]*data.Set, len(sets))
+
+	for i, name := range sets {
+		found[i] = data.NewSet()
+
+		bk := tx.Bucket(setBucket).Bucket([]byte(name))
+		if bk == nil {
+			continue
+		}
+
+		items, err := members(bk)
+		if err != nil {
+			return nil, err
+		}
+
+		found[i] = data.NewSet(items...)
+	}
+
+	return found, nil
+}
+
+// Lists the names of sets, which are the nested buckets of the set bucket.
+func (b *Bolt) SetList(prefix, cursor string, limit int) ([]string, string, error) {
+	return b.list(setBucket, prefix, cursor, limit, nil)
+}
+
+// Returns a set's members in key order.
+func members(bk *bolt.Bucket) ([]string, error) {
+	items := make([]string, 0)
+
+	if err := bk.ForEach(func(k, _ []byte) error {
+		items = append(items, string(k))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Lists the keys of a service's bucket that begin with prefix and sort after cursor, in key order. If include is
+// supplied, only keys whose values it returns true for are listed.
+func (b *Bolt) list(bucket []byte, prefix, cursor string, limit int, include func(v []byte) bool) ([]string, string, error) {
+	var (
+		page []string
+		next string
+	)
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		page, next = listBucket(tx.Bucket(bucket), prefix, cursor, limit, include)
+		return nil
+	}); err != nil {
+		return nil, "", err
+	}
+
+	return page, next, nil
+}
+
+// Like list, but within a transaction and for any bucket, including a set's.
+func listBucket(bk *bolt.Bucket, prefix, cursor string, limit int, include func(v []byte) bool) ([]string, string) {
+	limit = data.ListLimit(limit)
+
+	keys := make([]string, 0)
+
+	c := bk.Cursor()
+
+	p := []byte(prefix)
+
+	start := p
+	if cursor > prefix {
+		start = []byte(cursor)
+	}
+
+	// One key beyond the limit is read to find out whether there's another page
+	for k, v := c.Seek(start); k != nil && bytes.HasPrefix(k, p) && len(keys) <= limit; k, v = c.Next() {
+		if string(k) == cursor || (include != nil && !include(v)) {
+			continue
+		}
+
+		keys = append(keys, string(k))
+	}
+
+	return data.Truncate(keys, limit)
+}
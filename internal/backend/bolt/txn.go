@@ -0,0 +1,137 @@
+package bolt
+
+import (
+	"github.com/purpledb/purple/internal/data"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/txn"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Txn applies the operations within a single read-write transaction.
+func (b *Bolt) Txn(ops []*txn.Op) ([]*txn.Result, error) {
+	if err := txn.Validate(ops); err != nil {
+		return nil, err
+	}
+
+	return b.commit(nil, ops)
+}
+
+// TxnBegin starts a session whose reads are checked again once it's committed, within the transaction that applies its
+// writes. Bolt runs one read-write transaction at a time, so nothing can change in between.
+func (b *Bolt) TxnBegin() (txn.Session, error) {
+	return txn.NewSession(b.Txn, b.commit), nil
+}
+
+func (b *Bolt) commit(reads []*txn.Read, ops []*txn.Op) ([]*txn.Result, error) {
+	var results []*txn.Result
+
+	if err := b.db.Update(func(tx *bolt.Tx) (err error) {
+		results, err = txn.Commit(&boltTxn{tx: tx}, reads, ops)
+		return
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type boltTxn struct {
+	tx *bolt.Tx
+}
+
+var _ txn.Store = (*boltTxn)(nil)
+
+func (t *boltTxn) KVGet(key string) (*kv.Value, error) {
+	bs := liveKV(t.tx.Bucket(kvBucket), key)
+	if bs == nil {
+		return nil, nil
+	}
+
+	return decodeKVValue(bs)
+}
+
+func (t *boltTxn) KVPut(key string, value *kv.Value, ttl int32) error {
+	bk := t.tx.Bucket(kvBucket)
+
+	return storeKV(bk, key, liveKV(bk, key), value, ttl)
+}
+
+func (t *boltTxn) KVDelete(key string) (bool, error) {
+	bk := t.tx.Bucket(kvBucket)
+
+	existed := liveKV(bk, key) != nil
+
+	return existed, bk.Delete([]byte(key))
+}
+
+func (t *boltTxn) CounterGet(key string) (int64, error) {
+	if bs := t.tx.Bucket(counterBucket).Get([]byte(key)); bs != nil {
+		return data.BytesToInt64(bs), nil
+	}
+
+	return 0, nil
+}
+
+func (t *boltTxn) CounterIncrement(key string, amount int64) (int64, error) {
+	count, err := t.CounterGet(key)
+	if err != nil {
+		return 0, err
+	}
+
+	count += amount
+
+	return count, t.tx.Bucket(counterBucket).Put([]byte(key), data.Int64ToBytes(count))
+}
+
+func (t *boltTxn) FlagGet(key string) (bool, error) {
+	bs := t.tx.Bucket(flagBucket).Get([]byte(key))
+	if bs == nil {
+		return false, nil
+	}
+
+	return data.BoolFromBytes(bs)
+}
+
+func (t *boltTxn) FlagSet(key string, value bool) error {
+	return t.tx.Bucket(flagBucket).Put([]byte(key), data.BoolAsBytes(value))
+}
+
+func (t *boltTxn) SetGet(set string) ([]string, bool, error) {
+	bk := t.tx.Bucket(setBucket).Bucket([]byte(set))
+	if bk == nil {
+		return nil, false, nil
+	}
+
+	items, err := members(bk)
+
+	return items, true, err
+}
+
+func (t *boltTxn) SetAdd(set, item string) ([]string, error) {
+	bk, err := t.tx.Bucket(setBucket).CreateBucketIfNotExists([]byte(set))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bk.Put([]byte(item), []byte{}); err != nil {
+		return nil, err
+	}
+
+	return members(bk)
+}
+
+func (t *boltTxn) SetRemove(set, item string) ([]string, bool, error) {
+	bk := t.tx.Bucket(setBucket).Bucket([]byte(set))
+	if bk == nil {
+		return nil, false, nil
+	}
+
+	if err := bk.Delete([]byte(item)); err != nil {
+		return nil, false, err
+	}
+
+	items, err := members(bk)
+
+	return items, true, err
+}
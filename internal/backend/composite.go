@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/services/cache"
+	"github.com/purpledb/purple/services/counter"
+	"github.com/purpledb/purple/services/flag"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
+	"github.com/purpledb/purple/services/txn"
+)
+
+// Composite routes each service to its own underlying backend, e.g. the cache to memory and KV to disk. Close and
+// Flush fan out to every distinct underlying backend.
+type Composite struct {
+	cache.Cache
+	counter.Counter
+	flag.Flag
+	kv.KV
+	set.Set
+
+	backends []Service
+	// The backend that KV values, counters, flags, and sets share, if they do
+	txn txn.Txn
+}
+
+var _ Service = (*Composite)(nil)
+
+// NewComposite creates a Composite from the backends that each service is routed to. The same backend may be supplied
+// for several services.
+func NewComposite(cacheBk, counterBk, flagBk, kvBk, setBk Service) *Composite {
+	var backends []Service
+
+	seen := make(map[Service]bool)
+
+	for _, bk := range []Service{cacheBk, counterBk, flagBk, kvBk, setBk} {
+		if !seen[bk] {
+			seen[bk] = true
+			backends = append(backends, bk)
+		}
+	}
+
+	c := &Composite{
+		Cache:    cacheBk,
+		Counter:  counterBk,
+		Flag:     flagBk,
+		KV:       kvBk,
+		Set:      setBk,
+		backends: backends,
+	}
+
+	if counterBk == kvBk && flagBk == kvBk && setBk == kvBk {
+		c.txn = kvBk
+	}
+
+	return c
+}
+
+// Transactions can only be applied atomically if the services they span share a backend. The cache isn't part of
+// transactions, so it may be routed elsewhere.
+func (c *Composite) Txn(ops []*txn.Op) ([]*txn.Result, error) {
+	if c.txn == nil {
+		return nil, purple.ErrTxnSpansBackends
+	}
+
+	return c.txn.Txn(ops)
+}
+
+func (c *Composite) TxnBegin() (txn.Session, error) {
+	if c.txn == nil {
+		return nil, purple.ErrTxnSpansBackends
+	}
+
+	return c.txn.TxnBegin()
+}
+
+func (c *Composite) Name() string {
+	return "composite"
+}
+
+// Closes every underlying backend, returning the first error encountered.
+func (c *Composite) Close() error {
+	return c.each(Service.Close)
+}
+
+// Flushes every underlying backend, returning the first error encountered.
+func (c *Composite) Flush() error {
+	return c.each(Service.Flush)
+}
+
+// Applies fn to every underlying backend even if some of them fail.
+func (c *Composite) each(fn func(Service) error) error {
+	var first error
+
+	for _, bk := range c.backends {
+		if err := fn(bk); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/purpledb/purple/services/kv"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	workers    = 16
+	iterations = 200
+)
+
+// Run with the race detector enabled (go test -race) to catch unsynchronized access.
+func TestConcurrentAccess(t *testing.T) {
+	for _, svc := range getServices(t) {
+		testConcurrency(svc, t)
+	}
+}
+
+func testConcurrency(svc Service, t *testing.T) {
+	is := assert.New(t)
+
+	t.Run(fmt.Sprintf("%s/%s", strings.Title(svc.Name()), "Concurrency"), func(t *testing.T) {
+		is.NoError(svc.Flush())
+
+		var wg sync.WaitGroup
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+
+			go func(w int) {
+				defer wg.Done()
+
+				for i := 0; i < iterations; i++ {
+					key := fmt.Sprintf("key-%d", i%10)
+					item := fmt.Sprintf("item-%d-%d", w, i)
+
+					_, err := svc.CounterIncrement("shared-counter", 1)
+					is.NoError(err)
+
+					is.NoError(svc.CacheSet(key, item, 10))
+					_, _ = svc.CacheGet(key)
+
+					is.NoError(svc.FlagSet(key, i%2 == 0))
+					_, err = svc.FlagGet(key)
+					is.NoError(err)
+
+					is.NoError(svc.KVPut(key, &kv.Value{Content: []byte(item)}, 0))
+					_, _ = svc.KVGet(key)
+
+					_, err = svc.SetAdd("shared-set", item)
+					is.NoError(err)
+					_, _ = svc.SetGet("shared-set")
+				}
+			}(w)
+		}
+
+		wg.Wait()
+
+		count, err := svc.CounterGet("shared-counter")
+		is.NoError(err)
+		is.Equal(int64(workers*iterations), count)
+
+		items, err := svc.SetGet("shared-set")
+		is.NoError(err)
+		is.Len(items, workers*iterations)
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+
+			go func(w int) {
+				defer wg.Done()
+
+				for i := 0; i < iterations; i++ {
+					_, err := svc.SetRemove("shared-set", fmt.Sprintf("item-%d-%d", w, i))
+					is.NoError(err)
+				}
+			}(w)
+		}
+
+		wg.Wait()
+
+		items, err = svc.SetGet("shared-set")
+		is.NoError(err)
+		is.Empty(items)
+
+		is.NoError(svc.Flush())
+	})
+}
@@ -1,28 +1,64 @@
 package disk
 
 import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
-	"github.com/purpledb/purple/internal/services/flag"
 	"github.com/purpledb/purple/internal/util"
+	"github.com/purpledb/purple/services/flag"
 
 	"github.com/purpledb/purple"
-	"github.com/purpledb/purple/internal/services/cache"
-	"github.com/purpledb/purple/internal/services/counter"
-	"github.com/purpledb/purple/internal/services/kv"
-	"github.com/purpledb/purple/internal/services/set"
+	"github.com/purpledb/purple/services/cache"
+	"github.com/purpledb/purple/services/counter"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
+	"github.com/purpledb/purple/services/txn"
 
 	"github.com/purpledb/purple/internal/data"
 
 	"github.com/dgraph-io/badger"
 )
 
-const rootDataDir = "tmp/purple"
+// The directory, relative to the configured data path, that holds the Badger DB
+const dataDir = "data"
 
+// Each service's keys are stored under their own prefix, which guarantees key isolation within the single DB
+var (
+	cachePrefix   = []byte("cache:")
+	counterPrefix = []byte("counter:")
+	flagPrefix    = []byte("flag:")
+	kvPrefix      = []byte("kv:")
+	setPrefix     = []byte("set:")
+	// Each set has a header key under setPrefix, which is what's listed, and a key per item under setMemberPrefix
+	setMemberPrefix = []byte("setmember:")
+)
+
+// The value of a set's header key is its number of items, stored as a uvarint after setHeaderMarker. Sets used to
+// store a JSON array of their items there instead, and later just the byte 1, both of which migrateSetHeaders
+// converts when the backend starts.
+const setHeaderMarker = 2
+
+// The number of locks that set mutations are spread over by set name
+const setLockCount = 32
+
+// Disk stores all services in a single Badger DB. Since every service lives in the same DB, one transaction can span
+// several services.
 type Disk struct {
-	cache, counter, flag, kv, set *badger.DB
+	db *badger.DB
+
+	// Set when the backend runs in in-memory mode; the directory is removed on Close
+	tmpDir string
+	// Where Backup writes backup files
+	backupDir string
+	// Every mutation of a set updates the count in its header, so mutations of the same set are serialized here
+	// rather than left to conflict in Badger, transactions included. Only one process can open a DB, so this covers
+	// every writer.
+	setLocks [setLockCount]sync.Mutex
 }
 
 func (d *Disk) Name() string {
@@ -37,96 +73,116 @@ var (
 	_ set.Set         = (*Disk)(nil)
 )
 
-func NewDiskBackend() (*Disk, error) {
-	cacheDb, err := createDb("cache")
-	if err != nil {
+func NewDiskBackend(cfg *purple.DiskConfig) (*Disk, error) {
+	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
-	counterDb, err := createDb("counter")
+	d := &Disk{
+		backupDir: cfg.BackupDir,
+	}
+
+	root := cfg.Path
+
+	// Badger v1 has no native in-memory mode, so data is kept in a temporary directory without syncing writes
+	if cfg.InMemory {
+		dir, err := os.MkdirTemp("", "purple-disk-")
+		if err != nil {
+			return nil, err
+		}
+
+		root, d.tmpDir = dir, dir
+	}
+
+	root, err := filepath.Abs(root)
 	if err != nil {
 		return nil, err
 	}
 
-	flagDb, err := createDb("flag")
+	db, err := createDb(filepath.Join(root, dataDir), cfg)
 	if err != nil {
+		_ = d.Close()
 		return nil, err
 	}
 
-	kvDb, err := createDb("kv")
-	if err != nil {
+	d.db = db
+
+	if err := migrateLegacyDbs(root, db, cfg); err != nil {
+		_ = d.Close()
 		return nil, err
 	}
 
-	setDb, err := createDb("set")
-	if err != nil {
+	if err := migrateSetHeaders(db, cfg); err != nil {
+		_ = d.Close()
 		return nil, err
 	}
 
-	return &Disk{
-		cache:   cacheDb,
-		counter: counterDb,
-		flag:    flagDb,
-		kv:      kvDb,
-		set:     setDb,
-	}, nil
+	return d, nil
 }
 
-func createDb(subDir string) (*badger.DB, error) {
-	here, err := os.Getwd()
-	if err != nil {
-		return nil, err
+func createDb(path string, cfg *purple.DiskConfig) (*badger.DB, error) {
+	if !cfg.ReadOnly {
+		if err := util.MkDirIfNotExists(path); err != nil {
+			return nil, err
+		}
 	}
 
-	path := filepath.Join(here, rootDataDir, subDir)
+	opts := badger.DefaultOptions(path).
+		WithSyncWrites(cfg.SyncWrites && !cfg.InMemory).
+		WithReadOnly(cfg.ReadOnly)
 
-	if err := util.MkDirIfNotExists(path); err != nil {
-		return nil, err
+	if cfg.ValueLogFileSize != 0 {
+		opts = opts.WithValueLogFileSize(cfg.ValueLogFileSize)
 	}
 
-	return badger.Open(badger.DefaultOptions(path))
+	return badger.Open(opts)
 }
 
 // Service methods
 func (d *Disk) Close() error {
-	for _, bk := range []*badger.DB{
-		d.cache, d.counter, d.kv, d.set,
-	} {
-		if err := bk.Close(); err != nil {
+	if d.db != nil {
+		if err := d.db.Close(); err != nil {
 			return err
 		}
 	}
 
+	if d.tmpDir != "" {
+		return os.RemoveAll(d.tmpDir)
+	}
+
 	return nil
 }
 
 func (d *Disk) Flush() error {
-	for _, bk := range []*badger.DB{
-		d.cache, d.counter, d.flag, d.kv, d.set,
-	} {
-		if err := bk.DropAll(); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return d.db.DropAll()
 }
 
 // Generic functions
-func dbRead(db *badger.DB, key []byte) ([]byte, error) {
-	var value []byte
+func prefixed(prefix []byte, key string) []byte {
+	k := make([]byte, 0, len(prefix)+len(key))
+	k = append(k, prefix...)
+	return append(k, key...)
+}
 
-	if err := db.View(func(tx *badger.Txn) error {
-		it, err := tx.Get(key)
-		if err != nil {
-			if err == badger.ErrKeyNotFound {
-				return purple.NotFound(string(key))
-			} else {
-				return err
-			}
-		}
+// The prefix of the keys of a set's items. The set's name is preceded by its length so that no set's prefix is also
+// the prefix of another set's.
+func memberPrefix(set string) []byte {
+	k := make([]byte, 0, len(setMemberPrefix)+binary.MaxVarintLen64+len(set))
+	k = append(k, setMemberPrefix...)
+	k = binary.AppendUvarint(k, uint64(len(set)))
+	return append(k, set...)
+}
+
+func memberKey(set, item string) []byte {
+	return append(memberPrefix(set), item...)
+}
+
+// Reads the value stored under a service's key, returning a not found error that names the unprefixed key.
+func (d *Disk) read(prefix []byte, key string) ([]byte, error) {
+	var value []byte
 
-		val, err := it.ValueCopy(nil)
+	if err := d.db.View(func(tx *badger.Txn) error {
+		val, err := txRead(tx, prefix, key)
 		if err != nil {
 			return err
 		}
@@ -141,37 +197,106 @@ func dbRead(db *badger.DB, key []byte) ([]byte, error) {
 	return value, nil
 }
 
-func dbWrite(db *badger.DB, key, value []byte) error {
-	return db.Update(func(tx *badger.Txn) error {
-		return tx.Set(key, value)
+const (
+	maxUpdateAttempts = 64
+	minUpdateBackoff  = 100 * time.Microsecond
+	maxUpdateBackoff  = 10 * time.Millisecond
+)
+
+// Runs fn inside a single read-write transaction. If another transaction commits a conflicting write in the meantime,
+// Badger rejects the commit with ErrConflict and the whole transaction is retried after a jittered, exponentially
+// growing pause, so read-modify-write operations never lose updates. After maxUpdateAttempts conflicts in a row the
+// write is abandoned with ErrDiskWriteContention.
+func (d *Disk) update(fn func(tx *badger.Txn) error) error {
+	backoff := minUpdateBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := d.db.Update(fn)
+		if err != badger.ErrConflict {
+			return err
+		}
+
+		if attempt == maxUpdateAttempts {
+			return purple.ErrDiskWriteContention
+		}
+
+		time.Sleep(backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+
+		if backoff *= 2; backoff > maxUpdateBackoff {
+			backoff = maxUpdateBackoff
+		}
+	}
+}
+
+func txRead(tx *badger.Txn, prefix []byte, key string) ([]byte, error) {
+	it, err := tx.Get(prefixed(prefix, key))
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return nil, purple.NotFound(key)
+		} else {
+			return nil, err
+		}
+	}
+
+	return it.ValueCopy(nil)
+}
+
+func (d *Disk) write(prefix []byte, key string, value []byte) error {
+	return d.db.Update(func(tx *badger.Txn) error {
+		return tx.Set(prefixed(prefix, key), value)
 	})
 }
 
-func dbDelete(db *badger.DB, key []byte) error {
-	return db.Update(func(tx *badger.Txn) error {
-		return tx.Delete(key)
+func (d *Disk) delete(prefix []byte, key string) error {
+	return d.db.Update(func(tx *badger.Txn) error {
+		return tx.Delete(prefixed(prefix, key))
 	})
 }
 
-func dbSetCacheEntry(db *badger.DB, key, value []byte, ttl time.Duration) error {
-	entry := badger.NewEntry(key, value).WithTTL(ttl)
+// Lists a service's keys that begin with prefix and sort after cursor, in lexicographic order. Badger skips expired
+// keys when iterating, so only live cache items are listed.
+func (d *Disk) list(service []byte, prefix, cursor string, limit int) ([]string, string, error) {
+	limit = data.ListLimit(limit)
 
-	return db.Update(func(tx *badger.Txn) error {
-		return tx.SetEntry(entry)
-	})
+	keys := make([]string, 0)
+
+	if err := d.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefixed(service, prefix)
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		start := opts.Prefix
+		if cursor > prefix {
+			start = prefixed(service, cursor)
+		}
+
+		// One key beyond the limit is read to find out whether there's another page
+		for it.Seek(start); it.Valid() && len(keys) <= limit; it.Next() {
+			key := string(it.Item().Key()[len(service):])
+
+			if key != cursor {
+				keys = append(keys, key)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, "", err
+	}
+
+	page, next := data.Truncate(keys, limit)
+
+	return page, next, nil
 }
 
 // Cache
 func (d *Disk) CacheGet(key string) (string, error) {
-	k := []byte(key)
-
-	val, err := dbRead(d.cache, k)
+	val, err := d.read(cachePrefix, key)
 	if err != nil {
-		if purple.IsNotFound(err) {
-			return "", purple.NotFound(key)
-		} else {
-			return "", err
-		}
+		return "", err
 	}
 
 	return string(val), nil
@@ -186,18 +311,51 @@ func (d *Disk) CacheSet(key string, value string, ttl int32) error {
 		return purple.ErrNoValue
 	}
 
-	k, v := []byte(key), []byte(value)
-
 	t := time.Duration(ttl) * time.Second
 
-	return dbSetCacheEntry(d.cache, k, v, t)
+	entry := badger.NewEntry(prefixed(cachePrefix, key), []byte(value)).WithTTL(t)
+
+	return d.db.Update(func(tx *badger.Txn) error {
+		return tx.SetEntry(entry)
+	})
+}
+
+// Returns the number of seconds until a cache item expires, rounded up, or zero if it never expires.
+func (d *Disk) CacheTTL(key string) (int32, error) {
+	var ttl int32
+
+	if err := d.db.View(func(tx *badger.Txn) error {
+		it, err := tx.Get(prefixed(cachePrefix, key))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return purple.NotFound(key)
+			} else {
+				return err
+			}
+		}
+
+		if expiresAt := it.ExpiresAt(); expiresAt != 0 {
+			ttl = int32(int64(expiresAt) - time.Now().Unix())
+			if ttl < 1 {
+				ttl = 1
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return ttl, nil
+}
+
+func (d *Disk) CacheList(prefix, cursor string, limit int) ([]string, string, error) {
+	return d.list(cachePrefix, prefix, cursor, limit)
 }
 
 // Counter
 func (d *Disk) CounterGet(key string) (int64, error) {
-	k := []byte(key)
-
-	val, err := dbRead(d.counter, k)
+	val, err := d.read(counterPrefix, key)
 	if err != nil {
 		if purple.IsNotFound(err) {
 			return 0, nil
@@ -210,41 +368,35 @@ func (d *Disk) CounterGet(key string) (int64, error) {
 }
 
 func (d *Disk) CounterIncrement(key string, increment int64) (int64, error) {
-	k := []byte(key)
-
-	val, err := dbRead(d.counter, k)
-	if err != nil {
-		if purple.IsNotFound(err) {
-			v := data.Int64ToBytes(increment)
+	var count int64
 
-			if err := dbWrite(d.counter, k, v); err != nil {
-				return 0, err
-			}
+	if err := d.update(func(tx *badger.Txn) error {
+		count = increment
 
-			return d.CounterGet(key)
-		} else {
-			return 0, err
+		val, err := txRead(tx, counterPrefix, key)
+		if err != nil && !purple.IsNotFound(err) {
+			return err
 		}
-	}
-
-	count := data.BytesToInt64(val)
 
-	count += increment
-
-	newVal := data.Int64ToBytes(count)
+		if err == nil {
+			count += data.BytesToInt64(val)
+		}
 
-	if err := dbWrite(d.counter, k, newVal); err != nil {
+		return tx.Set(prefixed(counterPrefix, key), data.Int64ToBytes(count))
+	}); err != nil {
 		return 0, err
 	}
 
-	return d.CounterGet(key)
+	return count, nil
+}
+
+func (d *Disk) CounterList(prefix, cursor string, limit int) ([]string, string, error) {
+	return d.list(counterPrefix, prefix, cursor, limit)
 }
 
 // Flag
 func (d *Disk) FlagGet(key string) (bool, error) {
-	k := []byte(key)
-
-	val, err := dbRead(d.flag, k)
+	val, err := d.read(flagPrefix, key)
 	if err != nil {
 		if purple.IsNotFound(err) {
 			return false, nil
@@ -257,124 +409,829 @@ func (d *Disk) FlagGet(key string) (bool, error) {
 }
 
 func (d *Disk) FlagSet(key string, value bool) error {
-	k := []byte(key)
-
-	val := data.BoolAsBytes(value)
+	return d.write(flagPrefix, key, data.BoolAsBytes(value))
+}
 
-	return dbWrite(d.flag, k, val)
+func (d *Disk) FlagList(prefix, cursor string, limit int) ([]string, string, error) {
+	return d.list(flagPrefix, prefix, cursor, limit)
 }
 
-// KV
+// KV values are versioned by Badger itself: a value's version is the timestamp of the transaction that wrote it, which
+// grows with every commit to the DB. The rest of the value is stored in the kv.Value encoding.
 func (d *Disk) KVGet(key string) (*kv.Value, error) {
-	k := []byte(key)
+	var value *kv.Value
 
-	val, err := dbRead(d.kv, k)
-	if err != nil {
-		if err == badger.ErrKeyNotFound {
-			return nil, purple.NotFound(key)
-		} else {
-			return nil, err
+	if err := d.db.View(func(tx *badger.Txn) error {
+		it, err := tx.Get(prefixed(kvPrefix, key))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return purple.NotFound(key)
+			} else {
+				return err
+			}
 		}
+
+		value, err = decodeKV(it)
+		return err
+	}); err != nil {
+		return nil, err
 	}
 
-	return &kv.Value{
-		Content: val,
-	}, nil
+	return value, nil
+}
+
+func (d *Disk) KVPut(key string, value *kv.Value, ttl int32) error {
+	return d.putKV(key, value, ttl, func(*badger.Item) bool { return true })
 }
 
-func (d *Disk) KVPut(key string, value *kv.Value) error {
-	k := []byte(key)
+func (d *Disk) KVPutIfVersion(key string, value *kv.Value, version uint64, ttl int32) error {
+	return d.putKV(key, value, ttl, hasVersion(version))
+}
 
-	return dbWrite(d.kv, k, value.Content)
+func (d *Disk) KVPutIfAbsent(key string, value *kv.Value, ttl int32) error {
+	return d.putKV(key, value, ttl, func(it *badger.Item) bool { return it == nil })
 }
 
 func (d *Disk) KVDelete(key string) error {
-	k := []byte(key)
-	return dbDelete(d.kv, k)
+	return d.delete(kvPrefix, key)
 }
 
-// Set
-func (d *Disk) SetGet(key string) ([]string, error) {
-	k := []byte(key)
+func (d *Disk) KVDeleteIfVersion(key string, version uint64) error {
+	return d.kvIf(key, hasVersion(version), func(tx *badger.Txn, k []byte, _ *badger.Item) error {
+		return tx.Delete(k)
+	})
+}
 
-	val, err := dbRead(d.set, k)
-	if err != nil {
-		return nil, err
-	}
+func (d *Disk) KVTTL(key string) (int32, error) {
+	var ttl int32
 
-	s, err := data.BytesToSet(val)
-	if err != nil {
-		return nil, err
+	if err := d.db.View(func(tx *badger.Txn) error {
+		it, err := tx.Get(prefixed(kvPrefix, key))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return purple.NotFound(key)
+			} else {
+				return err
+			}
+		}
+
+		ttl = kv.Remaining(expiresAt(it), time.Now())
+
+		return nil
+	}); err != nil {
+		return 0, err
 	}
 
-	return s.Get(), nil
+	return ttl, nil
 }
 
-func (d *Disk) SetAdd(key, item string) ([]string, error) {
-	k := []byte(key)
+func (d *Disk) KVExpire(key string, ttl int32) error {
+	if err := kv.CheckExpireTTL(ttl); err != nil {
+		return err
+	}
 
-	val, err := dbRead(d.set, k)
-	if err != nil {
-		if purple.IsNotFound(err) {
-			s := data.NewSet(item)
-			value, err := s.AsBytes()
-			if err != nil {
-				return nil, err
-			}
+	return d.expireKV(key, ttl)
+}
 
-			if err := dbWrite(d.set, k, value); err != nil {
-				return nil, err
-			}
+func (d *Disk) KVPersist(key string) error {
+	return d.expireKV(key, 0)
+}
 
-			return s.Get(), nil
-		} else {
-			return nil, err
+// Badger keeps expiry per entry, so the value is written again with the new TTL, which also gives it a new version.
+func (d *Disk) expireKV(key string, ttl int32) error {
+	return d.kvIf(key, func(*badger.Item) bool { return true }, func(tx *badger.Txn, k []byte, it *badger.Item) error {
+		if it == nil {
+			return purple.NotFound(key)
 		}
-	}
 
-	s, err := data.BytesToSet(val)
-	if err != nil {
-		return nil, err
-	}
+		bs, err := it.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		return tx.SetEntry(kvEntry(k, bs, ttl))
+	})
+}
 
-	s.Add(item)
+func kvEntry(k, bs []byte, ttl int32) *badger.Entry {
+	e := badger.NewEntry(k, bs)
 
-	value, err := s.AsBytes()
-	if err != nil {
-		return nil, err
+	if ttl > 0 {
+		e = e.WithTTL(time.Duration(ttl) * time.Second)
 	}
 
-	if err := dbWrite(d.set, k, value); err != nil {
-		return nil, err
+	return e
+}
+
+// Badger stores expiry in Unix seconds, with 0 for entries that don't expire.
+func expiresAt(it *badger.Item) time.Time {
+	if it.ExpiresAt() == 0 {
+		return time.Time{}
 	}
 
-	return s.Get(), nil
+	return time.Unix(int64(it.ExpiresAt()), 0)
 }
 
-func (d *Disk) SetRemove(key, item string) ([]string, error) {
-	k := []byte(key)
+func hasVersion(version uint64) func(it *badger.Item) bool {
+	return func(it *badger.Item) bool {
+		return it != nil && it.Version() == version
+	}
+}
 
-	val, err := dbRead(d.set, k)
+func decodeKV(it *badger.Item) (*kv.Value, error) {
+	bs, err := it.ValueCopy(nil)
 	if err != nil {
 		return nil, err
 	}
 
-	s, err := data.BytesToSet(val)
+	value, err := kv.BytesToValue(bs)
 	if err != nil {
 		return nil, err
 	}
 
-	s.Remove(item)
+	value.Version = it.Version()
 
-	value, err := s.AsBytes()
-	if err != nil {
+	return value, nil
+}
+
+// Writes the value with its timestamps and TTL if check accepts the current item. The current value is read in the
+// same transaction so that its creation time carries over.
+func (d *Disk) putKV(key string, value *kv.Value, ttl int32, check func(it *badger.Item) bool) error {
+	if err := kv.CheckTTL(ttl); err != nil {
+		return err
+	}
+
+	return d.kvIf(key, check, func(tx *badger.Txn, k []byte, it *badger.Item) error {
+		var current *kv.Value
+
+		if it != nil {
+			var err error
+
+			if current, err = decodeKV(it); err != nil {
+				return err
+			}
+		}
+
+		bs, err := value.Stamped(current, time.Now()).AsBytes()
+		if err != nil {
+			return err
+		}
+
+		return tx.SetEntry(kvEntry(k, bs, ttl))
+	})
+}
+
+// Runs write if check accepts the item stored under the KV key, which is nil if the key doesn't exist, and fails with a
+// conflict error otherwise. Since the check and the write share a transaction, a concurrent write to the key makes
+// Badger retry the transaction, which then checks the new version.
+func (d *Disk) kvIf(key string, check func(it *badger.Item) bool, write func(tx *badger.Txn, k []byte, it *badger.Item) error) error {
+	k := prefixed(kvPrefix, key)
+
+	return d.update(func(tx *badger.Txn) error {
+		it, err := tx.Get(k)
+		if err != nil {
+			if err != badger.ErrKeyNotFound {
+				return err
+			}
+
+			it = nil
+		}
+
+		if !check(it) {
+			return purple.Conflict(key)
+		}
+
+		return write(tx, k, it)
+	})
+}
+
+func (d *Disk) KVList(prefix, cursor string, limit int) ([]string, string, error) {
+	return d.list(kvPrefix, prefix, cursor, limit)
+}
+
+// The batch operations each run in a single transaction, so a batch is applied atomically.
+func (d *Disk) KVGetMany(keys []string) ([]*kv.Value, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
 		return nil, err
 	}
 
-	if err := dbWrite(d.set, k, value); err != nil {
+	values := make([]*kv.Value, len(keys))
+
+	if err := d.db.View(func(tx *badger.Txn) error {
+		for i, key := range keys {
+			it, err := txGetKV(tx, key)
+			if err != nil {
+				return err
+			}
+
+			if it != nil {
+				if values[i], err = decodeKV(it); err != nil {
+					return err
+				}
+			}
+		}
 
+		return nil
+	}); err != nil {
 		return nil, err
 	}
 
-	return s.Get(), nil
+	return values, nil
+}
+
+func (d *Disk) KVPutMany(entries []*kv.Entry) error {
+	if err := kv.CheckEntries(entries); err != nil {
+		return err
+	}
+
+	return d.update(func(tx *badger.Txn) error {
+		now := time.Now()
+
+		for _, e := range entries {
+			it, err := txGetKV(tx, e.Key)
+			if err != nil {
+				return err
+			}
+
+			var current *kv.Value
+
+			if it != nil {
+				if current, err = decodeKV(it); err != nil {
+					return err
+				}
+			}
+
+			bs, err := e.Value.Stamped(current, now).AsBytes()
+			if err != nil {
+				return err
+			}
+
+			if err := tx.SetEntry(kvEntry(prefixed(kvPrefix, e.Key), bs, e.TTL)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (d *Disk) KVDeleteMany(keys []string) ([]bool, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
+		return nil, err
+	}
+
+	var existed []bool
+
+	if err := d.update(func(tx *badger.Txn) error {
+		// Reset on every attempt, since a conflict retries the whole transaction
+		existed = make([]bool, len(keys))
+
+		for i, key := range keys {
+			it, err := txGetKV(tx, key)
+			if err != nil {
+				return err
+			}
+
+			if it == nil {
+				continue
+			}
+
+			existed[i] = true
+
+			if err := tx.Delete(prefixed(kvPrefix, key)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return existed, nil
+}
+
+// Returns the item stored under the KV key, or nil if the key doesn't exist. Within a read-write transaction, the
+// item reflects the transaction's own writes.
+func txGetKV(tx *badger.Txn, key string) (*badger.Item, error) {
+	it, err := tx.Get(prefixed(kvPrefix, key))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+
+	return it, err
+}
+
+// Set
+func (d *Disk) SetGet(key string) ([]string, error) {
+	var items []string
+
+	if err := d.db.View(func(tx *badger.Txn) error {
+		s, err := txReadSet(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if s == nil {
+			return purple.NotFound(key)
+		}
+
+		items = s.Get()
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// The items are read once the write has committed, so as with Redis they may include other changes made in the
+// meantime.
+func (d *Disk) SetAdd(key, item string) ([]string, error) {
+	defer d.lockSet(key)()
+
+	if err := d.update(func(tx *badger.Txn) error {
+		_, err := txAddMember(tx, key, item)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return d.SetGet(key)
+}
+
+func (d *Disk) SetRemove(key, item string) ([]string, error) {
+	defer d.lockSet(key)()
+
+	if err := d.update(func(tx *badger.Txn) error {
+		exists, err := txHasSet(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return purple.NotFound(key)
+		}
+
+		_, err = txRemoveMember(tx, key, item)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return d.SetGet(key)
+}
+
+// Items are read straight from their keys, one page at a time.
+func (d *Disk) SetGetPage(key, cursor string, limit int) ([]string, string, error) {
+	return d.list(memberPrefix(key), "", cursor, limit)
+}
+
+func (d *Disk) SetAddSummary(key, item string) (*set.Summary, error) {
+	return d.summarize(key, func(tx *badger.Txn) (bool, error) {
+		return txAddMember(tx, key, item)
+	})
+}
+
+func (d *Disk) SetRemoveSummary(key, item string) (*set.Summary, error) {
+	return d.summarize(key, func(tx *badger.Txn) (bool, error) {
+		return txRemoveMember(tx, key, item)
+	})
+}
+
+// Applies the change and reads the size from the set's header within one transaction.
+func (d *Disk) summarize(key string, change func(tx *badger.Txn) (bool, error)) (*set.Summary, error) {
+	defer d.lockSet(key)()
+
+	summary := &set.Summary{}
+
+	if err := d.update(func(tx *badger.Txn) (err error) {
+		if summary.Changed, err = change(tx); err != nil {
+			return err
+		}
+
+		summary.Size, _, err = txSetSize(tx, key)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (d *Disk) SetRandom(key string, count int) ([]string, error) {
+	var items []string
+
+	if err := d.db.View(func(tx *badger.Txn) (err error) {
+		items, err = txRandomMembers(tx, key, count)
+		return
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (d *Disk) SetPop(key string, count int) ([]string, error) {
+	if err := set.CheckPopCount(count); err != nil {
+		return nil, err
+	}
+
+	defer d.lockSet(key)()
+
+	var popped []string
+
+	if err := d.update(func(tx *badger.Txn) (err error) {
+		if popped, err = txRandomMembers(tx, key, count); err != nil {
+			return err
+		}
+
+		for _, item := range popped {
+			if _, err := txRemoveMember(tx, key, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return popped, nil
+}
+
+func (d *Disk) SetContains(key, item string) (bool, error) {
+	var contains bool
+
+	err := d.db.View(func(tx *badger.Txn) (err error) {
+		contains, err = txHasMember(tx, key, item)
+		return
+	})
+
+	return contains, err
+}
+
+// The size is read from the set's header.
+func (d *Disk) SetSize(key string) (int, error) {
+	var size int
+
+	err := d.db.View(func(tx *badger.Txn) (err error) {
+		size, _, err = txSetSize(tx, key)
+		return
+	})
+
+	return size, err
+}
+
+func (d *Disk) SetUnion(keys ...string) ([]string, error) {
+	return d.combineSets(set.Union, keys)
+}
+
+func (d *Disk) SetIntersect(keys ...string) ([]string, error) {
+	return d.combineSets(set.Intersect, keys)
+}
+
+func (d *Disk) SetDiff(keys ...string) ([]string, error) {
+	return d.combineSets(set.Diff, keys)
+}
+
+func (d *Disk) SetUnionStore(dest string, keys ...string) (int, error) {
+	return d.storeSets(set.Union, dest, keys)
+}
+
+func (d *Disk) SetIntersectStore(dest string, keys ...string) (int, error) {
+	return d.storeSets(set.Intersect, dest, keys)
+}
+
+func (d *Disk) SetDiffStore(dest string, keys ...string) (int, error) {
+	return d.storeSets(set.Diff, dest, keys)
+}
+
+func (d *Disk) combineSets(op string, keys []string) ([]string, error) {
+	if err := set.CheckSets(keys); err != nil {
+		return nil, err
+	}
+
+	var items []string
+
+	if err := d.db.View(func(tx *badger.Txn) error {
+		sets, err := txReadSets(tx, keys)
+		if err != nil {
+			return err
+		}
+
+		items = data.Combine(op, sets).Get()
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Combines the sets and stores the result within one transaction.
+func (d *Disk) storeSets(op, dest string, keys []string) (int, error) {
+	if err := set.CheckSets(keys, dest); err != nil {
+		return 0, err
+	}
+
+	defer d.lockSet(dest)()
+
+	var size int
+
+	if err := d.update(func(tx *badger.Txn) error {
+		sets, err := txReadSets(tx, keys)
+		if err != nil {
+			return err
+		}
+
+		result := data.Combine(op, sets)
+		size = result.Len()
+
+		return txWriteSet(tx, dest, result)
+	}); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func setHeader(size int) []byte {
+	return binary.AppendUvarint([]byte{setHeaderMarker}, uint64(size))
+}
+
+// Returns the size stored in a set's header, or false if the header is in one of the legacy formats.
+func parseSetHeader(val []byte) (int, bool) {
+	if len(val) < 2 || val[0] != setHeaderMarker {
+		return 0, false
+	}
+
+	size, n := binary.Uvarint(val[1:])
+	if n <= 0 {
+		return 0, false
+	}
+
+	return int(size), true
+}
+
+// Returns the set's size and whether its header key exists.
+func txSetSize(tx *badger.Txn, key string) (int, bool, error) {
+	it, err := tx.Get(prefixed(setPrefix, key))
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return 0, false, nil
+		}
+
+		return 0, false, err
+	}
+
+	val, err := it.ValueCopy(nil)
+	if err != nil {
+		return 0, false, err
+	}
+
+	size, ok := parseSetHeader(val)
+	if !ok {
+		return 0, false, purple.ErrInvalidSetHeader
+	}
+
+	return size, true, nil
+}
+
+// Locks the set against other mutations and returns the function that unlocks it.
+func (d *Disk) lockSet(key string) func() {
+	mu := &d.setLocks[setLockIndex(key)]
+	mu.Lock()
+
+	return mu.Unlock
+}
+
+// Like lockSet for every set that a transaction's operations add to or remove from. The locks are taken in lock order
+// to rule out deadlocks between transactions.
+func (d *Disk) lockTxnSets(ops []*txn.Op) func() {
+	var picked [setLockCount]bool
+
+	for _, op := range ops {
+		if op.Kind == txn.SetAdd || op.Kind == txn.SetRemove {
+			picked[setLockIndex(op.Key)] = true
+		}
+	}
+
+	for i := range picked {
+		if picked[i] {
+			d.setLocks[i].Lock()
+		}
+	}
+
+	return func() {
+		for i := range picked {
+			if picked[i] {
+				d.setLocks[i].Unlock()
+			}
+		}
+	}
+}
+
+func setLockIndex(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return h.Sum32() % setLockCount
+}
+
+// Returns whether the set's header key exists.
+func txHasSet(tx *badger.Txn, key string) (bool, error) {
+	_, exists, err := txSetSize(tx, key)
+
+	return exists, err
+}
+
+func txHasMember(tx *badger.Txn, key, item string) (bool, error) {
+	_, err := tx.Get(memberKey(key, item))
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Calls fn with each of the set's items in lexicographic order. Only the member keys are read, since their values
+// are empty.
+func txEachMember(tx *badger.Txn, key string, fn func(item string)) {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Prefix = memberPrefix(key)
+
+	it := tx.NewIterator(opts)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		fn(string(it.Item().Key()[len(opts.Prefix):]))
+	}
+}
+
+// Returns nil if the set doesn't exist.
+func txReadSet(tx *badger.Txn, key string) (*data.Set, error) {
+	exists, err := txHasSet(tx, key)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	s := data.NewSet()
+	txEachMember(tx, key, s.Add)
+
+	return s, nil
+}
+
+// Returns the sets, with empty ones for those that don't exist.
+func txReadSets(tx *badger.Txn, keys []string) ([]*data.Set, error) {
+	sets := make([]*data.Set, len(keys))
+
+	for i, key := range keys {
+		s, err := txReadSet(tx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if s == nil {
+			s = data.NewSet()
+		}
+
+		sets[i] = s
+	}
+
+	return sets, nil
+}
+
+// Adds item to the set, creating the set if it doesn't exist yet, and returns whether it wasn't in the set already.
+// The count in the set's header is updated along with the item's key, so concurrent changes to the same set conflict
+// on the header and callers need to retry them (see update).
+func txAddMember(tx *badger.Txn, key, item string) (bool, error) {
+	size, exists, err := txSetSize(tx, key)
+	if err != nil {
+		return false, err
+	}
+
+	if exists {
+		if has, err := txHasMember(tx, key, item); err != nil || has {
+			return false, err
+		}
+	}
+
+	if err := tx.Set(memberKey(key, item), nil); err != nil {
+		return false, err
+	}
+
+	return true, tx.Set(prefixed(setPrefix, key), setHeader(size+1))
+}
+
+// Removes item from the set and returns whether it was in it. Like txAddMember, it updates the count in the header.
+// A set whose last item is removed is kept, empty.
+func txRemoveMember(tx *badger.Txn, key, item string) (bool, error) {
+	has, err := txHasMember(tx, key, item)
+	if err != nil || !has {
+		return false, err
+	}
+
+	size, _, err := txSetSize(tx, key)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Delete(memberKey(key, item)); err != nil {
+		return false, err
+	}
+
+	return true, tx.Set(prefixed(setPrefix, key), setHeader(size-1))
+}
+
+// Picks items of the set at random as data.Set.Random does, without holding all of the set's items in memory: the
+// positions of the picked items are chosen using the size in the set's header, and the items at those positions are
+// then read in a single pass over the set's keys. Sets that don't exist have no items to pick.
+func txRandomMembers(tx *badger.Txn, key string, count int) ([]string, error) {
+	size, _, err := txSetSize(tx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// How many times the item at each position is picked
+	picks := make(map[int]int)
+
+	if count < 0 {
+		for i := 0; i < -count && size > 0; i++ {
+			picks[rand.Intn(size)]++
+		}
+	} else {
+		if count > size {
+			count = size
+		}
+
+		// Floyd's algorithm picks count distinct positions with count random numbers
+		for j := size - count; j < size; j++ {
+			if p := rand.Intn(j + 1); picks[p] == 0 {
+				picks[p] = 1
+			} else {
+				picks[j] = 1
+			}
+		}
+	}
+
+	picked := make([]string, 0)
+	pos := 0
+
+	txEachMember(tx, key, func(item string) {
+		for n := picks[pos]; n > 0; n-- {
+			picked = append(picked, item)
+		}
+
+		pos++
+	})
+
+	// The items were read in order
+	rand.Shuffle(len(picked), func(i, j int) {
+		picked[i], picked[j] = picked[j], picked[i]
+	})
+
+	return picked, nil
+}
+
+// Replaces the set's items with those of s, only writing the member keys that change. An empty s deletes the set.
+func txWriteSet(tx *badger.Txn, key string, s *data.Set) error {
+	current, err := txReadSet(tx, key)
+	if err != nil {
+		return err
+	}
+
+	if current == nil {
+		current = data.NewSet()
+	}
+
+	for _, item := range current.Diff(s).Get() {
+		if err := tx.Delete(memberKey(key, item)); err != nil {
+			return err
+		}
+	}
+
+	if s.Len() == 0 {
+		return tx.Delete(prefixed(setPrefix, key))
+	}
+
+	if err := tx.Set(prefixed(setPrefix, key), setHeader(s.Len())); err != nil {
+		return err
+	}
+
+	for _, item := range s.Diff(current).Get() {
+		if err := tx.Set(memberKey(key, item), nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Disk) SetList(prefix, cursor string, limit int) ([]string, string, error) {
+	return d.list(setPrefix, prefix, cursor, limit)
 }
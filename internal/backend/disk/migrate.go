@@ -0,0 +1,207 @@
+package disk
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/data"
+	"github.com/purpledb/purple/services/kv"
+
+	"github.com/dgraph-io/badger"
+)
+
+// Before all services shared one DB, each service had a Badger DB of its own in a subdirectory of the data path
+var legacyDbs = []struct {
+	subDir string
+	prefix []byte
+	// Converts a legacy value into the current format, if it has changed since
+	convert func(val []byte) ([]byte, error)
+}{
+	{"cache", cachePrefix, nil},
+	{"counter", counterPrefix, nil},
+	{"flag", flagPrefix, nil},
+	{"kv", kvPrefix, convertLegacyKV},
+	{"set", setPrefix, nil},
+}
+
+// Legacy KV values are just the content, so they're given timestamps as of the migration
+func convertLegacyKV(val []byte) ([]byte, error) {
+	return (&kv.Value{Content: val}).Stamped(nil, time.Now()).AsBytes()
+}
+
+// Moves the data from any per-service DBs under root into db, adding each service's key prefix, and then removes the
+// legacy DBs. Each legacy DB is only removed once all of its data has been written, so an interrupted migration is
+// simply resumed on the next start.
+func migrateLegacyDbs(root string, db *badger.DB, cfg *purple.DiskConfig) error {
+	for _, legacy := range legacyDbs {
+		path := filepath.Join(root, legacy.subDir)
+
+		if _, err := os.Stat(filepath.Join(path, badger.ManifestFilename)); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return err
+		}
+
+		if cfg.ReadOnly {
+			return purple.ErrDiskMigrationReadOnly
+		}
+
+		if err := migrateLegacyDb(path, legacy.prefix, legacy.convert, db); err != nil {
+			return err
+		}
+
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func migrateLegacyDb(path string, prefix []byte, convert func([]byte) ([]byte, error), db *badger.DB) error {
+	legacy, err := badger.Open(badger.DefaultOptions(path))
+	if err != nil {
+		return err
+	}
+	defer legacy.Close()
+
+	wb := db.NewWriteBatch()
+
+	if err := legacy.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			if convert != nil {
+				if val, err = convert(val); err != nil {
+					return err
+				}
+			}
+
+			// Cache entries keep their original expiry
+			entry := badger.NewEntry(prefixed(prefix, string(item.Key())), val)
+			entry.ExpiresAt = item.ExpiresAt()
+
+			if err := wb.SetEntry(entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		wb.Cancel()
+		return err
+	}
+
+	if err := wb.Flush(); err != nil {
+		return err
+	}
+
+	return db.Sync()
+}
+
+// Sets briefly had a header holding just this byte, without their number of items
+var legacySetHeader = []byte{1}
+
+// Brings set headers in a legacy format up to date: sets that are still stored as a JSON array of their items under
+// their header key are converted into a key per item, and headers without a count of the set's items are given one.
+// All of the items are written before any of the headers is replaced, so an interrupted migration is simply resumed on
+// the next start.
+func migrateSetHeaders(db *badger.DB, cfg *purple.DiskConfig) error {
+	// The sizes of the sets whose headers need to be replaced
+	migrated := make(map[string]int)
+
+	wb := db.NewWriteBatch()
+
+	if err := db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = setPrefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := parseSetHeader(val); ok {
+				continue
+			}
+
+			if cfg.ReadOnly {
+				return purple.ErrDiskMigrationReadOnly
+			}
+
+			key := string(item.Key()[len(setPrefix):])
+
+			if bytes.Equal(val, legacySetHeader) {
+				size := 0
+				txEachMember(tx, key, func(string) {
+					size++
+				})
+
+				migrated[key] = size
+
+				continue
+			}
+
+			s, err := data.BytesToSet(val)
+			if err != nil {
+				return err
+			}
+
+			for _, member := range s.Get() {
+				if err := wb.Set(memberKey(key, member), nil); err != nil {
+					return err
+				}
+			}
+
+			migrated[key] = s.Len()
+		}
+
+		return nil
+	}); err != nil {
+		wb.Cancel()
+		return err
+	}
+
+	if len(migrated) == 0 {
+		wb.Cancel()
+		return nil
+	}
+
+	if err := wb.Flush(); err != nil {
+		return err
+	}
+
+	headers := db.NewWriteBatch()
+
+	for key, size := range migrated {
+		if err := headers.Set(prefixed(setPrefix, key), setHeader(size)); err != nil {
+			headers.Cancel()
+			return err
+		}
+	}
+
+	if err := headers.Flush(); err != nil {
+		return err
+	}
+
+	return db.Sync()
+}
@@ -0,0 +1,252 @@
+package disk
+
+import (
+	"time"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/data"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/txn"
+)
+
+// The longest a session's Badger transaction is kept open. An open transaction keeps Badger from discarding the
+// versions of keys written after it began, so sessions that are still in use past this age are ended all the same.
+const maxSessionAge = 5 * time.Minute
+
+// Txn applies the operations within a single Badger transaction, which is retried as a whole on conflict.
+func (d *Disk) Txn(ops []*txn.Op) ([]*txn.Result, error) {
+	if err := txn.Validate(ops); err != nil {
+		return nil, err
+	}
+
+	defer d.lockTxnSets(ops)()
+
+	var results []*txn.Result
+
+	if err := d.update(func(tx *badger.Txn) (err error) {
+		results, err = txn.Apply(&diskTxn{tx: tx, now: time.Now()}, ops)
+		return
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// TxnBegin starts a session that reads and commits within a single Badger transaction, so it's Badger that detects
+// conflicts: the commit fails if another transaction wrote any of the keys the session read after the session began,
+// even if the key has since been changed back. That includes the keys that the committed operations read, such as a
+// counter that's incremented.
+func (d *Disk) TxnBegin() (txn.Session, error) {
+	return &diskSession{
+		d:     d,
+		tx:    d.db.NewTransaction(true),
+		began: time.Now(),
+	}, nil
+}
+
+type diskSession struct {
+	d     *Disk
+	tx    *badger.Txn
+	began time.Time
+	// Kept to report which key conflicted
+	reads []*txn.Read
+}
+
+func (s *diskSession) Read(ops []*txn.Op) ([]*txn.Result, error) {
+	if err := s.checkAge(); err != nil {
+		return nil, err
+	}
+
+	results, err := txn.Apply(&diskTxn{tx: s.tx, now: time.Now()}, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		s.reads = append(s.reads, &txn.Read{Op: op, Result: results[i]})
+	}
+
+	return results, nil
+}
+
+func (s *diskSession) Commit(ops []*txn.Op) ([]*txn.Result, error) {
+	defer s.tx.Discard()
+
+	if err := s.checkAge(); err != nil {
+		return nil, err
+	}
+
+	defer s.d.lockTxnSets(ops)()
+
+	results, err := txn.Apply(&diskTxn{tx: s.tx, now: time.Now()}, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tx.Commit(); err != nil {
+		if err == badger.ErrConflict {
+			return nil, s.d.conflict(s.reads, ops)
+		}
+
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *diskSession) Abort() {
+	s.tx.Discard()
+}
+
+// Ends sessions that are too old, which are then reported as expired.
+func (s *diskSession) checkAge() error {
+	if time.Since(s.began) > maxSessionAge {
+		s.tx.Discard()
+		return purple.ErrTxnSessionNotFound
+	}
+
+	return nil
+}
+
+// Badger doesn't say which key conflicted, so the session's reads are made again to find one that has changed. If
+// none has, because a key was changed back or because the conflict is on a key that only the committed operations
+// read, the first key involved is reported.
+func (d *Disk) conflict(reads []*txn.Read, ops []*txn.Op) error {
+	var current []*txn.Result
+
+	if err := d.db.View(func(tx *badger.Txn) (err error) {
+		current, err = txn.Apply(&diskTxn{tx: tx, now: time.Now()}, txn.ReadOps(reads))
+		return
+	}); err != nil {
+		return err
+	}
+
+	if err := txn.Check(reads, current); err != nil {
+		return err
+	}
+
+	if len(reads) > 0 {
+		return purple.Conflict(reads[0].Op.Key)
+	}
+
+	return purple.Conflict(ops[0].Key)
+}
+
+type diskTxn struct {
+	tx  *badger.Txn
+	now time.Time
+}
+
+var _ txn.Store = (*diskTxn)(nil)
+
+func (t *diskTxn) KVGet(key string) (*kv.Value, error) {
+	it, err := txGetKV(t.tx, key)
+	if err != nil || it == nil {
+		return nil, err
+	}
+
+	return decodeKV(it)
+}
+
+func (t *diskTxn) KVPut(key string, value *kv.Value, ttl int32) error {
+	current, err := t.KVGet(key)
+	if err != nil {
+		return err
+	}
+
+	bs, err := value.Stamped(current, t.now).AsBytes()
+	if err != nil {
+		return err
+	}
+
+	return t.tx.SetEntry(kvEntry(prefixed(kvPrefix, key), bs, ttl))
+}
+
+func (t *diskTxn) KVDelete(key string) (bool, error) {
+	it, err := txGetKV(t.tx, key)
+	if err != nil || it == nil {
+		return false, err
+	}
+
+	return true, t.tx.Delete(prefixed(kvPrefix, key))
+}
+
+func (t *diskTxn) CounterGet(key string) (int64, error) {
+	val, err := txRead(t.tx, counterPrefix, key)
+	if err != nil {
+		if purple.IsNotFound(err) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return data.BytesToInt64(val), nil
+}
+
+func (t *diskTxn) CounterIncrement(key string, amount int64) (int64, error) {
+	count, err := t.CounterGet(key)
+	if err != nil {
+		return 0, err
+	}
+
+	count += amount
+
+	return count, t.tx.Set(prefixed(counterPrefix, key), data.Int64ToBytes(count))
+}
+
+func (t *diskTxn) FlagGet(key string) (bool, error) {
+	val, err := txRead(t.tx, flagPrefix, key)
+	if err != nil {
+		if purple.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return data.BoolFromBytes(val)
+}
+
+func (t *diskTxn) FlagSet(key string, value bool) error {
+	return t.tx.Set(prefixed(flagPrefix, key), data.BoolAsBytes(value))
+}
+
+func (t *diskTxn) SetGet(set string) ([]string, bool, error) {
+	s, err := txReadSet(t.tx, set)
+	if err != nil || s == nil {
+		return nil, false, err
+	}
+
+	return s.Get(), true, nil
+}
+
+func (t *diskTxn) SetAdd(set, item string) ([]string, error) {
+	if _, err := txAddMember(t.tx, set, item); err != nil {
+		return nil, err
+	}
+
+	// Reads within the transaction see its own writes
+	items, _, err := t.SetGet(set)
+
+	return items, err
+}
+
+// A set that doesn't exist is left alone rather than reported as not found.
+func (t *diskTxn) SetRemove(set, item string) ([]string, bool, error) {
+	exists, err := txHasSet(t.tx, set)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	if _, err := txRemoveMember(t.tx, set, item); err != nil {
+		return nil, false, err
+	}
+
+	items, _, err := t.SetGet(set)
+
+	return items, true, err
+}
@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/purpledb/purple"
+)
+
+const (
+	// How often expired cache items are deleted when no interval is configured
+	defaultExpiryInterval = time.Minute
+	// How many cache items are compared to pick each item to evict. As in Redis, eviction is approximate: the victim is
+	// the best candidate among a small sample rather than among all items.
+	evictionSamples = 5
+)
+
+// Stats holds the cache counts of a memory backend.
+type Stats struct {
+	// The number of cache items held, including expired items that haven't been deleted yet
+	CacheEntries int
+	// How many cache items were deleted because they expired, either by the sweeper or when they were read
+	Expired uint64
+	// How many cache items were evicted to stay within the maximum number of entries
+	Evicted uint64
+}
+
+// NewMemoryBackendFromConfig creates a memory backend that deletes expired cache items and KV keys in the background,
+// evicts cache items beyond the configured maximum, and persists its data as configured, restoring it first.
+func NewMemoryBackendFromConfig(cfg *purple.MemoryConfig) (*Memory, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := NewMemoryBackend()
+
+	m.maxCacheEntries = cfg.CacheMaxEntries
+	m.eviction = cfg.CacheEviction
+	m.done = make(chan struct{})
+
+	if err := m.setUpPersistence(cfg); err != nil {
+		_ = m.Close()
+		return nil, err
+	}
+
+	// The limit may have been lowered since the data was persisted
+	m.evict()
+
+	interval := cfg.ExpiryInterval
+	if interval == 0 {
+		interval = defaultExpiryInterval
+	}
+
+	m.wg.Add(1)
+	go m.expire(interval)
+
+	return m, nil
+}
+
+func (m *Memory) Stats() Stats {
+	return Stats{
+		CacheEntries: int(atomic.LoadInt64(&m.cacheEntries)),
+		Expired:      atomic.LoadUint64(&m.expiredCount),
+		Evicted:      atomic.LoadUint64(&m.evictedCount),
+	}
+}
+
+// Periodically deletes expired cache items and KV keys, which are otherwise only deleted when they're read.
+func (m *Memory) expire(interval time.Duration) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.sweep()
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// Deletes the expired cache items and KV keys of every shard, locking one shard at a time. Expiry isn't logged to the
+// AOF since replayed entries expire all the same.
+func (m *Memory) sweep() {
+	for _, s := range m.shards {
+		s.mu.Lock()
+
+		for k, item := range s.cache {
+			if expired(item.Item) {
+				m.deleteCacheItem(s, k)
+				atomic.AddUint64(&m.expiredCount, 1)
+			}
+		}
+
+		for k := range s.kvExpiry {
+			if s.kvExpired(k) {
+				s.deleteKV(k)
+			}
+		}
+
+		s.mu.Unlock()
+	}
+}
+
+// Evicts cache items until no more than the maximum are left. Must be called without holding any shard lock.
+func (m *Memory) evict() {
+	if m.maxCacheEntries <= 0 {
+		return
+	}
+
+	for atomic.LoadInt64(&m.cacheEntries) > int64(m.maxCacheEntries) {
+		if !m.evictOne() {
+			return
+		}
+	}
+}
+
+// Samples cache items from shards starting at a random one and deletes the one that should go first. Returns false if
+// there was nothing to evict or the eviction couldn't be logged.
+func (m *Memory) evictOne() bool {
+	var (
+		victimShard *shard
+		victimKey   string
+		victim      *cacheItem
+	)
+
+	start, sampled := rand.Intn(len(m.shards)), 0
+
+	for i := 0; i < len(m.shards) && sampled < evictionSamples; i++ {
+		s := m.shards[(start+i)%len(m.shards)]
+
+		s.mu.RLock()
+		// Map iteration begins at a random item, so the first item is as good a sample as any
+		for k, item := range s.cache {
+			if victim == nil || m.evictsBefore(item, victim) {
+				victimShard, victimKey, victim = s, k, item
+			}
+
+			sampled++
+			break
+		}
+		s.mu.RUnlock()
+	}
+
+	if victim == nil {
+		return false
+	}
+
+	victimShard.mu.Lock()
+	defer victimShard.mu.Unlock()
+
+	// The item may have been replaced or deleted since it was sampled, in which case the caller samples again
+	if current, ok := victimShard.cache[victimKey]; !ok || current != victim {
+		return true
+	}
+
+	// Unlike expiry, eviction is logged so that evicted items don't come back when the AOF is replayed
+	if err := m.log(&entry{Op: opCacheDelete, Key: victimKey}); err != nil {
+		return false
+	}
+
+	m.deleteCacheItem(victimShard, victimKey)
+
+	if expired(victim.Item) {
+		atomic.AddUint64(&m.expiredCount, 1)
+	} else {
+		atomic.AddUint64(&m.evictedCount, 1)
+	}
+
+	return true
+}
+
+// Reports whether a should be evicted before b. Expired items always go first; otherwise LFU evicts the less often
+// read item and LRU the less recently used one, which also breaks ties under LFU.
+func (m *Memory) evictsBefore(a, b *cacheItem) bool {
+	if expiredA, expiredB := expired(a.Item), expired(b.Item); expiredA != expiredB {
+		return expiredA
+	}
+
+	if m.eviction == purple.CacheEvictionLFU {
+		if hitsA, hitsB := atomic.LoadUint64(&a.hits), atomic.LoadUint64(&b.hits); hitsA != hitsB {
+			return hitsA < hitsB
+		}
+	}
+
+	return atomic.LoadInt64(&a.lastAccess) < atomic.LoadInt64(&b.lastAccess)
+}
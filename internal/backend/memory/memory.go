@@ -1,27 +1,96 @@
 package memory
 
 import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/purpledb/purple/internal/data"
-	"github.com/purpledb/purple/internal/services/flag"
+	"github.com/purpledb/purple/services/flag"
 
-	"github.com/purpledb/purple/internal/services/cache"
-	"github.com/purpledb/purple/internal/services/counter"
-	"github.com/purpledb/purple/internal/services/kv"
-	"github.com/purpledb/purple/internal/services/set"
+	"github.com/purpledb/purple/services/cache"
+	"github.com/purpledb/purple/services/counter"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
 
 	"github.com/purpledb/purple"
 )
 
+// The number of shards that keys are distributed across. Each shard has its own lock, which keeps contention low
+// when many goroutines access the backend at once.
+const shardCount = 32
+
+// Memory stores all data in native Go maps. The maps are split across shards by key hash and each shard guards its
+// maps with a RWMutex, so the backend is safe for concurrent use. A backend created from a config (see
+// NewMemoryBackendFromConfig) also deletes expired cache items in the background, can limit the number of cache items,
+// and can persist its data to snapshots and an append-only file.
 type Memory struct {
-	cache    map[string]*cache.Item
+	shards []*shard
+
+	// Nil unless the backend persists its data
+	persist *persistence
+
+	// The maximum number of cache items (zero for no limit) and how the items to evict are picked
+	maxCacheEntries int
+	eviction        string
+
+	// The last version given to a KV value, updated atomically
+	kvVersion uint64
+
+	// Updated atomically and reported by Stats
+	cacheEntries int64
+	expiredCount uint64
+	evictedCount uint64
+
+	// Closed to stop the background work of a backend created from a config
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type shard struct {
+	mu       sync.RWMutex
+	cache    map[string]*cacheItem
 	counters map[string]int64
 	flags    map[string]bool
 	kv       map[string]*kv.Value
+	// The expiry of each KV key that has one
+	kvExpiry map[string]time.Time
 	sets     map[string]*data.Set
 }
 
+func newShard() *shard {
+	s := &shard{}
+	s.reset()
+	return s
+}
+
+// Replaces all of the shard's maps with empty ones. The caller must hold the write lock (or own the shard exclusively).
+func (s *shard) reset() {
+	s.cache = make(map[string]*cacheItem)
+	s.counters = make(map[string]int64)
+	s.flags = make(map[string]bool)
+	s.kv = make(map[string]*kv.Value)
+	s.kvExpiry = make(map[string]time.Time)
+	s.sets = make(map[string]*data.Set)
+}
+
+// A cache item along with the accesses that eviction is based on. The access fields are updated atomically since reads
+// only hold the shard's read lock.
+type cacheItem struct {
+	*cache.Item
+
+	// When the item was last written or read, in Unix nanoseconds
+	lastAccess int64
+	// How often the item has been read
+	hits uint64
+}
+
+func (c *cacheItem) touch() {
+	atomic.StoreInt64(&c.lastAccess, time.Now().UnixNano())
+	atomic.AddUint64(&c.hits, 1)
+}
+
 func (m *Memory) Name() string {
 	return "memory"
 }
@@ -34,56 +103,140 @@ var (
 	_ set.Set         = (*Memory)(nil)
 )
 
+// NewMemoryBackend creates a memory backend without any background work, limits, or persistence.
 func NewMemoryBackend() *Memory {
-	cacheMem := make(map[string]*cache.Item)
+	shards := make([]*shard, shardCount)
+
+	for i := range shards {
+		shards[i] = newShard()
+	}
+
+	return &Memory{
+		shards: shards,
+	}
+}
+
+// Returns the shard responsible for the supplied key.
+func (m *Memory) shard(key string) *shard {
+	return m.shards[m.shardIndex(key)]
+}
 
-	counterMem := make(map[string]int64)
+func (m *Memory) shardIndex(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
 
-	flagMem := make(map[string]bool)
+	return int(h.Sum32() % uint32(len(m.shards)))
+}
 
-	setMem := make(map[string]*data.Set)
+// Returns the shards responsible for the supplied keys, each once and in shard order. Batches lock the shards in that
+// order, as Flush and Snapshot do, which rules out deadlocks between them.
+func (m *Memory) shardsOf(keys []string) []*shard {
+	picked := make([]bool, len(m.shards))
 
-	kvMem := make(map[string]*kv.Value)
+	for _, key := range keys {
+		picked[m.shardIndex(key)] = true
+	}
 
-	return &Memory{
-		cache:    cacheMem,
-		counters: counterMem,
-		flags:    flagMem,
-		kv:       kvMem,
-		sets:     setMem,
+	var shards []*shard
+
+	for i, ok := range picked {
+		if ok {
+			shards = append(shards, m.shards[i])
+		}
 	}
+
+	return shards
 }
 
 // Service methods
 func (m *Memory) Close() error {
+	if m.done != nil {
+		close(m.done)
+		m.wg.Wait()
+	}
+
+	if m.persist != nil {
+		return m.persist.close(m)
+	}
+
 	return nil
 }
 
 func (m *Memory) Flush() error {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	if err := m.log(&entry{Op: opFlush}); err != nil {
+		return err
+	}
+
+	for _, s := range m.shards {
+		s.reset()
+	}
+
+	atomic.StoreInt64(&m.cacheEntries, 0)
+
 	return nil
 }
 
 // Cache
 func (m *Memory) CacheGet(key string) (string, error) {
-	val, ok := m.cache[key]
+	s := m.shard(key)
+
+	s.mu.RLock()
+	val, ok := s.cache[key]
+	s.mu.RUnlock()
 
 	if !ok {
 		return "", purple.NotFound(key)
 	}
 
-	now := time.Now().Unix()
-
-	expired := (now - val.Timestamp) > int64(val.TTLSeconds)
-
-	if expired {
-		delete(m.cache, key)
+	if expired(val.Item) {
+		s.mu.Lock()
+		// The item may have been replaced since the read lock was released
+		if current, ok := s.cache[key]; ok && expired(current.Item) {
+			m.deleteCacheItem(s, key)
+			atomic.AddUint64(&m.expiredCount, 1)
+		}
+		s.mu.Unlock()
 
 		return "", purple.NotFound(key)
 	}
 
+	val.touch()
+
 	return val.Value, nil
 }
 
+// Returns the number of seconds until a cache item expires, rounded up.
+func (m *Memory) CacheTTL(key string) (int32, error) {
+	s := m.shard(key)
+
+	s.mu.RLock()
+	val, ok := s.cache[key]
+	s.mu.RUnlock()
+
+	if !ok || expired(val.Item) {
+		return 0, purple.NotFound(key)
+	}
+
+	// Items are kept until the second after their TTL has elapsed
+	remaining := val.Timestamp + int64(val.TTLSeconds) - time.Now().Unix()
+	if remaining < 1 {
+		remaining = 1
+	}
+
+	return int32(remaining), nil
+}
+
+func expired(item *cache.Item) bool {
+	now := time.Now().Unix()
+
+	return (now - item.Timestamp) > int64(item.TTLSeconds)
+}
+
 func (m *Memory) CacheSet(key, value string, ttl int32) error {
 	if key == "" {
 		return purple.ErrNoKey
@@ -99,11 +252,42 @@ func (m *Memory) CacheSet(key, value string, ttl int32) error {
 		TTLSeconds: parseTtl(ttl),
 	}
 
-	m.cache[key] = item
+	s := m.shard(key)
+
+	s.mu.Lock()
+
+	if err := m.log(&entry{Op: opCacheSet, Key: key, Value: value, Timestamp: item.Timestamp, TTL: item.TTLSeconds}); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	m.putCacheItem(s, key, item)
+
+	s.mu.Unlock()
+
+	// Other shards may need to be locked to find the items to evict, so the lock is released first
+	m.evict()
 
 	return nil
 }
 
+// Stores a cache item and keeps count of the number of items. The caller must hold the shard's write lock.
+func (m *Memory) putCacheItem(s *shard, key string, item *cache.Item) {
+	if _, ok := s.cache[key]; !ok {
+		atomic.AddInt64(&m.cacheEntries, 1)
+	}
+
+	s.cache[key] = &cacheItem{Item: item, lastAccess: time.Now().UnixNano()}
+}
+
+// Deletes a cache item and keeps count of the number of items. The caller must hold the shard's write lock.
+func (m *Memory) deleteCacheItem(s *shard, key string) {
+	if _, ok := s.cache[key]; ok {
+		delete(s.cache, key)
+		atomic.AddInt64(&m.cacheEntries, -1)
+	}
+}
+
 func parseTtl(ttl int32) int32 {
 	if ttl == 0 {
 		return cache.DefaultTtl
@@ -112,26 +296,60 @@ func parseTtl(ttl int32) int32 {
 	}
 }
 
+// Lists the keys of unexpired cache items. Expired items are left in place for CacheGet or the sweeper to delete.
+func (m *Memory) CacheList(prefix, cursor string, limit int) ([]string, string, error) {
+	return m.list(prefix, cursor, limit, func(s *shard) (keys []string) {
+		for k, item := range s.cache {
+			if !expired(item.Item) {
+				keys = append(keys, k)
+			}
+		}
+		return
+	})
+}
+
 // Counter
 func (m *Memory) CounterIncrement(key string, increment int64) (int64, error) {
-	count, ok := m.counters[key]
-	if !ok {
-		m.counters[key] = increment
-	} else {
-		total := count + increment
-		m.counters[key] = total
+	s := m.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := m.log(&entry{Op: opCounterIncr, Key: key, Count: increment}); err != nil {
+		return 0, err
 	}
 
-	return m.counters[key], nil
+	s.counters[key] += increment
+
+	return s.counters[key], nil
 }
 
 func (m *Memory) CounterGet(key string) (int64, error) {
-	return m.counters[key], nil
+	s := m.shard(key)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.counters[key], nil
+}
+
+func (m *Memory) CounterList(prefix, cursor string, limit int) ([]string, string, error) {
+	return m.list(prefix, cursor, limit, func(s *shard) (keys []string) {
+		for k := range s.counters {
+			keys = append(keys, k)
+		}
+		return
+	})
 }
 
 // Flag
 func (m *Memory) FlagGet(key string) (bool, error) {
-	val, ok := m.flags[key]
+	s := m.shard(key)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	val, ok := s.flags[key]
 	if !ok {
 		return false, nil
 	}
@@ -140,67 +358,613 @@ func (m *Memory) FlagGet(key string) (bool, error) {
 }
 
 func (m *Memory) FlagSet(key string, value bool) error {
-	m.flags[key] = value
+	s := m.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := m.log(&entry{Op: opFlagSet, Key: key, Flag: value}); err != nil {
+		return err
+	}
+
+	s.flags[key] = value
 
 	return nil
 }
 
+func (m *Memory) FlagList(prefix, cursor string, limit int) ([]string, string, error) {
+	return m.list(prefix, cursor, limit, func(s *shard) (keys []string) {
+		for k := range s.flags {
+			keys = append(keys, k)
+		}
+		return
+	})
+}
+
 // KV
 func (m *Memory) KVGet(key string) (*kv.Value, error) {
-	val, ok := m.kv[key]
+	s := m.shard(key)
+
+	s.mu.RLock()
+	val, ok := s.kv[key]
+	expiredVal := ok && s.kvExpired(key)
+	s.mu.RUnlock()
+
 	if !ok {
 		return nil, purple.NotFound(key)
 	}
 
+	if expiredVal {
+		s.mu.Lock()
+		// The key may have been written since the read lock was released
+		if s.kv[key] == val && s.kvExpired(key) {
+			s.deleteKV(key)
+		}
+		s.mu.Unlock()
+
+		return nil, purple.NotFound(key)
+	}
+
 	return val, nil
 }
 
-func (m *Memory) KVPut(key string, value *kv.Value) error {
-	m.kv[key] = value
+func (m *Memory) KVPut(key string, value *kv.Value, ttl int32) error {
+	return m.putKV(key, value, ttl, func(*kv.Value) bool { return true })
+}
+
+func (m *Memory) KVPutIfVersion(key string, value *kv.Value, version uint64, ttl int32) error {
+	return m.putKV(key, value, ttl, func(current *kv.Value) bool { return current != nil && current.Version == version })
+}
+
+func (m *Memory) KVPutIfAbsent(key string, value *kv.Value, ttl int32) error {
+	return m.putKV(key, value, ttl, func(current *kv.Value) bool { return current == nil })
+}
+
+// Stores a copy of the value with a new version, timestamps, and expiry if check accepts the current value, which is
+// nil if the key doesn't exist, and fails with a conflict error otherwise.
+func (m *Memory) putKV(key string, value *kv.Value, ttl int32, check func(current *kv.Value) bool) error {
+	if err := kv.CheckTTL(ttl); err != nil {
+		return err
+	}
+
+	s := m.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.liveKV(key)
+
+	if !check(current) {
+		return purple.Conflict(key)
+	}
+
+	now := time.Now()
+
+	stored := value.Stamped(current, now)
+	stored.Version = atomic.AddUint64(&m.kvVersion, 1)
+	expires := kv.Expiry(ttl, now)
+
+	if err := m.log(kvPutEntry(key, stored, expires)); err != nil {
+		return err
+	}
+
+	s.putKV(key, stored, expires)
+
 	return nil
 }
 
 func (m *Memory) KVDelete(key string) error {
-	delete(m.kv, key)
+	return m.deleteKV(key, func(*kv.Value) bool { return true })
+}
+
+func (m *Memory) KVDeleteIfVersion(key string, version uint64) error {
+	return m.deleteKV(key, func(current *kv.Value) bool { return current != nil && current.Version == version })
+}
+
+func (m *Memory) deleteKV(key string, check func(current *kv.Value) bool) error {
+	s := m.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !check(s.liveKV(key)) {
+		return purple.Conflict(key)
+	}
+
+	if err := m.log(&entry{Op: opKVDelete, Key: key}); err != nil {
+		return err
+	}
+
+	s.deleteKV(key)
+
+	return nil
+}
+
+func (m *Memory) KVTTL(key string) (int32, error) {
+	s := m.shard(key)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.liveKV(key) == nil {
+		return 0, purple.NotFound(key)
+	}
+
+	return kv.Remaining(s.kvExpiry[key], time.Now()), nil
+}
+
+func (m *Memory) KVExpire(key string, ttl int32) error {
+	if err := kv.CheckExpireTTL(ttl); err != nil {
+		return err
+	}
+
+	return m.expireKV(key, ttl)
+}
+
+func (m *Memory) KVPersist(key string) error {
+	return m.expireKV(key, 0)
+}
+
+// Gives the key a new expiry, or none if ttl is 0, along with a new version.
+func (m *Memory) expireKV(key string, ttl int32) error {
+	s := m.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.liveKV(key)
+	if current == nil {
+		return purple.NotFound(key)
+	}
+
+	// Values handed out by KVGet must not change, so the new version goes on a copy
+	stored := *current
+	stored.Version = atomic.AddUint64(&m.kvVersion, 1)
+	expires := kv.Expiry(ttl, time.Now())
+
+	if err := m.log(&entry{Op: opKVExpire, Key: key, Version: stored.Version, Expires: kv.UnixNano(expires)}); err != nil {
+		return err
+	}
+
+	s.putKV(key, &stored, expires)
+
+	return nil
+}
+
+func (m *Memory) KVList(prefix, cursor string, limit int) ([]string, string, error) {
+	return m.list(prefix, cursor, limit, func(s *shard) (keys []string) {
+		for k := range s.kv {
+			if !s.kvExpired(k) {
+				keys = append(keys, k)
+			}
+		}
+		return
+	})
+}
+
+// The batch operations hold the locks of all shards involved at once, so that a batch is applied atomically.
+func (m *Memory) KVGetMany(keys []string) ([]*kv.Value, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
+		return nil, err
+	}
+
+	shards := m.shardsOf(keys)
+
+	for _, s := range shards {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+
+	// Expired keys are left for the sweeper, since deleting them would take the write locks
+	values := make([]*kv.Value, len(keys))
+
+	for i, key := range keys {
+		values[i] = m.shard(key).liveKV(key)
+	}
+
+	return values, nil
+}
+
+func (m *Memory) KVPutMany(entries []*kv.Entry) error {
+	if err := kv.CheckEntries(entries); err != nil {
+		return err
+	}
+
+	keys := make([]string, len(entries))
+
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+
+	for _, s := range m.shardsOf(keys) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	now := time.Now()
+
+	var (
+		stored  = make([]*kv.Value, len(entries))
+		expires = make([]time.Time, len(entries))
+		batch   = make([]*entry, len(entries))
+	)
+
+	// A key that appears more than once ends up with the last of its values. The earlier ones can't change its
+	// creation time, so every value of the key is stamped against the value stored before the batch.
+	for i, e := range entries {
+		stored[i] = e.Value.Stamped(m.shard(e.Key).liveKV(e.Key), now)
+		stored[i].Version = atomic.AddUint64(&m.kvVersion, 1)
+		expires[i] = kv.Expiry(e.TTL, now)
+		batch[i] = kvPutEntry(e.Key, stored[i], expires[i])
+	}
+
+	if err := m.log(&entry{Op: opBatch, Entries: batch}); err != nil {
+		return err
+	}
+
+	for i, e := range entries {
+		m.shard(e.Key).putKV(e.Key, stored[i], expires[i])
+	}
+
 	return nil
 }
 
+func (m *Memory) KVDeleteMany(keys []string) ([]bool, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
+		return nil, err
+	}
+
+	for _, s := range m.shardsOf(keys) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	var (
+		existed = make([]bool, len(keys))
+		deleted = make(map[string]bool, len(keys))
+		batch   = make([]*entry, len(keys))
+	)
+
+	for i, key := range keys {
+		existed[i] = !deleted[key] && m.shard(key).liveKV(key) != nil
+		deleted[key] = true
+		batch[i] = &entry{Op: opKVDelete, Key: key}
+	}
+
+	if err := m.log(&entry{Op: opBatch, Entries: batch}); err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		m.shard(key).deleteKV(key)
+	}
+
+	return existed, nil
+}
+
+// Returns the value stored under key unless it doesn't exist or has expired. The caller must hold the shard's lock.
+func (s *shard) liveKV(key string) *kv.Value {
+	if s.kvExpired(key) {
+		return nil
+	}
+
+	return s.kv[key]
+}
+
+// Reports whether the key has an expiry that has passed. The caller must hold the shard's lock.
+func (s *shard) kvExpired(key string) bool {
+	expires, ok := s.kvExpiry[key]
+
+	return ok && !time.Now().Before(expires)
+}
+
+// Stores the value and its expiry, which is the zero time if it doesn't expire. The caller must hold the write lock.
+func (s *shard) putKV(key string, value *kv.Value, expires time.Time) {
+	s.kv[key] = value
+
+	if expires.IsZero() {
+		delete(s.kvExpiry, key)
+	} else {
+		s.kvExpiry[key] = expires
+	}
+}
+
+// The caller must hold the write lock.
+func (s *shard) deleteKV(key string) {
+	delete(s.kv, key)
+	delete(s.kvExpiry, key)
+}
+
 // Set
 func (m *Memory) SetGet(set string) ([]string, error) {
-	s, ok := m.sets[set]
+	s := m.shard(set)
 
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, ok := s.sets[set]
 	if !ok {
 		return nil, purple.NotFound(set)
 	}
 
-	return s.Get(), nil
+	return st.Get(), nil
 }
 
 func (m *Memory) SetAdd(set, item string) ([]string, error) {
-	var result []string
+	s := m.shard(set)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	s, ok := m.sets[set]
+	if err := m.log(&entry{Op: opSetAdd, Key: set, Items: []string{item}}); err != nil {
+		return nil, err
+	}
 
+	st, ok := s.sets[set]
 	if ok {
-		s.Add(item)
-		result = s.Get()
+		st.Add(item)
 	} else {
-		newSet := data.NewSet(item)
-
-		m.sets[set] = newSet
-		result = newSet.Get()
+		st = data.NewSet(item)
+		s.sets[set] = st
 	}
 
-	return result, nil
+	return st.Get(), nil
 }
 
 func (m *Memory) SetRemove(set, item string) ([]string, error) {
-	s, ok := m.sets[set]
+	s := m.shard(set)
 
-	if ok {
-		s.Remove(item)
-		return s.Get(), nil
-	} else {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.sets[set]
+	if !ok {
 		return nil, purple.NotFound(set)
 	}
+
+	if err := m.log(&entry{Op: opSetRemove, Key: set, Items: []string{item}}); err != nil {
+		return nil, err
+	}
+
+	st.Remove(item)
+
+	return st.Get(), nil
+}
+
+func (m *Memory) SetGetPage(key, cursor string, limit int) ([]string, string, error) {
+	s := m.shard(key)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, ok := s.sets[key]
+	if !ok {
+		return make([]string, 0), "", nil
+	}
+
+	page, next := st.Page(cursor, limit)
+
+	return page, next, nil
+}
+
+func (m *Memory) SetAddSummary(key, item string) (*set.Summary, error) {
+	s := m.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := m.log(&entry{Op: opSetAdd, Key: key, Items: []string{item}}); err != nil {
+		return nil, err
+	}
+
+	st, ok := s.sets[key]
+	if !ok {
+		st = data.NewSet()
+		s.sets[key] = st
+	}
+
+	changed := !st.Contains(item)
+	st.Add(item)
+
+	return &set.Summary{Changed: changed, Size: st.Len()}, nil
+}
+
+func (m *Memory) SetRemoveSummary(key, item string) (*set.Summary, error) {
+	s := m.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.sets[key]
+	if !ok {
+		return &set.Summary{}, nil
+	}
+
+	if err := m.log(&entry{Op: opSetRemove, Key: key, Items: []string{item}}); err != nil {
+		return nil, err
+	}
+
+	changed := st.Contains(item)
+	st.Remove(item)
+
+	return &set.Summary{Changed: changed, Size: st.Len()}, nil
+}
+
+func (m *Memory) SetRandom(key string, count int) ([]string, error) {
+	s := m.shard(key)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if st, ok := s.sets[key]; ok {
+		return st.Random(count), nil
+	}
+
+	return make([]string, 0), nil
+}
+
+// The popped items are logged as removals, since which items are picked isn't repeatable.
+func (m *Memory) SetPop(key string, count int) ([]string, error) {
+	if err := set.CheckPopCount(count); err != nil {
+		return nil, err
+	}
+
+	s := m.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.sets[key]
+	if !ok {
+		return make([]string, 0), nil
+	}
+
+	popped := st.Random(count)
+	if len(popped) == 0 {
+		return popped, nil
+	}
+
+	if err := m.log(&entry{Op: opSetRemove, Key: key, Items: popped}); err != nil {
+		return nil, err
+	}
+
+	for _, item := range popped {
+		st.Remove(item)
+	}
+
+	return popped, nil
+}
+
+func (m *Memory) SetContains(set, item string) (bool, error) {
+	s := m.shard(set)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, ok := s.sets[set]
+
+	return ok && st.Contains(item), nil
+}
+
+func (m *Memory) SetSize(set string) (int, error) {
+	s := m.shard(set)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if st, ok := s.sets[set]; ok {
+		return st.Len(), nil
+	}
+
+	return 0, nil
+}
+
+func (m *Memory) SetUnion(sets ...string) ([]string, error) {
+	return m.combineSets(set.Union, sets)
+}
+
+func (m *Memory) SetIntersect(sets ...string) ([]string, error) {
+	return m.combineSets(set.Intersect, sets)
+}
+
+func (m *Memory) SetDiff(sets ...string) ([]string, error) {
+	return m.combineSets(set.Diff, sets)
+}
+
+func (m *Memory) SetUnionStore(dest string, sets ...string) (int, error) {
+	return m.storeSets(set.Union, dest, sets)
+}
+
+func (m *Memory) SetIntersectStore(dest string, sets ...string) (int, error) {
+	return m.storeSets(set.Intersect, dest, sets)
+}
+
+func (m *Memory) SetDiffStore(dest string, sets ...string) (int, error) {
+	return m.storeSets(set.Diff, dest, sets)
+}
+
+// Combines the sets under the read locks of all of their shards.
+func (m *Memory) combineSets(op string, sets []string) ([]string, error) {
+	if err := set.CheckSets(sets); err != nil {
+		return nil, err
+	}
+
+	for _, s := range m.shardsOf(sets) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+
+	return data.Combine(op, m.lookupSets(sets)).Get(), nil
+}
+
+// Combines the sets and stores the result while holding the write locks of the shards of the sets and destination.
+func (m *Memory) storeSets(op, dest string, sets []string) (int, error) {
+	if err := set.CheckSets(sets, dest); err != nil {
+		return 0, err
+	}
+
+	for _, s := range m.shardsOf(append([]string{dest}, sets...)) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	result := data.Combine(op, m.lookupSets(sets))
+
+	if err := m.log(&entry{Op: opSetStore, Key: dest, Items: result.Get()}); err != nil {
+		return 0, err
+	}
+
+	m.shard(dest).storeSet(dest, result)
+
+	return result.Len(), nil
+}
+
+// Returns the sets, with empty ones for those that don't exist. The caller must hold the locks of their shards.
+func (m *Memory) lookupSets(sets []string) []*data.Set {
+	found := make([]*data.Set, len(sets))
+
+	for i, name := range sets {
+		if st, ok := m.shard(name).sets[name]; ok {
+			found[i] = st
+		} else {
+			found[i] = data.NewSet()
+		}
+	}
+
+	return found
+}
+
+// Replaces the set, or deletes it if st is empty. The caller must hold the write lock.
+func (s *shard) storeSet(name string, st *data.Set) {
+	if st.Len() == 0 {
+		delete(s.sets, name)
+	} else {
+		s.sets[name] = st
+	}
+}
+
+func (m *Memory) SetList(prefix, cursor string, limit int) ([]string, string, error) {
+	return m.list(prefix, cursor, limit, func(s *shard) (keys []string) {
+		for k := range s.sets {
+			keys = append(keys, k)
+		}
+		return
+	})
+}
+
+// Gathers the keys returned by fn from every shard, each under its read lock, and returns the requested page of them.
+// The maps aren't ordered, so every page visits all of the service's keys: a page costs O(n log limit) for n keys and
+// walking all of them page by page costs O(n²/limit log limit).
+func (m *Memory) list(prefix, cursor string, limit int, fn func(s *shard) []string) ([]string, string, error) {
+	var keys []string
+
+	for _, s := range m.shards {
+		s.mu.RLock()
+		keys = append(keys, fn(s)...)
+		s.mu.RUnlock()
+	}
+
+	page, next := data.Paginate(keys, prefix, cursor, limit)
+
+	return page, next, nil
 }
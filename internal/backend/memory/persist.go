@@ -0,0 +1,586 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/data"
+	"github.com/purpledb/purple/internal/util"
+	"github.com/purpledb/purple/services/cache"
+	"github.com/purpledb/purple/services/kv"
+)
+
+// Snapshots and the AOF share a format: newline-delimited JSON entries that each describe one mutation. A snapshot is
+// the shortest log that recreates the data, which is also what the AOF is rewritten to when it's compacted.
+const (
+	opCacheSet    = "cache.set"
+	opCacheDelete = "cache.delete"
+	opCounterIncr = "counter.incr"
+	opFlagSet     = "flag.set"
+	opKVPut       = "kv.put"
+	opKVDelete    = "kv.delete"
+	opKVExpire    = "kv.expire"
+	opSetAdd      = "set.add"
+	opSetRemove   = "set.remove"
+	opFlush       = "flush"
+	// Replaces a set with the items, or deletes it if there are none
+	opSetStore = "set.store"
+	// Holds the entries of a batch operation, which are written as a single line so that they're replayed all or none
+	opBatch = "batch"
+)
+
+type entry struct {
+	Op          string            `json:"op"`
+	Key         string            `json:"key,omitempty"`
+	Value       string            `json:"value,omitempty"`
+	Timestamp   int64             `json:"ts,omitempty"`
+	TTL         int32             `json:"ttl,omitempty"`
+	Count       int64             `json:"count,omitempty"`
+	Flag        bool              `json:"flag,omitempty"`
+	Content     []byte            `json:"content,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Version     uint64            `json:"version,omitempty"`
+	Created     int64             `json:"created,omitempty"`
+	Updated     int64             `json:"updated,omitempty"`
+	Expires     int64             `json:"expires,omitempty"`
+	Items       []string          `json:"items,omitempty"`
+	Entries     []*entry          `json:"entries,omitempty"`
+}
+
+func kvPutEntry(key string, val *kv.Value, expires time.Time) *entry {
+	return &entry{
+		Op:          opKVPut,
+		Key:         key,
+		Content:     val.Content,
+		ContentType: val.ContentType,
+		Metadata:    val.Metadata,
+		Version:     val.Version,
+		Created:     kv.UnixNano(val.Created),
+		Updated:     kv.UnixNano(val.Updated),
+		Expires:     kv.UnixNano(expires),
+	}
+}
+
+// Holds the state of a memory backend that persists its data. Locks are always taken in the same order to rule out
+// deadlocks: shard locks (in shard order) before mu.
+type persistence struct {
+	cfg purple.MemoryConfig
+
+	// Guards the AOF, which is replaced when it's compacted
+	mu  sync.Mutex
+	aof *os.File
+	// The current size of the AOF and its size after it was last compacted
+	size, baseSize int64
+
+	rewrite chan struct{}
+}
+
+// Sets up persistence as configured, restoring the data from the AOF or snapshot first. Nothing is done if neither
+// path is configured.
+func (m *Memory) setUpPersistence(cfg *purple.MemoryConfig) error {
+	if cfg.SnapshotPath == "" && cfg.AOFPath == "" {
+		return nil
+	}
+
+	p := &persistence{
+		cfg:     *cfg,
+		rewrite: make(chan struct{}, 1),
+	}
+
+	if p.cfg.AOFSync == "" {
+		p.cfg.AOFSync = purple.AOFSyncEverySec
+	}
+
+	if err := m.restore(cfg); err != nil {
+		return err
+	}
+
+	if cfg.AOFPath != "" {
+		if err := p.openAOF(); err != nil {
+			return err
+		}
+	}
+
+	m.persist = p
+
+	m.wg.Add(1)
+	go m.background()
+
+	return nil
+}
+
+// Restores the data from the AOF or, if there's no AOF to restore from, the snapshot. Every mutation is in the AOF, so
+// it's never older than the last snapshot. An AOF that's missing or empty holds nothing though, as when the AOF was only
+// just configured, so the snapshot's data is written to it to start it off. Otherwise the next restart would restore
+// from an AOF holding only the mutations made since.
+func (m *Memory) restore(cfg *purple.MemoryConfig) error {
+	fromAOF, err := hasEntries(cfg.AOFPath)
+	if err != nil {
+		return err
+	}
+
+	if fromAOF {
+		return m.replay(cfg.AOFPath)
+	}
+
+	if cfg.SnapshotPath == "" {
+		return nil
+	}
+
+	if err := m.replay(cfg.SnapshotPath); err != nil {
+		return err
+	}
+
+	if cfg.AOFPath == "" {
+		return nil
+	}
+
+	// The backend isn't in use yet, so there's no need to lock the shards
+	return m.writeState(cfg.AOFPath, true)
+}
+
+// Reports whether the file at path exists and isn't empty.
+func hasEntries(path string) (bool, error) {
+	if path == "" {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return info.Size() > 0, nil
+}
+
+// Takes snapshots, syncs the AOF, and compacts the AOF as configured until the backend is closed. Errors can't be
+// reported from here; the next explicit Snapshot, RewriteAOF, or mutation surfaces persistent failures.
+func (m *Memory) background() {
+	p := m.persist
+
+	defer m.wg.Done()
+
+	var snapshots, syncs <-chan time.Time
+
+	if p.cfg.SnapshotPath != "" && p.cfg.SnapshotInterval > 0 {
+		ticker := time.NewTicker(p.cfg.SnapshotInterval)
+		defer ticker.Stop()
+
+		snapshots = ticker.C
+	}
+
+	if p.cfg.AOFPath != "" && p.cfg.AOFSync == purple.AOFSyncEverySec {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		syncs = ticker.C
+	}
+
+	for {
+		select {
+		case <-snapshots:
+			_ = m.Snapshot()
+		case <-syncs:
+			p.mu.Lock()
+			_ = p.aof.Sync()
+			p.mu.Unlock()
+		case <-p.rewrite:
+			_ = m.RewriteAOF()
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// Writes a final snapshot and syncs and closes the AOF. The background work must have been stopped.
+func (p *persistence) close(m *Memory) error {
+	if p.cfg.SnapshotPath != "" {
+		if err := m.Snapshot(); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.aof == nil {
+		return nil
+	}
+
+	if err := p.aof.Sync(); err != nil {
+		_ = p.aof.Close()
+		return err
+	}
+
+	return p.aof.Close()
+}
+
+func (p *persistence) openAOF() error {
+	if err := util.MkDirIfNotExists(filepath.Dir(p.cfg.AOFPath)); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(p.cfg.AOFPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if p.aof != nil {
+		_ = p.aof.Close()
+	}
+
+	p.aof, p.size, p.baseSize = f, info.Size(), info.Size()
+
+	return nil
+}
+
+// Appends a mutation to the AOF, if there is one. It's called before the mutation is applied, with the lock of the
+// shard it applies to held, so the AOF records mutations of a key in the order they're applied.
+func (m *Memory) log(e *entry) error {
+	p := m.persist
+
+	if p == nil || p.cfg.AOFPath == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.aof.Write(line); err != nil {
+		// Cut off whatever part of the entry was written so that the next entry starts on a line of its own
+		_ = p.aof.Truncate(p.size)
+		return err
+	}
+
+	p.size += int64(len(line))
+
+	if p.cfg.AOFSync == purple.AOFSyncAlways {
+		if err := p.aof.Sync(); err != nil {
+			return err
+		}
+	}
+
+	if p.cfg.AOFRewriteSize > 0 && p.size > p.cfg.AOFRewriteSize && p.size > 2*p.baseSize {
+		select {
+		case p.rewrite <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Snapshot writes all data to the snapshot file, replacing the previous snapshot only once the new one is complete.
+// Mutations wait while the snapshot is taken, which makes it consistent across shards; reads carry on.
+func (m *Memory) Snapshot() error {
+	if m.persist == nil || m.persist.cfg.SnapshotPath == "" {
+		return purple.ErrNoSnapshotPath
+	}
+
+	m.rlockAll()
+	defer m.runlockAll()
+
+	return m.writeState(m.persist.cfg.SnapshotPath, false)
+}
+
+// RewriteAOF compacts the AOF by replacing it with the shortest log that recreates the current data. It's called
+// automatically once the AOF grows past the configured rewrite size. Mutations wait while the AOF is rewritten.
+func (m *Memory) RewriteAOF() error {
+	p := m.persist
+
+	if p == nil || p.cfg.AOFPath == "" {
+		return purple.ErrNoAOFPath
+	}
+
+	m.rlockAll()
+	defer m.runlockAll()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.aof.Sync(); err != nil {
+		return err
+	}
+
+	if err := m.writeState(p.cfg.AOFPath, true); err != nil {
+		return err
+	}
+
+	return p.openAOF()
+}
+
+// Writes the entries that recreate the current data to a temporary file and then moves it to path. The caller must
+// hold every shard's lock. AOFs start with a flush so that they're never empty, even if there's no data, which would
+// have them passed over in favor of the snapshot on restore.
+func (m *Memory) writeState(path string, aof bool) error {
+	if err := util.MkDirIfNotExists(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+
+	if aof {
+		if err := enc.Encode(&entry{Op: opFlush}); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+
+	for _, s := range m.shards {
+		if err := s.eachEntry(enc.Encode); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Calls fn with an entry for everything stored in the shard. Expired cache items are left out.
+func (s *shard) eachEntry(fn func(v interface{}) error) error {
+	for k, item := range s.cache {
+		if expired(item.Item) {
+			continue
+		}
+
+		if err := fn(&entry{Op: opCacheSet, Key: k, Value: item.Value, Timestamp: item.Timestamp, TTL: item.TTLSeconds}); err != nil {
+			return err
+		}
+	}
+
+	for k, count := range s.counters {
+		if err := fn(&entry{Op: opCounterIncr, Key: k, Count: count}); err != nil {
+			return err
+		}
+	}
+
+	for k, flag := range s.flags {
+		if err := fn(&entry{Op: opFlagSet, Key: k, Flag: flag}); err != nil {
+			return err
+		}
+	}
+
+	for k, val := range s.kv {
+		if s.kvExpired(k) {
+			continue
+		}
+
+		if err := fn(kvPutEntry(k, val, s.kvExpiry[k])); err != nil {
+			return err
+		}
+	}
+
+	for k, st := range s.sets {
+		if err := fn(&entry{Op: opSetAdd, Key: k, Items: st.Get()}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Applies the entries in the file at path, if it exists. An entry that was only partly written when the process
+// stopped is cut off the end of the file rather than treated as corruption.
+func (m *Memory) replay(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+
+	var offset int64
+
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				return os.Truncate(path, offset)
+			}
+
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var e entry
+
+		if err := json.Unmarshal(line, &e); err != nil {
+			return fmt.Errorf("%s line %d: %w", path, n, err)
+		}
+
+		if err := m.apply(&e); err != nil {
+			return fmt.Errorf("%s line %d: %w", path, n, err)
+		}
+
+		offset += int64(len(line))
+	}
+}
+
+// Applies a replayed entry. It's only called before persistence is set up, so nothing is logged.
+func (m *Memory) apply(e *entry) error {
+	var err error
+
+	switch e.Op {
+	case opCacheSet:
+		s := m.shard(e.Key)
+
+		// Cache items keep their original expiry
+		s.mu.Lock()
+		m.putCacheItem(s, e.Key, &cache.Item{Value: e.Value, Timestamp: e.Timestamp, TTLSeconds: e.TTL})
+		s.mu.Unlock()
+	case opCacheDelete:
+		s := m.shard(e.Key)
+
+		s.mu.Lock()
+		m.deleteCacheItem(s, e.Key)
+		s.mu.Unlock()
+	case opCounterIncr:
+		_, err = m.CounterIncrement(e.Key, e.Count)
+	case opFlagSet:
+		err = m.FlagSet(e.Key, e.Flag)
+	case opKVPut:
+		s := m.shard(e.Key)
+
+		// Values keep their original version, timestamps, and expiry, and later writes carry on from the highest version
+		s.mu.Lock()
+		s.putKV(e.Key, &kv.Value{
+			Content:     e.Content,
+			ContentType: e.ContentType,
+			Metadata:    e.Metadata,
+			Version:     e.Version,
+			Created:     kv.FromUnixNano(e.Created),
+			Updated:     kv.FromUnixNano(e.Updated),
+		}, kv.FromUnixNano(e.Expires))
+		s.mu.Unlock()
+
+		m.replayedKVVersion(e.Version)
+	case opKVDelete:
+		err = m.KVDelete(e.Key)
+	case opKVExpire:
+		s := m.shard(e.Key)
+
+		s.mu.Lock()
+		if current, ok := s.kv[e.Key]; ok {
+			stored := *current
+			stored.Version = e.Version
+			s.putKV(e.Key, &stored, kv.FromUnixNano(e.Expires))
+		}
+		s.mu.Unlock()
+
+		m.replayedKVVersion(e.Version)
+	case opSetAdd:
+		// Snapshots also hold sets that have been emptied, which have no items to add
+		if len(e.Items) == 0 {
+			s := m.shard(e.Key)
+
+			s.mu.Lock()
+			if _, ok := s.sets[e.Key]; !ok {
+				s.sets[e.Key] = data.NewSet()
+			}
+			s.mu.Unlock()
+		}
+
+		for _, item := range e.Items {
+			if _, err = m.SetAdd(e.Key, item); err != nil {
+				break
+			}
+		}
+	case opSetRemove:
+		for _, item := range e.Items {
+			if _, err = m.SetRemove(e.Key, item); err != nil && !purple.IsNotFound(err) {
+				break
+			}
+
+			err = nil
+		}
+	case opSetStore:
+		s := m.shard(e.Key)
+
+		s.mu.Lock()
+		s.storeSet(e.Key, data.NewSet(e.Items...))
+		s.mu.Unlock()
+	case opFlush:
+		err = m.Flush()
+	case opBatch:
+		for _, e := range e.Entries {
+			if err = m.apply(e); err != nil {
+				break
+			}
+		}
+	default:
+		err = purple.ErrInvalidLogEntry
+	}
+
+	return err
+}
+
+// Makes sure that later writes get versions above one that was replayed.
+func (m *Memory) replayedKVVersion(version uint64) {
+	if version > m.kvVersion {
+		m.kvVersion = version
+	}
+}
+
+func (m *Memory) rlockAll() {
+	for _, s := range m.shards {
+		s.mu.RLock()
+	}
+}
+
+func (m *Memory) runlockAll() {
+	for _, s := range m.shards {
+		s.mu.RUnlock()
+	}
+}
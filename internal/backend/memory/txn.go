@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/purpledb/purple/internal/data"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/txn"
+)
+
+// Txn holds the write locks of every shard the transaction touches while its operations are applied. The mutations are
+// then logged to the AOF as a single batch entry; if that fails, they're undone before the locks are released.
+func (m *Memory) Txn(ops []*txn.Op) ([]*txn.Result, error) {
+	if err := txn.Validate(ops); err != nil {
+		return nil, err
+	}
+
+	return m.commit(nil, ops)
+}
+
+// TxnBegin starts a session whose reads are checked again once it's committed, within the same locks as its writes.
+func (m *Memory) TxnBegin() (txn.Session, error) {
+	return txn.NewSession(m.Txn, m.commit), nil
+}
+
+func (m *Memory) commit(reads []*txn.Read, ops []*txn.Op) ([]*txn.Result, error) {
+	var keys []string
+
+	for _, op := range append(txn.ReadOps(reads), ops...) {
+		keys = append(keys, op.Key)
+	}
+
+	for _, s := range m.shardsOf(keys) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	t := &memoryTxn{m: m, now: time.Now()}
+
+	results, err := txn.Commit(t, reads, ops)
+	if err == nil && len(t.entries) > 0 {
+		err = m.log(&entry{Op: opBatch, Entries: t.entries})
+	}
+
+	if err != nil {
+		t.rollback()
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Applies a transaction's operations to the shards, whose locks the caller holds, while keeping track of how to log
+// and undo them.
+type memoryTxn struct {
+	m   *Memory
+	now time.Time
+
+	entries []*entry
+	undo    []func()
+}
+
+var _ txn.Store = (*memoryTxn)(nil)
+
+// Undoes the mutations in reverse order.
+func (t *memoryTxn) rollback() {
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+}
+
+// Remembers how to put the entry of key in mp back the way it is now.
+func saveEntry[V any](t *memoryTxn, mp map[string]V, key string) {
+	prev, ok := mp[key]
+
+	t.undo = append(t.undo, func() {
+		if ok {
+			mp[key] = prev
+		} else {
+			delete(mp, key)
+		}
+	})
+}
+
+func (t *memoryTxn) saveKV(s *shard, key string) {
+	saveEntry(t, s.kv, key)
+	saveEntry(t, s.kvExpiry, key)
+}
+
+func (t *memoryTxn) KVGet(key string) (*kv.Value, error) {
+	return t.m.shard(key).liveKV(key), nil
+}
+
+func (t *memoryTxn) KVPut(key string, value *kv.Value, ttl int32) error {
+	s := t.m.shard(key)
+
+	stored := value.Stamped(s.liveKV(key), t.now)
+	stored.Version = atomic.AddUint64(&t.m.kvVersion, 1)
+	expires := kv.Expiry(ttl, t.now)
+
+	t.saveKV(s, key)
+	t.entries = append(t.entries, kvPutEntry(key, stored, expires))
+	s.putKV(key, stored, expires)
+
+	return nil
+}
+
+func (t *memoryTxn) KVDelete(key string) (bool, error) {
+	s := t.m.shard(key)
+
+	existed := s.liveKV(key) != nil
+
+	t.saveKV(s, key)
+	t.entries = append(t.entries, &entry{Op: opKVDelete, Key: key})
+	s.deleteKV(key)
+
+	return existed, nil
+}
+
+func (t *memoryTxn) CounterGet(key string) (int64, error) {
+	return t.m.shard(key).counters[key], nil
+}
+
+func (t *memoryTxn) CounterIncrement(key string, amount int64) (int64, error) {
+	s := t.m.shard(key)
+
+	saveEntry(t, s.counters, key)
+	t.entries = append(t.entries, &entry{Op: opCounterIncr, Key: key, Count: amount})
+	s.counters[key] += amount
+
+	return s.counters[key], nil
+}
+
+func (t *memoryTxn) FlagGet(key string) (bool, error) {
+	return t.m.shard(key).flags[key], nil
+}
+
+func (t *memoryTxn) FlagSet(key string, value bool) error {
+	s := t.m.shard(key)
+
+	saveEntry(t, s.flags, key)
+	t.entries = append(t.entries, &entry{Op: opFlagSet, Key: key, Flag: value})
+	s.flags[key] = value
+
+	return nil
+}
+
+func (t *memoryTxn) SetGet(set string) ([]string, bool, error) {
+	st, ok := t.m.shard(set).sets[set]
+	if !ok {
+		return nil, false, nil
+	}
+
+	return st.Get(), true, nil
+}
+
+func (t *memoryTxn) SetAdd(set, item string) ([]string, error) {
+	items, _ := t.modifySet(set, opSetAdd, item, true, (*data.Set).Add)
+
+	return items, nil
+}
+
+func (t *memoryTxn) SetRemove(set, item string) ([]string, bool, error) {
+	items, ok := t.modifySet(set, opSetRemove, item, false, (*data.Set).Remove)
+
+	return items, ok, nil
+}
+
+// Applies fn to a copy of the set, so that the original can be put back, and reports whether the set existed. A set
+// that doesn't exist is created if create is true and otherwise left alone.
+func (t *memoryTxn) modifySet(set, op, item string, create bool, fn func(st *data.Set, item string)) ([]string, bool) {
+	s := t.m.shard(set)
+
+	current, ok := s.sets[set]
+	if !ok && !create {
+		return nil, false
+	}
+
+	st := data.NewSet()
+	if ok {
+		st = current.Copy()
+	}
+
+	fn(st, item)
+
+	saveEntry(t, s.sets, set)
+	t.entries = append(t.entries, &entry{Op: op, Key: set, Items: []string{item}})
+	s.sets[set] = st
+
+	return st.Get(), ok
+}
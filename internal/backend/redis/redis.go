@@ -1,25 +1,40 @@
 package redis
 
 import (
-	"github.com/purpledb/purple/internal/data"
+	"encoding/json"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/purpledb/purple/internal/services/flag"
+	"github.com/purpledb/purple/internal/data"
+
+	"github.com/purpledb/purple/services/flag"
 
-	"github.com/purpledb/purple/internal/services/cache"
-	"github.com/purpledb/purple/internal/services/counter"
-	"github.com/purpledb/purple/internal/services/kv"
-	"github.com/purpledb/purple/internal/services/set"
+	"github.com/purpledb/purple/services/cache"
+	"github.com/purpledb/purple/services/counter"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
 
 	"github.com/go-redis/redis"
 	"github.com/purpledb/purple"
 )
 
-const defaultUrl = "localhost:6379"
+const (
+	defaultUrl    = "localhost:6379"
+	DefaultPrefix = "purple"
+
+	// The number of keys fetched per SCAN call and deleted per DEL call when flushing
+	scanBatchSize = 1000
+)
 
+// Redis stores all services in a single Redis database. Each service's keys are namespaced using a configurable
+// prefix, e.g. "purple:kv:<key>", so that purple can share a Redis instance with other applications. Only standalone
+// Redis servers (and proxies that present themselves as one) are supported: the backend connects through a single
+// client, lists and flushes keys with SCAN on that one node, and runs scripts, transactions, and set operations that
+// touch several keys at once, none of which work across the slots of a Redis Cluster.
 type Redis struct {
-	cache, counters, flags, kv, sets *redis.Client
+	cl     *redis.Client
+	prefix string
 }
 
 func (r *Redis) Name() string {
@@ -34,53 +49,32 @@ var (
 	_ set.Set         = (*Redis)(nil)
 )
 
-func NewRedisBackend(addr string) (*Redis, error) {
+func NewRedisBackend(addr, prefix string) (*Redis, error) {
 	if addr == "" {
 		addr = defaultUrl
 	}
 
-	cacheCl, err := newRedisClient(addr, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	counterCl, err := newRedisClient(addr, 1)
-	if err != nil {
-		return nil, err
-	}
-
-	flagCl, err := newRedisClient(addr, 2)
-	if err != nil {
-		return nil, err
-	}
-
-	kvCl, err := newRedisClient(addr, 3)
-	if err != nil {
-		return nil, err
+	if prefix == "" {
+		prefix = DefaultPrefix
 	}
 
-	setCl, err := newRedisClient(addr, 4)
+	cl, err := newRedisClient(addr)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Redis{
-		cache:    cacheCl,
-		counters: counterCl,
-		flags:    flagCl,
-		kv:       kvCl,
-		sets:     setCl,
+		cl:     cl,
+		prefix: prefix,
 	}, nil
 }
 
-func newRedisClient(addr string, i int) (*redis.Client, error) {
+func newRedisClient(addr string) (*redis.Client, error) {
 	opts, err := redis.ParseURL(addr)
 	if err != nil {
 		return nil, err
 	}
 
-	opts.DB = i
-
 	cl := redis.NewClient(opts)
 
 	if err := cl.Ping().Err(); err != nil {
@@ -90,34 +84,128 @@ func newRedisClient(addr string, i int) (*redis.Client, error) {
 	return cl, nil
 }
 
-// Service methods
-func (r *Redis) Close() error {
-	for _, db := range []*redis.Client{
-		r.cache,
-	} {
-		if err := db.Close(); err != nil {
-			return err
+// Key namespacing
+func (r *Redis) key(service, key string) string {
+	return r.prefix + ":" + service + ":" + key
+}
+
+func (r *Redis) cacheKey(key string) string {
+	return r.key("cache", key)
+}
+
+func (r *Redis) counterKey(key string) string {
+	return r.key("counter", key)
+}
+
+func (r *Redis) flagKey(key string) string {
+	return r.key("flag", key)
+}
+
+func (r *Redis) kvKey(key string) string {
+	return r.key("kv", key)
+}
+
+func (r *Redis) setKey(key string) string {
+	return r.key("set", key)
+}
+
+// Escapes glob metacharacters so that a literal string can be used in a SCAN MATCH pattern.
+func escapePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// Lists a service's keys that begin with prefix using SCAN. The cursor is Redis' own SCAN cursor and the limit is passed
+// as the COUNT hint, so keys aren't returned in any particular order and a page may hold somewhat more or fewer keys
+// than the limit. SCAN is repeated until it returns at least one key or the scan is complete.
+func (r *Redis) list(service, prefix, cursor string, limit int) ([]string, string, error) {
+	namespace := r.key(service, "")
+	pattern := escapePattern(namespace+prefix) + "*"
+
+	keys, next, err := scan(cursor, func(c uint64) ([]string, uint64, error) {
+		return r.cl.Scan(c, pattern, int64(data.ListLimit(limit))).Result()
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	for i, k := range keys {
+		keys[i] = strings.TrimPrefix(k, namespace)
+	}
+
+	return keys, next, nil
+}
+
+// Repeats a SCAN-style command from cursor until it returns at least one element or the scan is complete, and returns
+// the elements along with the cursor to continue from, which is empty once the scan is complete.
+func scan(cursor string, fn func(c uint64) ([]string, uint64, error)) ([]string, string, error) {
+	var scanCursor uint64
+
+	if cursor != "" {
+		c, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			return nil, "", purple.ErrInvalidCursor
 		}
+
+		scanCursor = c
 	}
 
-	return nil
+	elems := make([]string, 0)
+
+	for {
+		batch, next, err := fn(scanCursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		elems = append(elems, batch...)
+
+		if next == 0 {
+			return elems, "", nil
+		}
+
+		scanCursor = next
+
+		if len(elems) > 0 {
+			return elems, strconv.FormatUint(scanCursor, 10), nil
+		}
+	}
+}
+
+// Service methods
+func (r *Redis) Close() error {
+	return r.cl.Close()
 }
 
+// Flush deletes purple's own keys (and only those) using SCAN rather than FLUSHALL, which would wipe every database on
+// the server.
 func (r *Redis) Flush() error {
-	for _, db := range []*redis.Client{
-		r.cache,
-	} {
-		if err := db.FlushAll().Err(); err != nil {
+	pattern := escapePattern(r.prefix) + ":*"
+
+	var cursor uint64
+
+	for {
+		keys, next, err := r.cl.Scan(cursor, pattern, scanBatchSize).Result()
+		if err != nil {
 			return err
 		}
-	}
 
-	return nil
+		if len(keys) > 0 {
+			if err := r.cl.Del(keys...).Err(); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+
+		cursor = next
+	}
 }
 
 // Cache operations
 func (r *Redis) CacheGet(key string) (string, error) {
-	s, err := r.cache.Get(key).Result()
+	s, err := r.cl.Get(r.cacheKey(key)).Result()
 
 	if err != nil {
 		if err == redis.Nil {
@@ -131,14 +219,44 @@ func (r *Redis) CacheGet(key string) (string, error) {
 }
 
 func (r *Redis) CacheSet(key, value string, ttl int32) error {
+	if key == "" {
+		return purple.ErrNoKey
+	}
+
+	if value == "" {
+		return purple.ErrNoValue
+	}
+
 	t := time.Duration(ttl) * time.Second
 
-	return r.cache.Set(key, value, t).Err()
+	return r.cl.Set(r.cacheKey(key), value, t).Err()
+}
+
+// Returns the number of seconds until a cache item expires, rounded up, or zero if it never expires.
+func (r *Redis) CacheTTL(key string) (int32, error) {
+	ttl, err := r.cl.PTTL(r.cacheKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// PTTL reports -2 for missing keys and -1 for keys without an expiry
+	switch {
+	case ttl == -2*time.Millisecond:
+		return 0, purple.NotFound(key)
+	case ttl < 0:
+		return 0, nil
+	}
+
+	return int32((ttl + time.Second - 1) / time.Second), nil
+}
+
+func (r *Redis) CacheList(prefix, cursor string, limit int) ([]string, string, error) {
+	return r.list("cache", prefix, cursor, limit)
 }
 
 // Counter operations
 func (r *Redis) CounterGet(key string) (int64, error) {
-	i, err := r.counters.Get(key).Int64()
+	i, err := r.cl.Get(r.counterKey(key)).Int64()
 
 	if err == redis.Nil {
 		return 0, nil
@@ -148,15 +266,22 @@ func (r *Redis) CounterGet(key string) (int64, error) {
 }
 
 func (r *Redis) CounterIncrement(key string, increment int64) (int64, error) {
-	return r.counters.IncrBy(key, increment).Result()
+	return r.cl.IncrBy(r.counterKey(key), increment).Result()
+}
+
+func (r *Redis) CounterList(prefix, cursor string, limit int) ([]string, string, error) {
+	return r.list("counter", prefix, cursor, limit)
 }
 
 // Flag operations
 func (r *Redis) FlagGet(key string) (bool, error) {
-	s := r.flags.Get(key).String()
-
-	if s == "" {
-		return false, nil
+	s, err := r.cl.Get(r.flagKey(key)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return false, nil
+		} else {
+			return false, err
+		}
 	}
 
 	val, err := strconv.ParseBool(s)
@@ -170,36 +295,352 @@ func (r *Redis) FlagGet(key string) (bool, error) {
 func (r *Redis) FlagSet(key string, value bool) error {
 	val := strconv.FormatBool(value)
 
-	return r.flags.Set(key, val, 0).Err()
+	return r.cl.Set(r.flagKey(key), val, 0).Err()
 }
 
-// KV operations
+func (r *Redis) FlagList(prefix, cursor string, limit int) ([]string, string, error) {
+	return r.list("flag", prefix, cursor, limit)
+}
+
+// KV values are stored as hashes that hold the content, the version, the content type, the metadata as a JSON object,
+// and the creation and update times in Unix nanoseconds. Keys expire through Redis' own EXPIRE. Writes run as Lua
+// scripts so that checking the current version and writing happen atomically, and each script only touches its own
+// key.
+const (
+	kvPutAny       = "any"
+	kvPutIfAbsent  = "absent"
+	kvPutIfVersion = "version"
+)
+
+var kvFields = []string{"content", "version", "content_type", "metadata", "created", "updated"}
+
+// Computes the next version of the KV hash KEYS[1] without touching any other key. The version is one more than the
+// current one, but at least the server's clock in microseconds, so that a key that's deleted and recreated never
+// reuses a version that a client may still hold. TIME makes the script non-deterministic, which is why its effects
+// rather than the script itself are replicated.
+const kvNextVersion = `
+redis.replicate_commands()
+local function nextVersion()
+	local now = redis.call('TIME')
+	local floor = tonumber(now[1]) * 1000000 + tonumber(now[2])
+	local next = tonumber(redis.call('HGET', KEYS[1], 'version') or '0') + 1
+	return string.format('%d', math.max(next, floor))
+end
+`
+
+var (
+	// KEYS: the KV key; ARGV: the content, the put mode, the expected version, the content type, the metadata, the
+	// current time, and the TTL. The creation time is only set if the key doesn't exist.
+	kvPutScript = redis.NewScript(kvNextVersion + `
+local current = redis.call('HGET', KEYS[1], 'version')
+if (ARGV[2] == 'absent' and current) or (ARGV[2] == 'version' and current ~= ARGV[3]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'content', ARGV[1], 'version', nextVersion(), 'content_type', ARGV[4], 'metadata', ARGV[5], 'updated', ARGV[6])
+redis.call('HSETNX', KEYS[1], 'created', ARGV[6])
+if tonumber(ARGV[7]) > 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[7])
+else
+	redis.call('PERSIST', KEYS[1])
+end
+return 1
+`)
+
+	// KEYS: the KV key; ARGV: the TTL, or 0 to remove the expiry
+	kvExpireScript = redis.NewScript(kvNextVersion + `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'version', nextVersion())
+if tonumber(ARGV[1]) > 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[1])
+else
+	redis.call('PERSIST', KEYS[1])
+end
+return 1
+`)
+
+	// KEYS: the KV key; ARGV: the expected version
+	kvDeleteScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'version') ~= ARGV[1] then
+	return 0
+end
+return redis.call('DEL', KEYS[1])
+`)
+)
+
 func (r *Redis) KVGet(key string) (*kv.Value, error) {
-	s, err := r.kv.Get(key).Result()
+	fields, err := r.cl.HMGet(r.kvKey(key), kvFields...).Result()
 	if err != nil {
-		if err == redis.Nil {
-			return nil, purple.NotFound(key)
-		} else {
+		return nil, err
+	}
+
+	value, err := kvValue(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, purple.NotFound(key)
+	}
+
+	return value, nil
+}
+
+// Decodes the kvFields of a KV hash, or returns nil if the key doesn't exist.
+func kvValue(fields []interface{}) (*kv.Value, error) {
+	content, ok := fields[0].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	strs := make([]string, len(fields))
+	for i, f := range fields {
+		strs[i], _ = f.(string)
+	}
+
+	value := &kv.Value{
+		Content:     []byte(content),
+		ContentType: strs[2],
+	}
+
+	var err error
+
+	if value.Version, err = strconv.ParseUint(strs[1], 10, 64); err != nil {
+		return nil, err
+	}
+
+	if strs[3] != "" {
+		if err := json.Unmarshal([]byte(strs[3]), &value.Metadata); err != nil {
 			return nil, err
 		}
 	}
 
-	return &kv.Value{
-		Content: []byte(s),
-	}, nil
+	for i, t := range []*time.Time{&value.Created, &value.Updated} {
+		ns, err := strconv.ParseInt(strs[4+i], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		*t = kv.FromUnixNano(ns)
+	}
+
+	return value, nil
+}
+
+func (r *Redis) KVPut(key string, value *kv.Value, ttl int32) error {
+	return r.putKV(key, value, ttl, kvPutAny, 0)
+}
+
+func (r *Redis) KVPutIfVersion(key string, value *kv.Value, version uint64, ttl int32) error {
+	return r.putKV(key, value, ttl, kvPutIfVersion, version)
+}
+
+func (r *Redis) KVPutIfAbsent(key string, value *kv.Value, ttl int32) error {
+	return r.putKV(key, value, ttl, kvPutIfAbsent, 0)
 }
 
-func (r *Redis) KVPut(key string, value *kv.Value) error {
-	return r.kv.Set(key, value.Content, 0).Err()
+func (r *Redis) putKV(key string, value *kv.Value, ttl int32, mode string, version uint64) error {
+	if err := kv.CheckTTL(ttl); err != nil {
+		return err
+	}
+
+	args, err := kvPutArgs(value, ttl, mode, version, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ok, err := kvPutScript.Run(r.cl, []string{r.kvKey(key)}, args...).Int()
+	if err != nil {
+		return err
+	}
+
+	if ok == 0 {
+		return purple.Conflict(key)
+	}
+
+	return nil
+}
+
+// Returns the ARGV of kvPutScript.
+func kvPutArgs(value *kv.Value, ttl int32, mode string, version uint64, now time.Time) ([]interface{}, error) {
+	// The script decides whether the creation time carries over
+	stored := value.Stamped(nil, now)
+
+	var metadata []byte
+
+	if stored.Metadata != nil {
+		var err error
+
+		if metadata, err = json.Marshal(stored.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return []interface{}{
+		stored.Content,
+		mode,
+		strconv.FormatUint(version, 10),
+		stored.ContentType,
+		metadata,
+		strconv.FormatInt(kv.UnixNano(stored.Updated), 10),
+		ttl,
+	}, nil
 }
 
 func (r *Redis) KVDelete(key string) error {
-	return r.kv.Del(key).Err()
+	return r.cl.Del(r.kvKey(key)).Err()
+}
+
+func (r *Redis) KVDeleteIfVersion(key string, version uint64) error {
+	ok, err := kvDeleteScript.Run(r.cl, []string{r.kvKey(key)}, strconv.FormatUint(version, 10)).Int()
+	if err != nil {
+		return err
+	}
+
+	if ok == 0 {
+		return purple.Conflict(key)
+	}
+
+	return nil
+}
+
+func (r *Redis) KVTTL(key string) (int32, error) {
+	ttl, err := r.cl.PTTL(r.kvKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// PTTL reports -2 for missing keys and -1 for keys without an expiry
+	switch {
+	case ttl == -2*time.Millisecond:
+		return 0, purple.NotFound(key)
+	case ttl < 0:
+		return kv.NoTTL, nil
+	}
+
+	return int32((ttl + time.Second - 1) / time.Second), nil
+}
+
+func (r *Redis) KVExpire(key string, ttl int32) error {
+	if err := kv.CheckExpireTTL(ttl); err != nil {
+		return err
+	}
+
+	return r.expireKV(key, ttl)
+}
+
+func (r *Redis) KVPersist(key string) error {
+	return r.expireKV(key, 0)
+}
+
+func (r *Redis) expireKV(key string, ttl int32) error {
+	ok, err := kvExpireScript.Run(r.cl, []string{r.kvKey(key)}, ttl).Int()
+	if err != nil {
+		return err
+	}
+
+	if ok == 0 {
+		return purple.NotFound(key)
+	}
+
+	return nil
+}
+
+func (r *Redis) KVList(prefix, cursor string, limit int) ([]string, string, error) {
+	return r.list("kv", prefix, cursor, limit)
+}
+
+// The batch operations each send their commands in a single MULTI/EXEC transaction, which takes one round trip and is
+// applied atomically.
+func (r *Redis) KVGetMany(keys []string) ([]*kv.Value, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.SliceCmd, len(keys))
+
+	if _, err := r.cl.TxPipelined(func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.HMGet(r.kvKey(key), kvFields...)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	values := make([]*kv.Value, len(keys))
+
+	for i, cmd := range cmds {
+		var err error
+
+		if values[i], err = kvValue(cmd.Val()); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func (r *Redis) KVPutMany(entries []*kv.Entry) error {
+	if err := kv.CheckEntries(entries); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	args := make([][]interface{}, len(entries))
+
+	for i, e := range entries {
+		var err error
+
+		if args[i], err = kvPutArgs(e.Value, e.TTL, kvPutAny, 0, now); err != nil {
+			return err
+		}
+	}
+
+	// EVALSHA could fail inside the transaction if the script isn't cached yet, so the script is sent in full
+	_, err := r.cl.TxPipelined(func(pipe redis.Pipeliner) error {
+		for i, e := range entries {
+			kvPutScript.Eval(pipe, []string{r.kvKey(e.Key)}, args[i]...)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (r *Redis) KVDeleteMany(keys []string) ([]bool, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.IntCmd, len(keys))
+
+	if _, err := r.cl.TxPipelined(func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Del(r.kvKey(key))
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	existed := make([]bool, len(keys))
+
+	for i, cmd := range cmds {
+		existed[i] = cmd.Val() > 0
+	}
+
+	return existed, nil
 }
 
 // Set operations
 func (r *Redis) SetGet(set string) ([]string, error) {
-	s, err := r.sets.SMembers(set).Result()
+	s, err := r.cl.SMembers(r.setKey(set)).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -208,17 +649,164 @@ func (r *Redis) SetGet(set string) ([]string, error) {
 }
 
 func (r *Redis) SetAdd(set, item string) ([]string, error) {
-	if err := r.sets.SAdd(set, item).Err(); err != nil {
+	k := r.setKey(set)
+
+	if err := r.cl.SAdd(k, item).Err(); err != nil {
 		return nil, err
 	}
 
-	return r.sets.SMembers(set).Result()
+	return r.members(k)
 }
 
 func (r *Redis) SetRemove(set, item string) ([]string, error) {
-	if err := r.sets.SRem(set, item).Err(); err != nil {
+	k := r.setKey(set)
+
+	if err := r.cl.SRem(k, item).Err(); err != nil {
+		return nil, err
+	}
+
+	return r.members(k)
+}
+
+// Pages are read with SSCAN, in the same way that keys are listed.
+func (r *Redis) SetGetPage(key, cursor string, limit int) ([]string, string, error) {
+	k := r.setKey(key)
+
+	return scan(cursor, func(c uint64) ([]string, uint64, error) {
+		return r.cl.SScan(k, c, "", int64(data.ListLimit(limit))).Result()
+	})
+}
+
+func (r *Redis) SetAddSummary(key, item string) (*set.Summary, error) {
+	return r.summarize(key, func(pipe redis.Pipeliner, k string) *redis.IntCmd {
+		return pipe.SAdd(k, item)
+	})
+}
+
+func (r *Redis) SetRemoveSummary(key, item string) (*set.Summary, error) {
+	return r.summarize(key, func(pipe redis.Pipeliner, k string) *redis.IntCmd {
+		return pipe.SRem(k, item)
+	})
+}
+
+// Sends the command that adds or removes the item, which returns the number of items it changed, and SCARD in a single
+// MULTI/EXEC transaction.
+func (r *Redis) summarize(key string, change func(pipe redis.Pipeliner, k string) *redis.IntCmd) (*set.Summary, error) {
+	k := r.setKey(key)
+
+	var changed, size *redis.IntCmd
+
+	if _, err := r.cl.TxPipelined(func(pipe redis.Pipeliner) error {
+		changed = change(pipe, k)
+		size = pipe.SCard(k)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &set.Summary{Changed: changed.Val() > 0, Size: int(size.Val())}, nil
+}
+
+func (r *Redis) SetRandom(key string, count int) ([]string, error) {
+	items, err := r.cl.SRandMemberN(r.setKey(key), int64(count)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.NonNilSet(items), nil
+}
+
+func (r *Redis) SetPop(key string, count int) ([]string, error) {
+	if err := set.CheckPopCount(count); err != nil {
+		return nil, err
+	}
+
+	items, err := r.cl.SPopN(r.setKey(key), int64(count)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	return data.NonNilSet(items), nil
+}
+
+func (r *Redis) SetContains(set, item string) (bool, error) {
+	return r.cl.SIsMember(r.setKey(set), item).Result()
+}
+
+func (r *Redis) SetSize(set string) (int, error) {
+	size, err := r.cl.SCard(r.setKey(set)).Result()
+
+	return int(size), err
+}
+
+func (r *Redis) SetUnion(sets ...string) ([]string, error) {
+	return r.combineSets(sets, r.cl.SUnion)
+}
+
+func (r *Redis) SetIntersect(sets ...string) ([]string, error) {
+	return r.combineSets(sets, r.cl.SInter)
+}
+
+func (r *Redis) SetDiff(sets ...string) ([]string, error) {
+	return r.combineSets(sets, r.cl.SDiff)
+}
+
+func (r *Redis) SetUnionStore(dest string, sets ...string) (int, error) {
+	return r.storeSets(dest, sets, r.cl.SUnionStore)
+}
+
+func (r *Redis) SetIntersectStore(dest string, sets ...string) (int, error) {
+	return r.storeSets(dest, sets, r.cl.SInterStore)
+}
+
+func (r *Redis) SetDiffStore(dest string, sets ...string) (int, error) {
+	return r.storeSets(dest, sets, r.cl.SDiffStore)
+}
+
+// Runs one of SUNION, SINTER, or SDIFF on the sets.
+func (r *Redis) combineSets(sets []string, cmd func(keys ...string) *redis.StringSliceCmd) ([]string, error) {
+	if err := set.CheckSets(sets); err != nil {
+		return nil, err
+	}
+
+	items, err := cmd(r.setKeys(sets)...).Result()
+	if err != nil {
 		return nil, err
 	}
 
-	return r.sets.SMembers(set).Result()
+	return data.NonNilSet(items), nil
+}
+
+// Runs one of SUNIONSTORE, SINTERSTORE, or SDIFFSTORE on the sets.
+func (r *Redis) storeSets(dest string, sets []string, cmd func(dest string, keys ...string) *redis.IntCmd) (int, error) {
+	if err := set.CheckSets(sets, dest); err != nil {
+		return 0, err
+	}
+
+	size, err := cmd(r.setKey(dest), r.setKeys(sets)...).Result()
+
+	return int(size), err
+}
+
+func (r *Redis) setKeys(sets []string) []string {
+	keys := make([]string, len(sets))
+
+	for i, s := range sets {
+		keys[i] = r.setKey(s)
+	}
+
+	return keys
+}
+
+func (r *Redis) SetList(prefix, cursor string, limit int) ([]string, string, error) {
+	return r.list("set", prefix, cursor, limit)
+}
+
+func (r *Redis) members(k string) ([]string, error) {
+	s, err := r.cl.SMembers(k).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.NonNilSet(s), nil
 }
@@ -0,0 +1,354 @@
+package redis
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/data"
+	"github.com/purpledb/purple/services/txn"
+)
+
+// How many times a transaction is retried when another client writes one of its keys between the precheck and EXEC
+// before it fails with a conflict.
+const maxTxnAttempts = 16
+
+// Txn watches the keys of all operations with WATCH, checks that none of their commands can fail (see precheck), and
+// sends them in a single MULTI/EXEC transaction, so they're applied atomically and no other client's commands run in
+// between. Redis doesn't roll back a MULTI/EXEC block whose commands fail at runtime, so failures the precheck can't
+// foresee, such as Redis running out of memory, can still leave a transaction partially applied.
+func (r *Redis) Txn(ops []*txn.Op) ([]*txn.Result, error) {
+	if err := txn.Validate(ops); err != nil {
+		return nil, err
+	}
+
+	return r.run(nil, ops)
+}
+
+// TxnBegin starts a session that commits by watching the keys it read with WATCH, reading them again, and sending its
+// writes in a MULTI/EXEC transaction, which Redis refuses to run if any of the watched keys was written in between.
+func (r *Redis) TxnBegin() (txn.Session, error) {
+	return txn.NewSession(r.Txn, r.run), nil
+}
+
+// Applies the operations of a transaction or, for a session, checks that its reads are unchanged and then applies its
+// writes. The keys of both are watched, so if any of them is written by another client before EXEC, the transaction is
+// aborted and tried again, and a write to one of the keys read shows up as a conflict on the next attempt.
+func (r *Redis) run(reads []*txn.Read, ops []*txn.Op) ([]*txn.Result, error) {
+	keys := make([]string, 0, len(reads)+len(ops))
+
+	for _, rd := range reads {
+		keys = append(keys, r.opKey(rd.Op))
+	}
+
+	for _, op := range ops {
+		keys = append(keys, r.opKey(op))
+	}
+
+	for attempt := 0; attempt < maxTxnAttempts; attempt++ {
+		var results []*txn.Result
+
+		err := r.cl.Watch(func(tx *redis.Tx) error {
+			// Commands sent on the connection outside of MULTI run right away
+			if len(reads) > 0 {
+				current, err := r.apply(tx, txn.ReadOps(reads), nil)
+				if err != nil {
+					return err
+				}
+
+				if err := txn.Check(reads, current); err != nil {
+					return err
+				}
+			}
+
+			if err := r.precheck(tx, ops); err != nil {
+				return err
+			}
+
+			pipe := tx.TxPipeline()
+			defer pipe.Close()
+
+			var err error
+
+			results, err = r.apply(pipe, ops, pipe.Exec)
+
+			return err
+		}, keys...)
+		if err != redis.TxFailedErr {
+			if err != nil {
+				return nil, err
+			}
+
+			return results, nil
+		}
+	}
+
+	return nil, purple.Conflict("transaction")
+}
+
+// Checks, before anything is queued, the current state of the keys for everything that would make a command of the
+// operations fail at runtime: a key holding another type than the operation expects, a counter that isn't an integer
+// or that an increment would overflow, and a KV value whose fields can't be parsed. The keys are watched, so the
+// transaction doesn't run if any of them changes after it's been checked.
+func (r *Redis) precheck(tx *redis.Tx, ops []*txn.Op) error {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	pipe := tx.Pipeline()
+	defer pipe.Close()
+
+	types := make(map[string]*redis.StatusCmd)
+	counters := make(map[string]*redis.StringCmd)
+	values := make(map[string]*redis.SliceCmd)
+
+	for _, op := range ops {
+		k := r.opKey(op)
+
+		if _, ok := types[k]; !ok {
+			types[k] = pipe.Type(k)
+		}
+
+		switch op.Kind {
+		case txn.CounterGet, txn.CounterIncr:
+			if _, ok := counters[k]; !ok {
+				counters[k] = pipe.Get(k)
+			}
+		case txn.KVGet, txn.KVPut, txn.KVDelete:
+			if _, ok := values[k]; !ok {
+				values[k] = pipe.HMGet(k, kvFields[1:]...)
+			}
+		}
+	}
+
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		return err
+	}
+
+	// The running value of each counter, to catch increments that overflow it
+	counts := make(map[string]int64, len(counters))
+
+	for k, cmd := range counters {
+		if cmd.Err() == redis.Nil {
+			continue
+		}
+
+		n, err := cmd.Int64()
+		if err != nil {
+			return purple.ErrRedisCounterInvalid
+		}
+
+		counts[k] = n
+	}
+
+	for _, op := range ops {
+		k := r.opKey(op)
+
+		if t := types[k].Val(); t != "none" && t != redisType(op.Kind) {
+			return purple.ErrRedisWrongType
+		}
+
+		switch op.Kind {
+		case txn.CounterIncr:
+			n := counts[k]
+
+			if (op.Amount > 0 && n > math.MaxInt64-op.Amount) || (op.Amount < 0 && n < math.MinInt64-op.Amount) {
+				return purple.ErrRedisCounterInvalid
+			}
+
+			counts[k] = n + op.Amount
+		case txn.KVGet, txn.KVPut, txn.KVDelete:
+			if types[k].Val() != "hash" {
+				continue
+			}
+
+			// The content can be any string, so it isn't fetched
+			if _, err := kvValue(append([]interface{}{""}, values[k].Val()...)); err != nil {
+				return purple.ErrInvalidKVValue
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the Redis type of the keys that an operation applies to.
+func redisType(kind string) string {
+	switch kind {
+	case txn.KVGet, txn.KVPut, txn.KVDelete:
+		return "hash"
+	case txn.SetGet, txn.SetAdd, txn.SetRemove:
+		return "set"
+	}
+
+	return "string"
+}
+
+// Queues the commands of the operations, sends them using exec unless it's nil, and collects the results.
+func (r *Redis) apply(c redis.Cmdable, ops []*txn.Op, exec func() ([]redis.Cmder, error)) ([]*txn.Result, error) {
+	results := make([]*txn.Result, len(ops))
+
+	if len(ops) == 0 {
+		return results, nil
+	}
+
+	now := time.Now()
+
+	collect := make([]func(res *txn.Result) error, len(ops))
+
+	for i, op := range ops {
+		var err error
+
+		if collect[i], err = r.queue(c, op, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if exec != nil {
+		// A GET of a missing counter or flag is reported as redis.Nil, which isn't a failure here
+		if _, err := exec(); err != nil && err != redis.Nil {
+			return nil, err
+		}
+	}
+
+	for i := range ops {
+		results[i] = &txn.Result{}
+
+		if err := collect[i](results[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// Returns the Redis key that an operation applies to.
+func (r *Redis) opKey(op *txn.Op) string {
+	switch op.Kind {
+	case txn.KVGet, txn.KVPut, txn.KVDelete:
+		return r.kvKey(op.Key)
+	case txn.CounterGet, txn.CounterIncr:
+		return r.counterKey(op.Key)
+	case txn.FlagGet, txn.FlagSet:
+		return r.flagKey(op.Key)
+	}
+
+	return r.setKey(op.Key)
+}
+
+// Queues the commands of an operation and returns a function that turns their replies into the operation's result once
+// the transaction has run.
+func (r *Redis) queue(pipe redis.Cmdable, op *txn.Op, now time.Time) (func(res *txn.Result) error, error) {
+	switch op.Kind {
+	case txn.KVGet:
+		cmd := pipe.HMGet(r.kvKey(op.Key), kvFields...)
+
+		return func(res *txn.Result) (err error) {
+			res.Value, err = kvValue(cmd.Val())
+			res.Found = res.Value != nil
+			return
+		}, nil
+	case txn.KVPut:
+		args, err := kvPutArgs(op.Value, op.TTL, kvPutAny, 0, now)
+		if err != nil {
+			return nil, err
+		}
+
+		cmd := kvPutScript.Eval(pipe, []string{r.kvKey(op.Key)}, args...)
+
+		return func(*txn.Result) error {
+			return cmd.Err()
+		}, nil
+	case txn.KVDelete:
+		cmd := pipe.Del(r.kvKey(op.Key))
+
+		return func(res *txn.Result) error {
+			res.Found = cmd.Val() > 0
+			return cmd.Err()
+		}, nil
+	case txn.CounterGet:
+		cmd := pipe.Get(r.counterKey(op.Key))
+
+		return func(res *txn.Result) (err error) {
+			if res.Count, err = cmd.Int64(); err == redis.Nil {
+				err = nil
+			}
+			return
+		}, nil
+	case txn.CounterIncr:
+		cmd := pipe.IncrBy(r.counterKey(op.Key), op.Amount)
+
+		return func(res *txn.Result) (err error) {
+			res.Count, err = cmd.Result()
+			return
+		}, nil
+	case txn.FlagGet:
+		cmd := pipe.Get(r.flagKey(op.Key))
+
+		return func(res *txn.Result) error {
+			s, err := cmd.Result()
+			if err != nil {
+				if err == redis.Nil {
+					return nil
+				}
+
+				return err
+			}
+
+			// Like FlagGet, unparseable values read as false
+			res.Flag, _ = strconv.ParseBool(s)
+
+			return nil
+		}, nil
+	case txn.FlagSet:
+		cmd := pipe.Set(r.flagKey(op.Key), strconv.FormatBool(op.Flag), 0)
+
+		return func(*txn.Result) error {
+			return cmd.Err()
+		}, nil
+	case txn.SetGet:
+		members := pipe.SMembers(r.setKey(op.Key))
+
+		// Redis deletes sets once they're empty, so a set exists as long as it has members
+		return func(res *txn.Result) error {
+			res.Items = data.NonNilSet(members.Val())
+			res.Found = len(res.Items) > 0
+			return members.Err()
+		}, nil
+	case txn.SetAdd:
+		k := r.setKey(op.Key)
+		add := pipe.SAdd(k, op.Item)
+		members := pipe.SMembers(k)
+
+		return func(res *txn.Result) error {
+			res.Items = data.NonNilSet(members.Val())
+			return firstErr(add.Err(), members.Err())
+		}, nil
+	case txn.SetRemove:
+		k := r.setKey(op.Key)
+		exists := pipe.Exists(k)
+		remove := pipe.SRem(k, op.Item)
+		members := pipe.SMembers(k)
+
+		return func(res *txn.Result) error {
+			res.Items = data.NonNilSet(members.Val())
+			res.Found = exists.Val() > 0
+			return firstErr(exists.Err(), remove.Err(), members.Err())
+		}, nil
+	}
+
+	return nil, purple.ErrUnknownTxnOp
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/backend/memory"
+	"github.com/purpledb/purple/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal(services.Names(), []string{"bolt", "disk", "memory", "redis", "sqlite"})
+
+	services.Register("custom", func(cfg *purple.ServerConfig) (Service, error) {
+		is.Equal(cfg.Section("custom"), map[string]string{"setting": "value"})
+		return memory.NewMemoryBackend(), nil
+	})
+	is.Contains(services.Names(), "custom")
+	is.NoError((&purple.ServerConfig{Port: 1234, Backend: "custom"}).Validate())
+
+	is.Panics(func() {
+		services.Register("custom", func(_ *purple.ServerConfig) (Service, error) {
+			return nil, nil
+		})
+	})
+
+	bk, err := NewBackend(&purple.ServerConfig{
+		Backend: "custom",
+		Options: map[string]string{"custom.setting": "value"},
+	})
+	is.NoError(err)
+	is.NotNil(bk)
+
+	bk, err = NewBackend(&purple.ServerConfig{Backend: "does-not-exist"})
+	is.Equal(err, purple.ErrBackendNotRecognized)
+	is.Nil(bk)
+}
@@ -0,0 +1,966 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/purpledb/purple/internal/util"
+	"github.com/purpledb/purple/services/flag"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/data"
+	"github.com/purpledb/purple/services/cache"
+	"github.com/purpledb/purple/services/counter"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
+
+	_ "modernc.org/sqlite"
+)
+
+const defaultCleanupInterval = time.Minute
+
+// Each service gets its own table. Set members are stored one row per member, with the rowid preserving insertion
+// order. KV versions are taken from a row of the sequences table, which isn't flushed so that versions never repeat. KV
+// metadata is stored as a JSON object and timestamps in Unix nanoseconds. Expiry times of cache items and KV keys are in
+// Unix milliseconds; KV keys that don't expire have a NULL expiry.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS cache (
+		key        TEXT PRIMARY KEY,
+		value      TEXT NOT NULL,
+		expires_at INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS cache_expires_at ON cache (expires_at)`,
+	`CREATE TABLE IF NOT EXISTS counters (
+		key   TEXT PRIMARY KEY,
+		value INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS flags (
+		key   TEXT PRIMARY KEY,
+		value INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS kv (
+		key          TEXT PRIMARY KEY,
+		content      BLOB NOT NULL,
+		version      INTEGER NOT NULL,
+		content_type TEXT NOT NULL,
+		metadata     TEXT,
+		created      INTEGER NOT NULL,
+		updated      INTEGER NOT NULL,
+		expires_at   INTEGER
+	)`,
+	`CREATE INDEX IF NOT EXISTS kv_expires_at ON kv (expires_at)`,
+	`CREATE TABLE IF NOT EXISTS sequences (
+		name  TEXT PRIMARY KEY,
+		value INTEGER NOT NULL
+	)`,
+	`INSERT OR IGNORE INTO sequences (name, value) VALUES ('kv', 0)`,
+	`CREATE TABLE IF NOT EXISTS set_members (
+		name TEXT NOT NULL,
+		item TEXT NOT NULL,
+		UNIQUE (name, item)
+	)`,
+}
+
+var tables = []string{"cache", "counters", "flags", "kv", "set_members"}
+
+// Sqlite stores all services in a single SQLite database file.
+type Sqlite struct {
+	db   *sql.DB
+	done chan struct{}
+}
+
+func (s *Sqlite) Name() string {
+	return "sqlite"
+}
+
+var (
+	_ cache.Cache     = (*Sqlite)(nil)
+	_ counter.Counter = (*Sqlite)(nil)
+	_ flag.Flag       = (*Sqlite)(nil)
+	_ kv.KV           = (*Sqlite)(nil)
+	_ set.Set         = (*Sqlite)(nil)
+)
+
+func NewSqliteBackend(cfg *purple.SqliteConfig) (*Sqlite, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	path, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := util.MkDirIfNotExists(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+
+	// Transactions take the write lock up front (rather than upgrading from a read lock, which can deadlock) and wait
+	// for other writers instead of failing immediately. SQLite's busy handler polls rather than queueing waiters, so
+	// the timeout leaves room for a writer to lose several rounds under heavy write contention.
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(30000)&_txlock=immediate", path)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// The journal mode is stored in the database file, so it only needs to be set once rather than on every connection
+	for _, stmt := range append([]string{`PRAGMA journal_mode = WAL`}, schema...) {
+		if _, err := db.Exec(stmt); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	s := &Sqlite{
+		db:   db,
+		done: make(chan struct{}),
+	}
+
+	interval := cfg.CleanupInterval
+	if interval == 0 {
+		interval = defaultCleanupInterval
+	}
+
+	go s.cleanup(interval)
+
+	return s, nil
+}
+
+// Periodically deletes expired cache entries, which are otherwise only filtered out when read.
+func (s *Sqlite) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n := now()
+			_, _ = s.db.Exec(`DELETE FROM cache WHERE expires_at <= ?`, n)
+			_, _ = s.db.Exec(`DELETE FROM kv WHERE expires_at <= ?`, n)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func now() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+// Runs fn inside a transaction, which is committed if fn succeeds and rolled back otherwise.
+func (s *Sqlite) txn(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Service methods
+func (s *Sqlite) Close() error {
+	close(s.done)
+
+	return s.db.Close()
+}
+
+func (s *Sqlite) Flush() error {
+	return s.txn(func(tx *sql.Tx) error {
+		for _, table := range tables {
+			if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Cache
+func (s *Sqlite) CacheGet(key string) (string, error) {
+	var value string
+
+	if err := s.db.QueryRow(`SELECT value FROM cache WHERE key = ? AND expires_at > ?`, key, now()).Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return "", purple.NotFound(key)
+		} else {
+			return "", err
+		}
+	}
+
+	return value, nil
+}
+
+func (s *Sqlite) CacheSet(key, value string, ttl int32) error {
+	if key == "" {
+		return purple.ErrNoKey
+	}
+
+	if value == "" {
+		return purple.ErrNoValue
+	}
+
+	if ttl == 0 {
+		ttl = cache.DefaultTtl
+	}
+
+	expiresAt := now() + int64(ttl)*1000
+
+	_, err := s.db.Exec(`INSERT INTO cache (key, value, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`, key, value, expiresAt)
+
+	return err
+}
+
+// Returns the number of seconds until a cache entry expires, rounded up.
+func (s *Sqlite) CacheTTL(key string) (int32, error) {
+	var expiresAt int64
+
+	if err := s.db.QueryRow(`SELECT expires_at FROM cache WHERE key = ? AND expires_at > ?`, key, now()).Scan(&expiresAt); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, purple.NotFound(key)
+		} else {
+			return 0, err
+		}
+	}
+
+	return int32((expiresAt - now() + 999) / 1000), nil
+}
+
+func (s *Sqlite) CacheList(prefix, cursor string, limit int) ([]string, string, error) {
+	return s.list(`SELECT key FROM cache WHERE expires_at > ? AND key > ? AND substr(key, 1, length(?)) = ?
+		ORDER BY key LIMIT ?`, prefix, cursor, limit, now())
+}
+
+// Counter
+func (s *Sqlite) CounterGet(key string) (int64, error) {
+	var value int64
+
+	if err := s.db.QueryRow(`SELECT value FROM counters WHERE key = ?`, key).Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		} else {
+			return 0, err
+		}
+	}
+
+	return value, nil
+}
+
+func (s *Sqlite) CounterIncrement(key string, increment int64) (int64, error) {
+	var value int64
+
+	if err := s.db.QueryRow(`INSERT INTO counters (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = value + excluded.value
+		RETURNING value`, key, increment).Scan(&value); err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+func (s *Sqlite) CounterList(prefix, cursor string, limit int) ([]string, string, error) {
+	return s.list(`SELECT key FROM counters WHERE key > ? AND substr(key, 1, length(?)) = ?
+		ORDER BY key LIMIT ?`, prefix, cursor, limit)
+}
+
+// Flag
+func (s *Sqlite) FlagGet(key string) (bool, error) {
+	var value bool
+
+	if err := s.db.QueryRow(`SELECT value FROM flags WHERE key = ?`, key).Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else {
+			return false, err
+		}
+	}
+
+	return value, nil
+}
+
+func (s *Sqlite) FlagSet(key string, value bool) error {
+	_, err := s.db.Exec(`INSERT INTO flags (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value)
+
+	return err
+}
+
+func (s *Sqlite) FlagList(prefix, cursor string, limit int) ([]string, string, error) {
+	return s.list(`SELECT key FROM flags WHERE key > ? AND substr(key, 1, length(?)) = ?
+		ORDER BY key LIMIT ?`, prefix, cursor, limit)
+}
+
+// KV
+func (s *Sqlite) KVGet(key string) (*kv.Value, error) {
+	value, err := scanKV(s.db.QueryRow(`SELECT `+kvColumns+` FROM kv WHERE key = ? AND `+kvLive, key, now()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, purple.NotFound(key)
+		} else {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+// The columns that scanKV reads
+const kvColumns = `content, version, content_type, metadata, created, updated`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanKV(row scanner, dest ...interface{}) (*kv.Value, error) {
+	var (
+		value            = &kv.Value{}
+		metadata         []byte
+		created, updated int64
+	)
+
+	dest = append([]interface{}{&value.Content, &value.Version, &value.ContentType, &metadata, &created, &updated}, dest...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if metadata != nil {
+		if err := json.Unmarshal(metadata, &value.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	value.Created, value.Updated = kv.FromUnixNano(created), kv.FromUnixNano(updated)
+
+	return value, nil
+}
+
+func (s *Sqlite) KVPut(key string, value *kv.Value, ttl int32) error {
+	return s.putKV(key, kvPutStmt, value, ttl)
+}
+
+const kvPutStmt = `INSERT INTO kv (key, content, version, content_type, metadata, created, updated, expires_at)
+	VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6, ?7)
+	ON CONFLICT (key) DO UPDATE SET content = excluded.content, version = excluded.version,
+		content_type = excluded.content_type, metadata = excluded.metadata, updated = excluded.updated,
+		expires_at = excluded.expires_at`
+
+func (s *Sqlite) KVPutIfVersion(key string, value *kv.Value, version uint64, ttl int32) error {
+	return s.putKV(key, `UPDATE kv SET content = ?2, version = ?3, content_type = ?4, metadata = ?5, updated = ?6,
+		expires_at = ?7 WHERE key = ?1 AND version = ?8`, value, ttl, version)
+}
+
+func (s *Sqlite) KVPutIfAbsent(key string, value *kv.Value, ttl int32) error {
+	return s.putKV(key, `INSERT INTO kv (key, content, version, content_type, metadata, created, updated, expires_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6, ?7) ON CONFLICT (key) DO NOTHING`, value, ttl)
+}
+
+// The condition that KV rows must meet to be visible, given the current time
+const kvLive = `(expires_at IS NULL OR expires_at > ?)`
+
+// Runs a statement that writes the value with the next KV version. The statement takes the key, content, version,
+// content type, metadata, current time, and expiry as its first seven parameters; if it doesn't write a row, the write
+// fails with a conflict error. An expired row for the key is deleted first so that the statement doesn't see it.
+func (s *Sqlite) putKV(key, stmt string, value *kv.Value, ttl int32, args ...interface{}) error {
+	if err := kv.CheckTTL(ttl); err != nil {
+		return err
+	}
+
+	return s.txn(func(tx *sql.Tx) error {
+		return writeKV(tx, key, stmt, value, ttl, args...)
+	})
+}
+
+// Does the work of putKV within the transaction tx.
+func writeKV(tx *sql.Tx, key, stmt string, value *kv.Value, ttl int32, args ...interface{}) error {
+	stored := value.Stamped(nil, time.Now())
+
+	// The driver stores nil as NULL, which the content column doesn't allow
+	if stored.Content == nil {
+		stored.Content = []byte{}
+	}
+
+	var metadata interface{}
+
+	if stored.Metadata != nil {
+		js, err := json.Marshal(stored.Metadata)
+		if err != nil {
+			return err
+		}
+
+		metadata = string(js)
+	}
+
+	if err := deleteExpiredKV(tx, key); err != nil {
+		return err
+	}
+
+	var version uint64
+
+	if err := tx.QueryRow(`UPDATE sequences SET value = value + 1 WHERE name = 'kv' RETURNING value`).Scan(&version); err != nil {
+		return err
+	}
+
+	params := []interface{}{key, stored.Content, version, stored.ContentType, metadata, kv.UnixNano(stored.Updated), expiresAt(ttl)}
+
+	res, err := tx.Exec(stmt, append(params, args...)...)
+	if err != nil {
+		return err
+	}
+
+	return conflictIfUnchanged(res, key)
+}
+
+func deleteExpiredKV(tx *sql.Tx, key string) error {
+	_, err := tx.Exec(`DELETE FROM kv WHERE key = ? AND expires_at <= ?`, key, now())
+
+	return err
+}
+
+// Returns the expiry of a KV key written now with the TTL, which is NULL if the TTL is 0.
+func expiresAt(ttl int32) interface{} {
+	if ttl == 0 {
+		return nil
+	}
+
+	return now() + int64(ttl)*1000
+}
+
+func (s *Sqlite) KVDelete(key string) error {
+	_, err := s.db.Exec(`DELETE FROM kv WHERE key = ?`, key)
+
+	return err
+}
+
+func (s *Sqlite) KVDeleteIfVersion(key string, version uint64) error {
+	res, err := s.db.Exec(`DELETE FROM kv WHERE key = ? AND version = ? AND `+kvLive, key, version, now())
+	if err != nil {
+		return err
+	}
+
+	return conflictIfUnchanged(res, key)
+}
+
+// Returns the number of seconds until a key expires, rounded up, or kv.NoTTL.
+func (s *Sqlite) KVTTL(key string) (int32, error) {
+	var expires sql.NullInt64
+
+	if err := s.db.QueryRow(`SELECT expires_at FROM kv WHERE key = ? AND `+kvLive, key, now()).Scan(&expires); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, purple.NotFound(key)
+		} else {
+			return 0, err
+		}
+	}
+
+	if !expires.Valid {
+		return kv.NoTTL, nil
+	}
+
+	return int32((expires.Int64 - now() + 999) / 1000), nil
+}
+
+func (s *Sqlite) KVExpire(key string, ttl int32) error {
+	if err := kv.CheckExpireTTL(ttl); err != nil {
+		return err
+	}
+
+	return s.expireKV(key, ttl)
+}
+
+func (s *Sqlite) KVPersist(key string) error {
+	return s.expireKV(key, 0)
+}
+
+func (s *Sqlite) expireKV(key string, ttl int32) error {
+	return s.txn(func(tx *sql.Tx) error {
+		if err := deleteExpiredKV(tx, key); err != nil {
+			return err
+		}
+
+		var version uint64
+
+		if err := tx.QueryRow(`UPDATE sequences SET value = value + 1 WHERE name = 'kv' RETURNING value`).Scan(&version); err != nil {
+			return err
+		}
+
+		res, err := tx.Exec(`UPDATE kv SET expires_at = ?, version = ? WHERE key = ?`, expiresAt(ttl), version, key)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		// Rolling back the transaction also takes back the version
+		if n == 0 {
+			return purple.NotFound(key)
+		}
+
+		return nil
+	})
+}
+
+func conflictIfUnchanged(res sql.Result, key string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return purple.Conflict(key)
+	}
+
+	return nil
+}
+
+func (s *Sqlite) KVList(prefix, cursor string, limit int) ([]string, string, error) {
+	return s.list(`SELECT key FROM kv WHERE `+kvLive+` AND key > ? AND substr(key, 1, length(?)) = ?
+		ORDER BY key LIMIT ?`, prefix, cursor, limit, now())
+}
+
+// Fetches all of the keys with a single query.
+func (s *Sqlite) KVGetMany(keys []string) ([]*kv.Value, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
+		return nil, err
+	}
+
+	values := make([]*kv.Value, len(keys))
+
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, now())
+
+	for _, key := range keys {
+		args = append(args, key)
+	}
+
+	rows, err := s.db.Query(`SELECT `+kvColumns+`, key FROM kv WHERE `+kvLive+` AND key IN (?`+
+		strings.Repeat(", ?", len(keys)-1)+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[string]*kv.Value, len(keys))
+
+	for rows.Next() {
+		var key string
+
+		value, err := scanKV(rows, &key)
+		if err != nil {
+			return nil, err
+		}
+
+		found[key] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, key := range keys {
+		values[i] = found[key]
+	}
+
+	return values, nil
+}
+
+// Writes all of the entries in a single transaction.
+func (s *Sqlite) KVPutMany(entries []*kv.Entry) error {
+	if err := kv.CheckEntries(entries); err != nil {
+		return err
+	}
+
+	return s.txn(func(tx *sql.Tx) error {
+		for _, e := range entries {
+			if err := writeKV(tx, e.Key, kvPutStmt, e.Value, e.TTL); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Deletes all of the keys in a single transaction.
+func (s *Sqlite) KVDeleteMany(keys []string) ([]bool, error) {
+	if err := kv.CheckBatch(len(keys)); err != nil {
+		return nil, err
+	}
+
+	existed := make([]bool, len(keys))
+
+	if err := s.txn(func(tx *sql.Tx) error {
+		for i, key := range keys {
+			// Expired rows are deleted first so that they aren't counted
+			if err := deleteExpiredKV(tx, key); err != nil {
+				return err
+			}
+
+			res, err := tx.Exec(`DELETE FROM kv WHERE key = ?`, key)
+			if err != nil {
+				return err
+			}
+
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			existed[i] = n > 0
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return existed, nil
+}
+
+// Set
+func (s *Sqlite) SetGet(set string) ([]string, error) {
+	return members(s.db, set)
+}
+
+func (s *Sqlite) SetAdd(set, item string) ([]string, error) {
+	var items []string
+
+	if err := s.txn(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO set_members (name, item) VALUES (?, ?)`, set, item); err != nil {
+			return err
+		}
+
+		var err error
+		items, err = members(tx, set)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (s *Sqlite) SetRemove(set, item string) ([]string, error) {
+	var items []string
+
+	if err := s.txn(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM set_members WHERE name = ? AND item = ?`, set, item); err != nil {
+			return err
+		}
+
+		var err error
+		items, err = members(tx, set)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Pages are read in item order using the index on names and items.
+func (s *Sqlite) SetGetPage(key, cursor string, limit int) ([]string, string, error) {
+	limit = data.ListLimit(limit)
+
+	// One item beyond the limit is read to find out whether there's another page
+	items, err := queryItems(s.db, `SELECT item FROM set_members WHERE name = ? AND item > ? ORDER BY item LIMIT ?`,
+		key, cursor, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, next := data.Truncate(items, limit)
+
+	return page, next, nil
+}
+
+func (s *Sqlite) SetAddSummary(key, item string) (*set.Summary, error) {
+	return s.summarize(key, `INSERT OR IGNORE INTO set_members (name, item) VALUES (?, ?)`, item)
+}
+
+func (s *Sqlite) SetRemoveSummary(key, item string) (*set.Summary, error) {
+	return s.summarize(key, `DELETE FROM set_members WHERE name = ? AND item = ?`, item)
+}
+
+// Runs a statement that adds or removes the item and counts the set's items afterwards within one transaction.
+func (s *Sqlite) summarize(key, stmt, item string) (*set.Summary, error) {
+	summary := &set.Summary{}
+
+	if err := s.txn(func(tx *sql.Tx) error {
+		res, err := tx.Exec(stmt, key, item)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		summary.Changed = n > 0
+
+		return tx.QueryRow(`SELECT COUNT(*) FROM set_members WHERE name = ?`, key).Scan(&summary.Size)
+	}); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (s *Sqlite) SetRandom(key string, count int) ([]string, error) {
+	items, err := members(s.db, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.NewSet(items...).Random(count), nil
+}
+
+func (s *Sqlite) SetPop(key string, count int) ([]string, error) {
+	if err := set.CheckPopCount(count); err != nil {
+		return nil, err
+	}
+
+	var popped []string
+
+	if err := s.txn(func(tx *sql.Tx) error {
+		items, err := members(tx, key)
+		if err != nil {
+			return err
+		}
+
+		popped = data.NewSet(items...).Random(count)
+
+		for _, item := range popped {
+			if _, err := tx.Exec(`DELETE FROM set_members WHERE name = ? AND item = ?`, key, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return popped, nil
+}
+
+func (s *Sqlite) SetContains(set, item string) (bool, error) {
+	var contains bool
+
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM set_members WHERE name = ? AND item = ?)`, set, item).
+		Scan(&contains)
+
+	return contains, err
+}
+
+func (s *Sqlite) SetSize(set string) (int, error) {
+	var size int
+
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM set_members WHERE name = ?`, set).Scan(&size)
+
+	return size, err
+}
+
+func (s *Sqlite) SetUnion(sets ...string) ([]string, error) {
+	return s.combineSets(set.Union, sets)
+}
+
+func (s *Sqlite) SetIntersect(sets ...string) ([]string, error) {
+	return s.combineSets(set.Intersect, sets)
+}
+
+func (s *Sqlite) SetDiff(sets ...string) ([]string, error) {
+	return s.combineSets(set.Diff, sets)
+}
+
+func (s *Sqlite) SetUnionStore(dest string, sets ...string) (int, error) {
+	return s.storeSets(set.Union, dest, sets)
+}
+
+func (s *Sqlite) SetIntersectStore(dest string, sets ...string) (int, error) {
+	return s.storeSets(set.Intersect, dest, sets)
+}
+
+func (s *Sqlite) SetDiffStore(dest string, sets ...string) (int, error) {
+	return s.storeSets(set.Diff, dest, sets)
+}
+
+func (s *Sqlite) combineSets(op string, sets []string) ([]string, error) {
+	if err := set.CheckSets(sets); err != nil {
+		return nil, err
+	}
+
+	query, args := combineQuery(op, sets)
+
+	return queryItems(s.db, query, args...)
+}
+
+// Combines the sets and replaces the destination's members with the result within one transaction.
+func (s *Sqlite) storeSets(op, dest string, sets []string) (int, error) {
+	if err := set.CheckSets(sets, dest); err != nil {
+		return 0, err
+	}
+
+	var size int
+
+	if err := s.txn(func(tx *sql.Tx) error {
+		query, args := combineQuery(op, sets)
+
+		items, err := queryItems(tx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM set_members WHERE name = ?`, dest); err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if _, err := tx.Exec(`INSERT INTO set_members (name, item) VALUES (?, ?)`, dest, item); err != nil {
+				return err
+			}
+		}
+
+		size = len(items)
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// Builds the query that combines the sets, whose results are ordered by item.
+func combineQuery(op string, sets []string) (string, []interface{}) {
+	if op == set.Diff {
+		first, others := sets[0], distinct(sets[1:])
+
+		if len(others) == 0 {
+			return `SELECT item FROM set_members WHERE name = ? ORDER BY item`, []interface{}{first}
+		}
+
+		return `SELECT item FROM set_members WHERE name = ? AND item NOT IN (
+				SELECT item FROM set_members WHERE name IN (?` + strings.Repeat(", ?", len(others)-1) + `)
+			) ORDER BY item`, append([]interface{}{first}, others...)
+	}
+
+	names := distinct(sets)
+	in := `name IN (?` + strings.Repeat(", ?", len(names)-1) + `)`
+
+	if op == set.Union {
+		return `SELECT DISTINCT item FROM set_members WHERE ` + in + ` ORDER BY item`, names
+	}
+
+	// An item is in all of the sets if it's in as many of them as there are sets
+	return `SELECT item FROM set_members WHERE ` + in + ` GROUP BY item HAVING COUNT(*) = ? ORDER BY item`,
+		append(names, len(names))
+}
+
+// Returns the sets without duplicates as query args.
+func distinct(sets []string) []interface{} {
+	seen := make(map[string]bool, len(sets))
+	args := make([]interface{}, 0, len(sets))
+
+	for _, s := range sets {
+		if !seen[s] {
+			seen[s] = true
+			args = append(args, s)
+		}
+	}
+
+	return args
+}
+
+func (s *Sqlite) SetList(prefix, cursor string, limit int) ([]string, string, error) {
+	return s.list(`SELECT DISTINCT name FROM set_members WHERE name > ? AND substr(name, 1, length(?)) = ?
+		ORDER BY name LIMIT ?`, prefix, cursor, limit)
+}
+
+// Runs a listing query, which takes any leading args followed by the cursor, the prefix (twice), and the limit, and
+// returns the requested page of keys.
+func (s *Sqlite) list(query, prefix, cursor string, limit int, leading ...interface{}) ([]string, string, error) {
+	limit = data.ListLimit(limit)
+
+	// One key beyond the limit is fetched to find out whether there's another page
+	args := append(leading, cursor, prefix, prefix, limit+1)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+
+	for rows.Next() {
+		var key string
+
+		if err := rows.Scan(&key); err != nil {
+			return nil, "", err
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	page, next := data.Truncate(keys, limit)
+
+	return page, next, nil
+}
+
+// Satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Fetches the members of a set in insertion order. Sets without members are returned as empty sets.
+func members(q querier, set string) ([]string, error) {
+	return queryItems(q, `SELECT item FROM set_members WHERE name = ? ORDER BY rowid`, set)
+}
+
+// Runs a query that selects set items and returns them, or an empty slice if there are none.
+func queryItems(q querier, query string, args ...interface{}) ([]string, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]string, 0)
+
+	for rows.Next() {
+		var item string
+
+		if err := rows.Scan(&item); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/txn"
+)
+
+// Txn applies the operations within a single transaction.
+func (s *Sqlite) Txn(ops []*txn.Op) ([]*txn.Result, error) {
+	if err := txn.Validate(ops); err != nil {
+		return nil, err
+	}
+
+	return s.commit(nil, ops)
+}
+
+// TxnBegin starts a session whose reads are checked again once it's committed, within the transaction that applies its
+// writes. That transaction takes the write lock as it begins, so nothing can change in between.
+func (s *Sqlite) TxnBegin() (txn.Session, error) {
+	return txn.NewSession(s.Txn, s.commit), nil
+}
+
+func (s *Sqlite) commit(reads []*txn.Read, ops []*txn.Op) ([]*txn.Result, error) {
+	var results []*txn.Result
+
+	if err := s.txn(func(tx *sql.Tx) (err error) {
+		results, err = txn.Commit(&sqliteTxn{tx: tx}, reads, ops)
+		return
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type sqliteTxn struct {
+	tx *sql.Tx
+}
+
+var _ txn.Store = (*sqliteTxn)(nil)
+
+func (t *sqliteTxn) KVGet(key string) (*kv.Value, error) {
+	value, err := scanKV(t.tx.QueryRow(`SELECT `+kvColumns+` FROM kv WHERE key = ? AND `+kvLive, key, now()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return value, err
+}
+
+func (t *sqliteTxn) KVPut(key string, value *kv.Value, ttl int32) error {
+	return writeKV(t.tx, key, kvPutStmt, value, ttl)
+}
+
+func (t *sqliteTxn) KVDelete(key string) (bool, error) {
+	if err := deleteExpiredKV(t.tx, key); err != nil {
+		return false, err
+	}
+
+	res, err := t.tx.Exec(`DELETE FROM kv WHERE key = ?`, key)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return n > 0, err
+}
+
+func (t *sqliteTxn) CounterGet(key string) (int64, error) {
+	var value int64
+
+	if err := t.tx.QueryRow(`SELECT value FROM counters WHERE key = ?`, key).Scan(&value); err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+func (t *sqliteTxn) CounterIncrement(key string, amount int64) (int64, error) {
+	var value int64
+
+	err := t.tx.QueryRow(`INSERT INTO counters (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = value + excluded.value
+		RETURNING value`, key, amount).Scan(&value)
+
+	return value, err
+}
+
+func (t *sqliteTxn) FlagGet(key string) (bool, error) {
+	var value bool
+
+	if err := t.tx.QueryRow(`SELECT value FROM flags WHERE key = ?`, key).Scan(&value); err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	return value, nil
+}
+
+func (t *sqliteTxn) FlagSet(key string, value bool) error {
+	_, err := t.tx.Exec(`INSERT INTO flags (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value)
+
+	return err
+}
+
+// Sets only exist while they have members.
+func (t *sqliteTxn) SetGet(set string) ([]string, bool, error) {
+	items, err := members(t.tx, set)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return items, len(items) > 0, nil
+}
+
+func (t *sqliteTxn) SetAdd(set, item string) ([]string, error) {
+	if _, err := t.tx.Exec(`INSERT OR IGNORE INTO set_members (name, item) VALUES (?, ?)`, set, item); err != nil {
+		return nil, err
+	}
+
+	return members(t.tx, set)
+}
+
+func (t *sqliteTxn) SetRemove(set, item string) ([]string, bool, error) {
+	_, existed, err := t.SetGet(set)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if _, err := t.tx.Exec(`DELETE FROM set_members WHERE name = ? AND item = ?`, set, item); err != nil {
+		return nil, false, err
+	}
+
+	items, err := members(t.tx, set)
+
+	return items, existed, err
+}
@@ -0,0 +1,39 @@
+package backend
+
+import "github.com/purpledb/purple/internal/backend/memory"
+
+// Stats gathers the statistics reported by the backends behind a Service. Fields are nil for statistics that none of
+// those backends keep.
+type Stats struct {
+	Tiered *TieredStats
+	Memory *memory.Stats
+}
+
+// CollectStats gathers the statistics of the backends behind svc, looking through Backend, Tiered, and Composite
+// wrappers.
+func CollectStats(svc Service) *Stats {
+	stats := &Stats{}
+
+	collectStats(svc, stats)
+
+	return stats
+}
+
+func collectStats(svc Service, stats *Stats) {
+	switch s := svc.(type) {
+	case *memory.Memory:
+		mem := s.Stats()
+		stats.Memory = &mem
+	case *Backend:
+		collectStats(s.Service, stats)
+	case *Tiered:
+		tiered := s.Stats()
+		stats.Tiered = &tiered
+
+		collectStats(s.Service, stats)
+	case *Composite:
+		for _, bk := range s.backends {
+			collectStats(bk, stats)
+		}
+	}
+}
@@ -0,0 +1,547 @@
+package backend
+
+import (
+	"hash/fnv"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
+	"github.com/purpledb/purple/services/txn"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// The number of locks that keys are striped across. A key's lock is held while it's loaded from or written to the
+// backing store so that a slow read can't put a stale value into the LRU after a concurrent write.
+const tieredLockCount = 32
+
+// Tiered keeps a size-bounded in-memory LRU of counters, flags, KV values, and sets in front of another backend. Reads
+// are served from the LRU when possible and writes go through to the backing store before the LRU is updated. Cache
+// service operations bypass the LRU since the backing store already handles their expiry.
+type Tiered struct {
+	Service
+
+	lru   *lru.Cache[tieredKey, interface{}]
+	locks [tieredLockCount]sync.Mutex
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type tieredKey struct {
+	service string
+	key     string
+}
+
+// TieredStats reports how effective the LRU of a Tiered backend has been since it was created.
+type TieredStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// The number of entries currently held in the LRU
+	Size int
+}
+
+var _ Service = (*Tiered)(nil)
+
+// NewTiered places an LRU holding at most size entries in front of the supplied backend.
+func NewTiered(backing Service, size int) (*Tiered, error) {
+	cache, err := lru.New[tieredKey, interface{}](size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tiered{
+		Service: backing,
+		lru:     cache,
+	}, nil
+}
+
+func (t *Tiered) Name() string {
+	return "tiered"
+}
+
+// Stats returns the LRU's hit, miss, and eviction counts.
+func (t *Tiered) Stats() TieredStats {
+	return TieredStats{
+		Hits:      atomic.LoadUint64(&t.hits),
+		Misses:    atomic.LoadUint64(&t.misses),
+		Evictions: atomic.LoadUint64(&t.evictions),
+		Size:      t.lru.Len(),
+	}
+}
+
+// Service methods
+
+// Every lock is held while flushing so that a load that started before the flush can't put its value into the LRU
+// after it's been purged.
+func (t *Tiered) Flush() error {
+	for i := range t.locks {
+		t.locks[i].Lock()
+		defer t.locks[i].Unlock()
+	}
+
+	defer t.lru.Purge()
+
+	return t.Service.Flush()
+}
+
+// Returns the lock that guards the backing store entry for the supplied key.
+func (t *Tiered) lock(k tieredKey) *sync.Mutex {
+	return &t.locks[lockIndex(k)]
+}
+
+func lockIndex(k tieredKey) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(k.service))
+	_, _ = h.Write([]byte(k.key))
+
+	return int(h.Sum32() % tieredLockCount)
+}
+
+func (t *Tiered) add(k tieredKey, value interface{}) {
+	if t.lru.Add(k, value) {
+		atomic.AddUint64(&t.evictions, 1)
+	}
+}
+
+// A value that load returns to have get pass it on without caching it
+type uncached struct {
+	value interface{}
+}
+
+// Returns the value held in the LRU for k, falling back to load (under k's lock) and caching the result on a miss.
+func (t *Tiered) get(k tieredKey, load func() (interface{}, error)) (interface{}, error) {
+	if val, ok := t.lru.Get(k); ok {
+		atomic.AddUint64(&t.hits, 1)
+		return val, nil
+	}
+
+	mu := t.lock(k)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Another goroutine may have loaded the value while this one waited for the lock
+	if val, ok := t.lru.Get(k); ok {
+		atomic.AddUint64(&t.hits, 1)
+		return val, nil
+	}
+
+	atomic.AddUint64(&t.misses, 1)
+
+	val, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if u, ok := val.(uncached); ok {
+		return u.value, nil
+	}
+
+	t.add(k, val)
+
+	return val, nil
+}
+
+// Applies write to the backing store under k's lock. If it succeeds the LRU entry is replaced with the value that
+// write returns, or removed if that value is nil. If it fails the entry is removed, since the stored value is unknown.
+func (t *Tiered) set(k tieredKey, write func() (interface{}, error)) (interface{}, error) {
+	mu := t.lock(k)
+	mu.Lock()
+	defer mu.Unlock()
+
+	val, err := write()
+	if err != nil || val == nil {
+		t.lru.Remove(k)
+		return nil, err
+	}
+
+	t.add(k, val)
+
+	return val, nil
+}
+
+// Counter
+func (t *Tiered) CounterGet(key string) (int64, error) {
+	val, err := t.get(tieredKey{"counter", key}, func() (interface{}, error) {
+		return t.Service.CounterGet(key)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(int64), nil
+}
+
+func (t *Tiered) CounterIncrement(key string, increment int64) (int64, error) {
+	val, err := t.set(tieredKey{"counter", key}, func() (interface{}, error) {
+		return t.Service.CounterIncrement(key, increment)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(int64), nil
+}
+
+// Flag
+func (t *Tiered) FlagGet(key string) (bool, error) {
+	val, err := t.get(tieredKey{"flag", key}, func() (interface{}, error) {
+		return t.Service.FlagGet(key)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return val.(bool), nil
+}
+
+func (t *Tiered) FlagSet(key string, value bool) error {
+	_, err := t.set(tieredKey{"flag", key}, func() (interface{}, error) {
+		return value, t.Service.FlagSet(key, value)
+	})
+
+	return err
+}
+
+// KV values are cached along with their expiry so that the LRU doesn't serve them once the backing store has expired
+// them. The expiry is rounded down to the second, so a value may drop out of the LRU slightly early but never late.
+type tieredKV struct {
+	value   *kv.Value
+	expires time.Time
+}
+
+func (e *tieredKV) expired() bool {
+	return !e.expires.IsZero() && !time.Now().Before(e.expires)
+}
+
+func (t *Tiered) KVGet(key string) (*kv.Value, error) {
+	k := tieredKey{"kv", key}
+
+	// The value and its TTL take separate calls, so the value is read again afterwards. Every write, changes to the
+	// expiry included, gives the value a new version, so if the version is unchanged the TTL belongs to the value.
+	// Otherwise the value is returned without being cached.
+	load := func() (interface{}, error) {
+		val, err := t.Service.KVGet(key)
+		if err != nil {
+			return nil, err
+		}
+
+		ttl, err := t.Service.KVTTL(key)
+		if err != nil {
+			return nil, err
+		}
+
+		again, err := t.Service.KVGet(key)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &tieredKV{value: again}
+		if ttl != kv.NoTTL {
+			entry.expires = time.Now().Add(time.Duration(ttl-1) * time.Second)
+		}
+
+		if again.Version != val.Version {
+			return uncached{entry}, nil
+		}
+
+		return entry, nil
+	}
+
+	val, err := t.get(k, load)
+	if err != nil {
+		return nil, err
+	}
+
+	if val.(*tieredKV).expired() {
+		t.lru.Remove(k)
+
+		if val, err = t.get(k, load); err != nil {
+			return nil, err
+		}
+	}
+
+	// Callers get a copy so that modifying it doesn't change the cached value
+	return copyValue(val.(*tieredKV).value), nil
+}
+
+// KV writes remove the LRU entry rather than replacing it, since the new version is only known to the backing store.
+func (t *Tiered) KVPut(key string, value *kv.Value, ttl int32) error {
+	return t.writeKV(key, func() error {
+		return t.Service.KVPut(key, value, ttl)
+	})
+}
+
+func (t *Tiered) KVPutIfVersion(key string, value *kv.Value, version uint64, ttl int32) error {
+	return t.writeKV(key, func() error {
+		return t.Service.KVPutIfVersion(key, value, version, ttl)
+	})
+}
+
+func (t *Tiered) KVPutIfAbsent(key string, value *kv.Value, ttl int32) error {
+	return t.writeKV(key, func() error {
+		return t.Service.KVPutIfAbsent(key, value, ttl)
+	})
+}
+
+func (t *Tiered) KVExpire(key string, ttl int32) error {
+	return t.writeKV(key, func() error {
+		return t.Service.KVExpire(key, ttl)
+	})
+}
+
+func (t *Tiered) KVPersist(key string) error {
+	return t.writeKV(key, func() error {
+		return t.Service.KVPersist(key)
+	})
+}
+
+func (t *Tiered) KVDelete(key string) error {
+	return t.writeKV(key, func() error {
+		return t.Service.KVDelete(key)
+	})
+}
+
+func (t *Tiered) KVDeleteIfVersion(key string, version uint64) error {
+	return t.writeKV(key, func() error {
+		return t.Service.KVDeleteIfVersion(key, version)
+	})
+}
+
+func (t *Tiered) writeKV(key string, write func() error) error {
+	_, err := t.set(tieredKey{"kv", key}, func() (interface{}, error) {
+		return nil, write()
+	})
+
+	return err
+}
+
+// KVGetMany goes straight to the backing store, since caching the values would take a KVTTL call per key.
+func (t *Tiered) KVPutMany(entries []*kv.Entry) error {
+	keys := make([]string, len(entries))
+
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+
+	return t.writeKVs(keys, func() error {
+		return t.Service.KVPutMany(entries)
+	})
+}
+
+func (t *Tiered) KVDeleteMany(keys []string) ([]bool, error) {
+	var existed []bool
+
+	err := t.writeKVs(keys, func() (err error) {
+		existed, err = t.Service.KVDeleteMany(keys)
+		return
+	})
+
+	return existed, err
+}
+
+// Like writeKV for a batch of keys.
+func (t *Tiered) writeKVs(keys []string, write func() error) error {
+	ks := make([]tieredKey, len(keys))
+
+	for i, key := range keys {
+		ks[i] = tieredKey{"kv", key}
+	}
+
+	return t.writeAll(ks, write)
+}
+
+// Applies write to the backing store while holding the locks of all of the keys, which are taken in lock order to rule
+// out deadlocks between batches, and then removes the keys from the LRU.
+func (t *Tiered) writeAll(keys []tieredKey, write func() error) error {
+	var picked [tieredLockCount]bool
+
+	for _, k := range keys {
+		picked[lockIndex(k)] = true
+	}
+
+	for i := range picked {
+		if picked[i] {
+			t.locks[i].Lock()
+			defer t.locks[i].Unlock()
+		}
+	}
+
+	err := write()
+
+	for _, k := range keys {
+		t.lru.Remove(k)
+	}
+
+	return err
+}
+
+// Every key a transaction touches is removed from the LRU, as reading it may have expired a KV value.
+func (t *Tiered) Txn(ops []*txn.Op) ([]*txn.Result, error) {
+	return t.applyTxn(ops, t.Service.Txn)
+}
+
+// Sessions read from and commit to the underlying backend, touching the LRU like Txn does.
+func (t *Tiered) TxnBegin() (txn.Session, error) {
+	s, err := t.Service.TxnBegin()
+	if err != nil {
+		return nil, err
+	}
+
+	return &tieredSession{Session: s, t: t}, nil
+}
+
+type tieredSession struct {
+	txn.Session
+	t *Tiered
+}
+
+func (s *tieredSession) Read(ops []*txn.Op) ([]*txn.Result, error) {
+	return s.t.applyTxn(ops, s.Session.Read)
+}
+
+func (s *tieredSession) Commit(ops []*txn.Op) ([]*txn.Result, error) {
+	return s.t.applyTxn(ops, s.Session.Commit)
+}
+
+func (t *Tiered) applyTxn(ops []*txn.Op, apply func(ops []*txn.Op) ([]*txn.Result, error)) ([]*txn.Result, error) {
+	keys := make([]tieredKey, len(ops))
+
+	for i, op := range ops {
+		// Operation kinds are named after their service, e.g. counter.incr
+		keys[i] = tieredKey{strings.SplitN(op.Kind, ".", 2)[0], op.Key}
+	}
+
+	var results []*txn.Result
+
+	err := t.writeAll(keys, func() (err error) {
+		results, err = apply(ops)
+		return
+	})
+
+	return results, err
+}
+
+// Set
+func (t *Tiered) SetGet(set string) ([]string, error) {
+	val, err := t.get(tieredKey{"set", set}, func() (interface{}, error) {
+		return t.Service.SetGet(set)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyItems(val.([]string)), nil
+}
+
+func (t *Tiered) SetAdd(set, item string) ([]string, error) {
+	return t.modifySet(set, func() ([]string, error) {
+		return t.Service.SetAdd(set, item)
+	})
+}
+
+func (t *Tiered) SetRemove(set, item string) ([]string, error) {
+	return t.modifySet(set, func() ([]string, error) {
+		return t.Service.SetRemove(set, item)
+	})
+}
+
+// The summaries and SetPop don't return the set's items, so the set is removed from the LRU rather than cached.
+func (t *Tiered) SetAddSummary(key, item string) (*set.Summary, error) {
+	return t.summarize(key, func() (*set.Summary, error) {
+		return t.Service.SetAddSummary(key, item)
+	})
+}
+
+func (t *Tiered) SetRemoveSummary(key, item string) (*set.Summary, error) {
+	return t.summarize(key, func() (*set.Summary, error) {
+		return t.Service.SetRemoveSummary(key, item)
+	})
+}
+
+func (t *Tiered) summarize(key string, write func() (*set.Summary, error)) (*set.Summary, error) {
+	var summary *set.Summary
+
+	err := t.writeAll([]tieredKey{{"set", key}}, func() (err error) {
+		summary, err = write()
+		return
+	})
+
+	return summary, err
+}
+
+func (t *Tiered) SetPop(key string, count int) ([]string, error) {
+	var popped []string
+
+	err := t.writeAll([]tieredKey{{"set", key}}, func() (err error) {
+		popped, err = t.Service.SetPop(key, count)
+		return
+	})
+
+	return popped, err
+}
+
+func (t *Tiered) SetUnionStore(dest string, sets ...string) (int, error) {
+	return t.storeSets(dest, func() (int, error) {
+		return t.Service.SetUnionStore(dest, sets...)
+	})
+}
+
+func (t *Tiered) SetIntersectStore(dest string, sets ...string) (int, error) {
+	return t.storeSets(dest, func() (int, error) {
+		return t.Service.SetIntersectStore(dest, sets...)
+	})
+}
+
+func (t *Tiered) SetDiffStore(dest string, sets ...string) (int, error) {
+	return t.storeSets(dest, func() (int, error) {
+		return t.Service.SetDiffStore(dest, sets...)
+	})
+}
+
+// Only the size of the stored set is known, so the destination is removed from the LRU rather than cached.
+func (t *Tiered) storeSets(dest string, store func() (int, error)) (int, error) {
+	var size int
+
+	err := t.writeAll([]tieredKey{{"set", dest}}, func() (err error) {
+		size, err = store()
+		return
+	})
+
+	return size, err
+}
+
+// Caches the members returned by a set mutation. The LRU holds its own copy so that callers can't modify it.
+func (t *Tiered) modifySet(set string, write func() ([]string, error)) ([]string, error) {
+	val, err := t.set(tieredKey{"set", set}, func() (interface{}, error) {
+		items, err := write()
+		if err != nil {
+			return nil, err
+		}
+
+		return copyItems(items), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyItems(val.([]string)), nil
+}
+
+func copyItems(items []string) []string {
+	return append(make([]string, 0, len(items)), items...)
+}
+
+func copyValue(val *kv.Value) *kv.Value {
+	c := *val
+	c.Content = append([]byte{}, val.Content...)
+	c.Metadata = maps.Clone(val.Metadata)
+
+	return &c
+}
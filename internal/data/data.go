@@ -33,7 +33,5 @@ func BytesToSet(bs []byte) (*Set, error) {
 		return nil, err
 	}
 
-	return &Set{
-		items: items,
-	}, nil
+	return NewSet(items...), nil
 }
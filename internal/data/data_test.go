@@ -1,6 +1,8 @@
 package data
 
 import (
+	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -51,7 +53,50 @@ func TestDataHelpers(t *testing.T) {
 
 			res, err := BytesToSet(bs)
 			is.NoError(err)
-			is.Equal(tc, res.items)
+			is.ElementsMatch(tc, res.Get())
 		}
 	})
 }
+
+func TestPagination(t *testing.T) {
+	is := assert.New(t)
+
+	keys := []string{"b", "a:3", "a:1", "c", "a:2"}
+
+	page, next := Paginate(keys, "a:", "", 2)
+	is.Equal(page, []string{"a:1", "a:2"})
+	is.Equal(next, "a:2")
+
+	page, next = Paginate(keys, "a:", next, 2)
+	is.Equal(page, []string{"a:3"})
+	is.Empty(next)
+
+	page, next = Paginate(keys, "", "", 0)
+	is.Equal(page, []string{"a:1", "a:2", "a:3", "b", "c"})
+	is.Empty(next)
+
+	page, next = Paginate(keys, "d", "", 2)
+	is.Empty(page)
+	is.Empty(next)
+
+	// Walking every page yields every key exactly once, in order
+	many := make([]string, 0, 500)
+	for i := 499; i >= 0; i-- {
+		many = append(many, fmt.Sprintf("key-%03d", i))
+	}
+
+	var all []string
+	for page, next = Paginate(many, "", "", 7); ; page, next = Paginate(many, "", next, 7) {
+		all = append(all, page...)
+		if next == "" {
+			break
+		}
+	}
+	is.Len(all, 500)
+	is.True(sort.StringsAreSorted(all))
+
+	is.Equal(ListLimit(0), DefaultListLimit)
+	is.Equal(ListLimit(-1), DefaultListLimit)
+	is.Equal(ListLimit(MaxListLimit+1), MaxListLimit)
+	is.Equal(ListLimit(10), 10)
+}
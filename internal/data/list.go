@@ -0,0 +1,94 @@
+package data
+
+import (
+	"container/heap"
+	"iter"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// The number of keys returned per page when no limit is supplied
+	DefaultListLimit = 100
+	// The largest page that can be requested
+	MaxListLimit = 1000
+)
+
+// ListLimit returns the page size to use for the supplied limit, which is replaced by the default if it isn't positive
+// and capped at MaxListLimit.
+func ListLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultListLimit
+	}
+
+	if limit > MaxListLimit {
+		return MaxListLimit
+	}
+
+	return limit
+}
+
+// Paginate returns up to limit of the supplied keys that begin with prefix and sort after cursor, in lexicographic
+// order. The returned cursor is the last key of the page, or empty if there are no further keys. Rather than sorting
+// every matching key, only the limit+1 smallest are kept, so a page costs O(n log limit) for n keys.
+func Paginate(keys []string, prefix, cursor string, limit int) ([]string, string) {
+	return paginate(slices.Values(keys), prefix, cursor, limit)
+}
+
+// Like Paginate, but takes the keys as a sequence, so that they don't need to be copied into a slice first.
+func paginate(keys iter.Seq[string], prefix, cursor string, limit int) ([]string, string) {
+	limit = ListLimit(limit)
+
+	// A max-heap of the smallest keys seen so far, one beyond the limit to find out whether there's another page
+	smallest := make(keyHeap, 0)
+
+	for k := range keys {
+		if !strings.HasPrefix(k, prefix) || k <= cursor {
+			continue
+		}
+
+		if len(smallest) <= limit {
+			heap.Push(&smallest, k)
+		} else if k < smallest[0] {
+			smallest[0] = k
+			heap.Fix(&smallest, 0)
+		}
+	}
+
+	page := []string(smallest)
+
+	sort.Strings(page)
+
+	return Truncate(page, limit)
+}
+
+type keyHeap []string
+
+func (h keyHeap) Len() int           { return len(h) }
+func (h keyHeap) Less(i, j int) bool { return h[i] > h[j] }
+func (h keyHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *keyHeap) Push(x interface{}) {
+	*h = append(*h, x.(string))
+}
+
+func (h *keyHeap) Pop() interface{} {
+	old := *h
+	k := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return k
+}
+
+// Truncate cuts a sorted page of keys down to limit keys and returns the cursor for the next page, which is empty if
+// nothing was cut off.
+func Truncate(keys []string, limit int) ([]string, string) {
+	if len(keys) <= limit {
+		return keys, ""
+	}
+
+	keys = keys[:limit]
+
+	return keys, keys[limit-1]
+}
@@ -1,53 +1,188 @@
 package data
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"maps"
+	"math/rand"
+	"sort"
 
+	"github.com/purpledb/purple/services/set"
+)
+
+// Set is a set of strings backed by a map, so that adding, removing, and looking up items takes constant time.
 type Set struct {
-	items []string
+	items map[string]struct{}
 }
 
 func NewSet(items ...string) *Set {
-	is := make([]string, 0)
+	s := &Set{
+		items: make(map[string]struct{}, len(items)),
+	}
 
 	for _, i := range items {
-		is = append(is, i)
+		s.items[i] = struct{}{}
 	}
 
-	return &Set{
-		items: is,
+	return s
+}
+
+func (s *Set) Contains(item string) bool {
+	_, ok := s.items[item]
+	return ok
+}
+
+func (s *Set) Add(item string) {
+	s.items[item] = struct{}{}
+}
+
+func (s *Set) Remove(item string) {
+	delete(s.items, item)
+}
+
+func (s *Set) Len() int {
+	return len(s.items)
+}
+
+// Get returns a copy of the set's items in lexicographic order, which callers can hold onto after the set has been
+// modified.
+func (s *Set) Get() []string {
+	items := s.list()
+	sort.Strings(items)
+
+	return items
+}
+
+// Page returns up to limit items that sort after cursor, in lexicographic order, along with the cursor of the next
+// page, which is empty if there are no further items. Only the items of the page are sorted, and the others aren't
+// copied.
+func (s *Set) Page(cursor string, limit int) ([]string, string) {
+	return paginate(maps.Keys(s.items), "", cursor, limit)
+}
+
+// Random returns up to count distinct items picked at random or, if count is negative, -count items that may repeat,
+// as with Redis's SRANDMEMBER.
+func (s *Set) Random(count int) []string {
+	items := s.list()
+
+	if count < 0 {
+		picked := make([]string, 0)
+
+		for i := 0; i < -count && len(items) > 0; i++ {
+			picked = append(picked, items[rand.Intn(len(items))])
+		}
+
+		return picked
+	}
+
+	if count > len(items) {
+		count = len(items)
+	}
+
+	// Only the first count items need to be shuffled
+	for i := 0; i < count; i++ {
+		j := i + rand.Intn(len(items)-i)
+		items[i], items[j] = items[j], items[i]
 	}
+
+	return items[:count]
 }
 
-func (s *Set) contains(item string) bool {
-	for _, i := range s.items {
-		if i == item {
-			return true
+// The items in no particular order
+func (s *Set) list() []string {
+	items := make([]string, 0, len(s.items))
+
+	for i := range s.items {
+		items = append(items, i)
+	}
+
+	return items
+}
+
+// Copy returns a set with the same items that can be modified independently.
+func (s *Set) Copy() *Set {
+	c := &Set{
+		items: make(map[string]struct{}, len(s.items)),
+	}
+
+	for i := range s.items {
+		c.items[i] = struct{}{}
+	}
+
+	return c
+}
+
+// Union returns a new set with the items that are in s or any of the others.
+func (s *Set) Union(others ...*Set) *Set {
+	u := s.Copy()
+
+	for _, o := range others {
+		for i := range o.items {
+			u.items[i] = struct{}{}
 		}
 	}
 
-	return false
+	return u
 }
 
-func (s *Set) Add(item string) {
-	if !s.contains(item) {
-		s.items = append(s.items, item)
+// Intersect returns a new set with the items that are in s and all of the others.
+func (s *Set) Intersect(others ...*Set) *Set {
+	in := NewSet()
+
+	for i := range s.items {
+		if containedByAll(i, others) {
+			in.items[i] = struct{}{}
+		}
 	}
+
+	return in
 }
 
-func (s *Set) Remove(item string) {
-	if s.contains(item) {
-		for idx, i := range s.items {
-			if i == item {
-				s.items = append(s.items[:idx], s.items[idx+1:]...)
-			}
+// Diff returns a new set with the items that are in s but in none of the others.
+func (s *Set) Diff(others ...*Set) *Set {
+	d := NewSet()
+
+	for i := range s.items {
+		if !containedByAny(i, others) {
+			d.items[i] = struct{}{}
 		}
 	}
+
+	return d
 }
 
-func (s *Set) Get() []string {
-	return NonNilSet(s.items)
+// Combine applies one of the set service's operations to sets, of which there's at least one.
+func Combine(op string, sets []*Set) *Set {
+	switch op {
+	case set.Union:
+		return sets[0].Union(sets[1:]...)
+	case set.Intersect:
+		return sets[0].Intersect(sets[1:]...)
+	}
+
+	return sets[0].Diff(sets[1:]...)
+}
+
+func containedByAll(item string, sets []*Set) bool {
+	for _, s := range sets {
+		if !s.Contains(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containedByAny(item string, sets []*Set) bool {
+	for _, s := range sets {
+		if s.Contains(item) {
+			return true
+		}
+	}
+
+	return false
 }
 
+// AsBytes encodes the set as a JSON array of its items.
 func (s *Set) AsBytes() ([]byte, error) {
 	return json.Marshal(s.Get())
 }
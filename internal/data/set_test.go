@@ -14,16 +14,74 @@ func TestSetType(t *testing.T) {
 	fruits.Remove("some-item")
 
 	fruits.Add("apple")
-	is.Len(fruits.items, 1)
-	is.Equal(fruits.items[0], "apple")
+	is.Equal(fruits.Len(), 1)
+	is.True(fruits.Contains("apple"))
 
 	fruits.Add("apple")
-	is.Len(fruits.items, 1)
-	is.Equal(fruits.items[0], "apple")
+	is.Equal(fruits.Get(), []string{"apple"})
+
+	fruits.Add("banana")
+	fruits.Add("avocado")
+	is.Equal(fruits.Get(), []string{"apple", "avocado", "banana"})
 
 	fruits.Remove("apple")
-	is.Empty(fruits.items)
+	is.False(fruits.Contains("apple"))
+	is.Equal(fruits.Get(), []string{"avocado", "banana"})
+
+	fruits.Remove("avocado")
+	fruits.Remove("banana")
+	is.Empty(fruits.Get())
+	is.Equal(fruits.Len(), 0)
 
 	sets := make(map[string]*Set)
 	is.Len(sets, 0)
 }
+
+func TestSetAlgebra(t *testing.T) {
+	is := assert.New(t)
+
+	a := NewSet("1", "2", "3")
+	b := NewSet("2", "3", "4")
+	c := NewSet("3", "5")
+
+	is.Equal(a.Union(b, c).Get(), []string{"1", "2", "3", "4", "5"})
+	is.Equal(a.Intersect(b, c).Get(), []string{"3"})
+	is.Equal(a.Diff(b).Get(), []string{"1"})
+	is.Equal(b.Diff(a, c).Get(), []string{"4"})
+
+	// Without others, each returns a copy
+	u := a.Union()
+	u.Add("9")
+	is.Equal(a.Get(), []string{"1", "2", "3"})
+	is.Equal(a.Intersect().Get(), a.Get())
+	is.Equal(a.Diff().Get(), a.Get())
+
+	is.Empty(a.Intersect(NewSet()).Get())
+}
+
+func TestSetSampling(t *testing.T) {
+	is := assert.New(t)
+
+	s := NewSet("a", "b", "c", "d", "e")
+
+	page, next := s.Page("", 2)
+	is.Equal(page, []string{"a", "b"})
+	is.Equal(next, "b")
+	page, next = s.Page(next, 3)
+	is.Equal(page, []string{"c", "d", "e"})
+	is.Empty(next)
+
+	picked := s.Random(3)
+	is.Len(picked, 3)
+	is.Subset(s.Get(), picked)
+	is.Len(NewSet(picked...).Get(), 3)
+
+	is.ElementsMatch(s.Random(10), s.Get())
+	is.Empty(s.Random(0))
+
+	// Negative counts may repeat items
+	picked = s.Random(-10)
+	is.Len(picked, 10)
+	is.Subset(s.Get(), picked)
+	is.Empty(NewSet().Random(-3))
+}
@@ -0,0 +1,161 @@
+package migrate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/backend"
+	"github.com/purpledb/purple/proto"
+)
+
+// Record is one entry of a dump. Dumps are written as newline-delimited JSON with one record per line. Which value
+// fields are set depends on the service: cache records carry a value and TTL, counter records a count, flag records a
+// flag, KV records base64-encoded content (which is always present, even if it's empty) along with its content type,
+// metadata, and TTL if the key expires, and set records their items.
+type Record struct {
+	Service     string            `json:"service"`
+	Key         string            `json:"key"`
+	Value       string            `json:"value,omitempty"`
+	TTL         int32             `json:"ttl,omitempty"`
+	Count       *int64            `json:"count,omitempty"`
+	Flag        *bool             `json:"flag,omitempty"`
+	Content     []byte            `json:"content,omitzero"`
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Items       []string          `json:"items,omitempty"`
+}
+
+func (r *Record) Proto() *proto.DumpRecord {
+	res := &proto.DumpRecord{
+		Service:     r.Service,
+		Key:         r.Key,
+		Value:       r.Value,
+		Ttl:         r.TTL,
+		Content:     r.Content,
+		ContentType: r.ContentType,
+		Metadata:    r.Metadata,
+		Items:       r.Items,
+	}
+
+	if r.Count != nil {
+		res.Count = *r.Count
+	}
+
+	if r.Flag != nil {
+		res.Flag = *r.Flag
+	}
+
+	return res
+}
+
+// ImportMode determines what happens to a backend's existing data when a dump is imported.
+type ImportMode string
+
+const (
+	// Merge keeps existing entries and overwrites those that are also in the dump. Set items are added to existing sets.
+	Merge ImportMode = "merge"
+	// Replace flushes the backend before importing the dump.
+	Replace ImportMode = "replace"
+)
+
+// Export calls fn with a record for every entry of every service in the backend, one service at a time.
+func Export(b backend.Service, fn func(r *Record) error) error {
+	for _, svc := range purple.Services {
+		h := handlers[svc]
+
+		if err := eachKey(h.list(b), func(key string) error {
+			val, err := h.read(b, key)
+			if err != nil {
+				if purple.IsNotFound(err) {
+					return nil
+				} else {
+					return err
+				}
+			}
+
+			return fn(h.toRecord(key, val))
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteDump exports the backend to w as newline-delimited JSON and returns the number of records written.
+func WriteDump(b backend.Service, w io.Writer) (int, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	count := 0
+
+	if err := Export(b, func(r *Record) error {
+		count++
+		return enc.Encode(r)
+	}); err != nil {
+		return count, err
+	}
+
+	return count, bw.Flush()
+}
+
+// Import reads a newline-delimited JSON dump from r and writes each record into the backend as soon as it's been read
+// and validated, so dumps of any size are imported in constant memory. In replace mode the backend is flushed right
+// before the first record is written (or at the end of an empty dump), which leaves it untouched if the first record
+// is malformed. A malformed record further into the dump stops the import with the records before it already
+// written. Returns the number of records imported.
+func Import(b backend.Service, r io.Reader, mode ImportMode) (int, error) {
+	if mode != Merge && mode != Replace {
+		return 0, purple.ErrInvalidImportMode
+	}
+
+	dec := json.NewDecoder(r)
+
+	flushed := mode != Replace
+	flush := func() error {
+		if flushed {
+			return nil
+		}
+
+		flushed = true
+
+		return b.Flush()
+	}
+
+	count := 0
+
+	for n := 1; ; n++ {
+		var rec Record
+
+		if err := dec.Decode(&rec); err != nil {
+			if err == io.EOF {
+				return count, flush()
+			}
+
+			return count, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		h, ok := handlers[rec.Service]
+		if !ok || rec.Key == "" {
+			return count, fmt.Errorf("record %d: %w", n, purple.ErrInvalidDumpRecord)
+		}
+
+		val, err := h.fromRecord(&rec)
+		if err != nil {
+			return count, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		if err := flush(); err != nil {
+			return count, err
+		}
+
+		if err := h.write(b, rec.Key, val); err != nil {
+			return count, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		count++
+	}
+}
@@ -0,0 +1,147 @@
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/backend/memory"
+	"github.com/purpledb/purple/proto"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	is := assert.New(t)
+
+	src := memory.NewMemoryBackend()
+
+	is.NoError(src.CacheSet("cached", "value", 60))
+	_, err := src.CounterIncrement("counter", -7)
+	is.NoError(err)
+	is.NoError(src.FlagSet("flag", false))
+	is.NoError(src.KVPut("key", &kv.Value{Content: []byte{0, 1, 2}, ContentType: "application/octet-stream", Metadata: map[string]string{"origin": "test"}}, 0))
+	_, err = src.SetAdd("set", "a")
+	is.NoError(err)
+
+	var buf bytes.Buffer
+
+	count, err := WriteDump(src, &buf)
+	is.NoError(err)
+	is.Equal(count, 5)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	is.Len(lines, 5)
+	is.Equal(lines[1], `{"service":"counter","key":"counter","count":-7}`)
+	is.Equal(lines[2], `{"service":"flag","key":"flag","flag":false}`)
+	is.Equal(lines[3], `{"service":"kv","key":"key","content":"AAEC","content_type":"application/octet-stream","metadata":{"origin":"test"}}`)
+
+	t.Run("Merge", func(t *testing.T) {
+		dst := memory.NewMemoryBackend()
+
+		is.NoError(dst.KVPut("existing", &kv.Value{Content: []byte("kept")}, 0))
+		_, err := dst.SetAdd("set", "b")
+		is.NoError(err)
+
+		count, err := Import(dst, bytes.NewReader(buf.Bytes()), Merge)
+		is.NoError(err)
+		is.Equal(count, 5)
+
+		_, err = dst.KVGet("existing")
+		is.NoError(err)
+
+		items, err := dst.SetGet("set")
+		is.NoError(err)
+		is.Equal(items, []string{"a", "b"})
+
+		val, err := dst.KVGet("key")
+		is.NoError(err)
+		is.Equal(val.Content, []byte{0, 1, 2})
+		is.Equal(val.ContentType, "application/octet-stream")
+		is.Equal(val.Metadata, map[string]string{"origin": "test"})
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		dst := memory.NewMemoryBackend()
+
+		is.NoError(dst.KVPut("existing", &kv.Value{Content: []byte("removed")}, 0))
+
+		count, err := Import(dst, bytes.NewReader(buf.Bytes()), Replace)
+		is.NoError(err)
+		is.Equal(count, 5)
+
+		_, err = dst.KVGet("existing")
+		is.True(purple.IsNotFound(err))
+
+		is.NoError((&Migration{From: src, To: dst}).Verify())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		dst := memory.NewMemoryBackend()
+
+		is.NoError(dst.KVPut("existing", &kv.Value{Content: []byte("kept")}, 0))
+
+		_, err := Import(dst, bytes.NewReader(buf.Bytes()), "overwrite")
+		is.Equal(err, purple.ErrInvalidImportMode)
+
+		for _, dump := range []string{
+			`{"service":"unknown","key":"key"}`,
+			`{"service":"kv","key":""}`,
+			`{"service":"kv","key":"key","ttl":-1}`,
+			`{"service":"counter","key":"counter"}`,
+			`{"service":"set","key":"set","items":[]}`,
+		} {
+			_, err := Import(dst, strings.NewReader(dump), Replace)
+			is.True(errors.Is(err, purple.ErrInvalidDumpRecord), dump)
+		}
+
+		_, err = Import(dst, strings.NewReader("not json"), Replace)
+		is.Error(err)
+
+		// Nothing is flushed when the first record is invalid
+		_, err = dst.KVGet("existing")
+		is.NoError(err)
+
+		// Records are written as they're read, so those before a malformed one are kept
+		count, err := Import(dst, strings.NewReader(`{"service":"flag","key":"first","flag":true}
+{"service":"counter","key":"counter"}`), Merge)
+		is.True(errors.Is(err, purple.ErrInvalidDumpRecord))
+		is.Equal(count, 1)
+		flag, err := dst.FlagGet("first")
+		is.NoError(err)
+		is.True(flag)
+	})
+
+	t.Run("EmptyContent", func(t *testing.T) {
+		src := memory.NewMemoryBackend()
+		is.NoError(src.KVPut("empty", &kv.Value{}, 0))
+
+		var buf bytes.Buffer
+
+		_, err := WriteDump(src, &buf)
+		is.NoError(err)
+		is.Equal(strings.TrimSpace(buf.String()), `{"service":"kv","key":"empty","content":""}`)
+
+		// Dumps written by earlier versions left empty content out
+		for _, dump := range []string{buf.String(), `{"service":"kv","key":"empty"}`} {
+			dst := memory.NewMemoryBackend()
+
+			count, err := Import(dst, strings.NewReader(dump), Replace)
+			is.NoError(err)
+			is.Equal(count, 1)
+
+			val, err := dst.KVGet("empty")
+			is.NoError(err)
+			is.Empty(val.Content)
+		}
+	})
+
+	t.Run("Proto", func(t *testing.T) {
+		count, flag := int64(3), true
+
+		is.Equal((&Record{Service: "counter", Key: "key", Count: &count}).Proto(), &proto.DumpRecord{Service: "counter", Key: "key", Count: 3})
+		is.Equal((&Record{Service: "flag", Key: "key", Flag: &flag}).Proto(), &proto.DumpRecord{Service: "flag", Key: "key", Flag: true})
+	})
+}
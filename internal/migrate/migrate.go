@@ -0,0 +1,409 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/backend"
+	"github.com/purpledb/purple/internal/data"
+	"github.com/purpledb/purple/services/kv"
+)
+
+// How many entries are processed between progress reports
+const progressInterval = 1000
+
+// Migration copies every cache item and KV pair (with their remaining TTLs), counter, flag, and set from one backend to
+// another. Entries that already exist in the destination are overwritten; set members are added to existing sets.
+type Migration struct {
+	From backend.Service
+	To   backend.Service
+	// Whether entries are only read and counted rather than written to the destination
+	DryRun bool
+	// Called every so often while a service is being migrated, and once when it's done, with the number of its
+	// entries processed so far
+	Progress func(service string, count int)
+}
+
+// Summary describes the contents of one service in a backend.
+type Summary struct {
+	Count int
+	// A hex-encoded SHA-256 digest of the service's keys and values (TTLs aren't included)
+	Checksum string
+}
+
+// Reads and writes the entries of one service. Values are passed around as interface{} since each service stores a
+// different type.
+type handler struct {
+	list  func(b backend.Service) func(prefix, cursor string, limit int) ([]string, string, error)
+	read  func(b backend.Service, key string) (interface{}, error)
+	write func(b backend.Service, key string, val interface{}) error
+	// Renders the part of a value that's compared during verification
+	digest func(val interface{}) string
+	// Convert values to and from dump records
+	toRecord   func(key string, val interface{}) *Record
+	fromRecord func(r *Record) (interface{}, error)
+}
+
+type cacheEntry struct {
+	value string
+	ttl   int32
+}
+
+// A KV value along with its remaining TTL, which is 0 if the key doesn't expire
+type kvEntry struct {
+	value *kv.Value
+	ttl   int32
+}
+
+var handlers = map[string]handler{
+	"cache": {
+		list: func(b backend.Service) func(string, string, int) ([]string, string, error) { return b.CacheList },
+		read: func(b backend.Service, key string) (interface{}, error) {
+			val, err := b.CacheGet(key)
+			if err != nil {
+				return nil, err
+			}
+
+			ttl, err := b.CacheTTL(key)
+			if err != nil {
+				return nil, err
+			}
+
+			return cacheEntry{val, ttl}, nil
+		},
+		write: func(b backend.Service, key string, val interface{}) error {
+			entry := val.(cacheEntry)
+			return b.CacheSet(key, entry.value, entry.ttl)
+		},
+		digest: func(val interface{}) string {
+			return val.(cacheEntry).value
+		},
+		toRecord: func(key string, val interface{}) *Record {
+			entry := val.(cacheEntry)
+			return &Record{Service: "cache", Key: key, Value: entry.value, TTL: entry.ttl}
+		},
+		fromRecord: func(r *Record) (interface{}, error) {
+			if r.Value == "" {
+				return nil, purple.ErrInvalidDumpRecord
+			}
+
+			return cacheEntry{r.Value, r.TTL}, nil
+		},
+	},
+	"counter": {
+		list: func(b backend.Service) func(string, string, int) ([]string, string, error) { return b.CounterList },
+		read: func(b backend.Service, key string) (interface{}, error) {
+			return b.CounterGet(key)
+		},
+		// Counters can only be incremented, so the destination's counter is moved by the difference
+		write: func(b backend.Service, key string, val interface{}) error {
+			current, err := b.CounterGet(key)
+			if err != nil {
+				return err
+			}
+
+			_, err = b.CounterIncrement(key, val.(int64)-current)
+			return err
+		},
+		digest: func(val interface{}) string {
+			return strconv.FormatInt(val.(int64), 10)
+		},
+		toRecord: func(key string, val interface{}) *Record {
+			count := val.(int64)
+			return &Record{Service: "counter", Key: key, Count: &count}
+		},
+		fromRecord: func(r *Record) (interface{}, error) {
+			if r.Count == nil {
+				return nil, purple.ErrInvalidDumpRecord
+			}
+
+			return *r.Count, nil
+		},
+	},
+	"flag": {
+		list: func(b backend.Service) func(string, string, int) ([]string, string, error) { return b.FlagList },
+		read: func(b backend.Service, key string) (interface{}, error) {
+			return b.FlagGet(key)
+		},
+		write: func(b backend.Service, key string, val interface{}) error {
+			return b.FlagSet(key, val.(bool))
+		},
+		digest: func(val interface{}) string {
+			return strconv.FormatBool(val.(bool))
+		},
+		toRecord: func(key string, val interface{}) *Record {
+			flag := val.(bool)
+			return &Record{Service: "flag", Key: key, Flag: &flag}
+		},
+		fromRecord: func(r *Record) (interface{}, error) {
+			if r.Flag == nil {
+				return nil, purple.ErrInvalidDumpRecord
+			}
+
+			return *r.Flag, nil
+		},
+	},
+	"kv": {
+		list: func(b backend.Service) func(string, string, int) ([]string, string, error) { return b.KVList },
+		read: func(b backend.Service, key string) (interface{}, error) {
+			val, err := b.KVGet(key)
+			if err != nil {
+				return nil, err
+			}
+
+			ttl, err := b.KVTTL(key)
+			if err != nil {
+				return nil, err
+			}
+
+			if ttl == kv.NoTTL {
+				ttl = 0
+			}
+
+			return kvEntry{val, ttl}, nil
+		},
+		write: func(b backend.Service, key string, val interface{}) error {
+			entry := val.(kvEntry)
+			return b.KVPut(key, entry.value, entry.ttl)
+		},
+		// Versions and timestamps are assigned by each backend, so only the content, content type, and metadata are
+		// compared and carried over
+		digest: func(val interface{}) string {
+			v := val.(kvEntry).value
+
+			fields := make([]string, 0, len(v.Metadata))
+			for k, m := range v.Metadata {
+				fields = append(fields, k+"="+m)
+			}
+			sort.Strings(fields)
+
+			return strings.Join(append([]string{string(v.Content), v.ContentType}, fields...), "\x00")
+		},
+		toRecord: func(key string, val interface{}) *Record {
+			entry := val.(kvEntry)
+			v := entry.value
+
+			// Empty content is written out as "" rather than left out
+			content := v.Content
+			if content == nil {
+				content = []byte{}
+			}
+
+			return &Record{Service: "kv", Key: key, Content: content, ContentType: v.ContentType, Metadata: v.Metadata, TTL: entry.ttl}
+		},
+		// Records without content hold an empty value; dumps written by earlier versions left empty content out
+		fromRecord: func(r *Record) (interface{}, error) {
+			if r.TTL < 0 {
+				return nil, purple.ErrInvalidDumpRecord
+			}
+
+			return kvEntry{&kv.Value{Content: r.Content, ContentType: r.ContentType, Metadata: r.Metadata}, r.TTL}, nil
+		},
+	},
+	"set": {
+		list: func(b backend.Service) func(string, string, int) ([]string, string, error) { return b.SetList },
+		// Sets without members can't be created through the set service, so they're treated as missing
+		read: func(b backend.Service, key string) (interface{}, error) {
+			items, err := b.SetGet(key)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(items) == 0 {
+				return nil, purple.NotFound(key)
+			}
+
+			return items, nil
+		},
+		write: func(b backend.Service, key string, val interface{}) error {
+			for _, item := range val.([]string) {
+				if _, err := b.SetAdd(key, item); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		digest: func(val interface{}) string {
+			items := append([]string{}, val.([]string)...)
+			sort.Strings(items)
+			return strings.Join(items, "\x00")
+		},
+		toRecord: func(key string, val interface{}) *Record {
+			return &Record{Service: "set", Key: key, Items: val.([]string)}
+		},
+		fromRecord: func(r *Record) (interface{}, error) {
+			if len(r.Items) == 0 {
+				return nil, purple.ErrInvalidDumpRecord
+			}
+
+			return r.Items, nil
+		},
+	},
+}
+
+// Run copies every service's entries and returns the number of entries copied per service. Entries that disappear
+// (e.g. cache items that expire) between being listed and being read are skipped.
+func (m *Migration) Run() (map[string]int, error) {
+	if m.From == m.To {
+		return nil, purple.ErrSameBackend
+	}
+
+	counts := make(map[string]int, len(purple.Services))
+
+	for _, svc := range purple.Services {
+		h := handlers[svc]
+
+		count := 0
+
+		if err := eachKey(h.list(m.From), func(key string) error {
+			val, err := h.read(m.From, key)
+			if err != nil {
+				if purple.IsNotFound(err) {
+					return nil
+				} else {
+					return err
+				}
+			}
+
+			if !m.DryRun {
+				if err := h.write(m.To, key, val); err != nil {
+					return fmt.Errorf("%s %q: %w", svc, key, err)
+				}
+			}
+
+			count++
+
+			if m.Progress != nil && count%progressInterval == 0 {
+				m.Progress(svc, count)
+			}
+
+			return nil
+		}); err != nil {
+			return counts, err
+		}
+
+		counts[svc] = count
+
+		if m.Progress != nil {
+			m.Progress(svc, count)
+		}
+	}
+
+	return counts, nil
+}
+
+// Verify compares the entry counts and checksums of every service in the two backends and returns an error naming
+// the services that differ. Cache items that expire while the backends are summarized show up as differences.
+func (m *Migration) Verify() error {
+	from, err := Summarize(m.From)
+	if err != nil {
+		return err
+	}
+
+	to, err := Summarize(m.To)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []string
+
+	for _, svc := range purple.Services {
+		if from[svc] != to[svc] {
+			mismatches = append(mismatches, fmt.Sprintf("%s (%d entries in source, %d in destination)", svc, from[svc].Count, to[svc].Count))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%w: %s", purple.ErrVerificationFailed, strings.Join(mismatches, ", "))
+	}
+
+	return nil
+}
+
+// Summarize counts the entries of every service in a backend and computes a checksum over their keys and values,
+// which doesn't depend on the order in which the backend lists them.
+func Summarize(b backend.Service) (map[string]Summary, error) {
+	summaries := make(map[string]Summary, len(purple.Services))
+
+	for _, svc := range purple.Services {
+		h := handlers[svc]
+
+		digests := make(map[string]string)
+
+		if err := eachKey(h.list(b), func(key string) error {
+			val, err := h.read(b, key)
+			if err != nil {
+				if purple.IsNotFound(err) {
+					return nil
+				} else {
+					return err
+				}
+			}
+
+			digests[key] = h.digest(val)
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(digests))
+		for key := range digests {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		sum := sha256.New()
+
+		for _, key := range keys {
+			_, _ = fmt.Fprintf(sum, "%q=%q\n", key, digests[key])
+		}
+
+		summaries[svc] = Summary{
+			Count:    len(keys),
+			Checksum: hex.EncodeToString(sum.Sum(nil)),
+		}
+	}
+
+	return summaries, nil
+}
+
+// Calls fn once for every key returned by list, following cursors until the listing is complete. Some backends (e.g.
+// Redis) may list a key more than once, so keys that have already been seen are skipped.
+func eachKey(list func(prefix, cursor string, limit int) ([]string, string, error), fn func(key string) error) error {
+	seen := make(map[string]bool)
+
+	cursor := ""
+
+	for {
+		keys, next, err := list("", cursor, data.MaxListLimit)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+
+		cursor = next
+	}
+}
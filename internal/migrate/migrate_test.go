@@ -0,0 +1,101 @@
+package migrate
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/backend/memory"
+	"github.com/purpledb/purple/internal/backend/sqlite"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigration(t *testing.T) {
+	is := assert.New(t)
+
+	from := memory.NewMemoryBackend()
+
+	to, err := sqlite.NewSqliteBackend(&purple.SqliteConfig{Path: filepath.Join(t.TempDir(), "purple.db")})
+	is.NoError(err)
+	defer to.Close()
+
+	is.NoError(from.CacheSet("cached", "value", 60))
+	_, err = from.CounterIncrement("counter", 42)
+	is.NoError(err)
+	is.NoError(from.FlagSet("flag", true))
+	is.NoError(from.KVPut("key", &kv.Value{Content: []byte("content")}, 0))
+	_, err = from.SetAdd("set", "a")
+	is.NoError(err)
+	_, err = from.SetAdd("set", "b")
+	is.NoError(err)
+
+	// Counters that already exist in the destination end up with the source's value
+	_, err = to.CounterIncrement("counter", 100)
+	is.NoError(err)
+
+	t.Run("DryRun", func(t *testing.T) {
+		m := &Migration{From: from, To: to, DryRun: true}
+
+		counts, err := m.Run()
+		is.NoError(err)
+		is.Equal(counts, map[string]int{"cache": 1, "counter": 1, "flag": 1, "kv": 1, "set": 1})
+
+		_, err = to.KVGet("key")
+		is.True(purple.IsNotFound(err))
+
+		is.True(errors.Is(m.Verify(), purple.ErrVerificationFailed))
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		var progress []string
+
+		m := &Migration{
+			From: from,
+			To:   to,
+			Progress: func(service string, _ int) {
+				progress = append(progress, service)
+			},
+		}
+
+		counts, err := m.Run()
+		is.NoError(err)
+		is.Equal(counts, map[string]int{"cache": 1, "counter": 1, "flag": 1, "kv": 1, "set": 1})
+		is.Equal(progress, purple.Services)
+
+		val, err := to.CacheGet("cached")
+		is.NoError(err)
+		is.Equal(val, "value")
+
+		ttl, err := to.CacheTTL("cached")
+		is.NoError(err)
+		is.InDelta(60, ttl, 2)
+
+		count, err := to.CounterGet("counter")
+		is.NoError(err)
+		is.Equal(count, int64(42))
+
+		items, err := to.SetGet("set")
+		is.NoError(err)
+		is.Equal(items, []string{"a", "b"})
+
+		is.NoError(m.Verify())
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		m := &Migration{From: from, To: to}
+
+		is.NoError(to.KVPut("key", &kv.Value{Content: []byte("changed")}, 0))
+
+		err := m.Verify()
+		is.True(errors.Is(err, purple.ErrVerificationFailed))
+		is.Contains(err.Error(), "kv")
+		is.NotContains(err.Error(), "counter")
+	})
+
+	t.Run("SameBackend", func(t *testing.T) {
+		_, err := (&Migration{From: from, To: from}).Run()
+		is.Equal(err, purple.ErrSameBackend)
+	})
+}
@@ -2,37 +2,51 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
-	"github.com/purpledb/purple/internal/services/kv"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
+	"github.com/purpledb/purple/services/txn"
 
 	"github.com/purpledb/purple"
 	"github.com/purpledb/purple/internal/backend"
+	"github.com/purpledb/purple/internal/migrate"
 
 	"github.com/purpledb/purple/proto"
 
 	"github.com/sirupsen/logrus"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
 	address string
 	srv     *grpc.Server
 	backend backend.Service
-	log     *logrus.Entry
+	// The open transaction sessions
+	sessions *txn.Sessions
+	log      *logrus.Entry
 }
 
 var (
+	_ proto.AdminServer   = (*Server)(nil)
 	_ proto.CacheServer   = (*Server)(nil)
 	_ proto.CounterServer = (*Server)(nil)
 	_ proto.FlagServer    = (*Server)(nil)
 	_ proto.KVServer      = (*Server)(nil)
 	_ proto.SetServer     = (*Server)(nil)
+	_ proto.TxnServer     = (*Server)(nil)
 )
 
 func NewGrpcServer(cfg *purple.ServerConfig) (*Server, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	addr := fmt.Sprintf(":%d", cfg.Port)
 
 	srv := grpc.NewServer()
@@ -51,13 +65,68 @@ func NewGrpcServer(cfg *purple.ServerConfig) (*Server, error) {
 	log := logger.WithField("server", "grpc")
 
 	return &Server{
-		address: addr,
-		srv:     srv,
-		backend: bk,
-		log:     log,
+		address:  addr,
+		srv:      srv,
+		backend:  bk,
+		sessions: txn.NewSessions(txn.SessionTimeout, cfg.TxnMaxSessions),
+		log:      log,
+	}, nil
+}
+
+// Admin
+
+// Dump streams a record for every entry in the backend.
+func (s *Server) Dump(_ *proto.Empty, stream proto.Admin_DumpServer) error {
+	return migrate.Export(s.backend, func(r *migrate.Record) error {
+		return stream.Send(r.Proto())
+	})
+}
+
+// Backup writes an online backup of the disk backend to its backup directory.
+func (s *Server) Backup(_ context.Context, req *proto.BackupRequest) (*proto.BackupResponse, error) {
+	info, err := backend.Backup(s.backend, req.Since)
+	if err != nil {
+		if errors.Is(err, purple.ErrBackupNotSupported) || err == purple.ErrNoBackupDir {
+			err = status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		return nil, err
+	}
+
+	return &proto.BackupResponse{
+		Path:  info.Path,
+		Since: info.Since,
+		Next:  info.Next,
 	}, nil
 }
 
+// Stats reports the statistics kept by the backends behind the server: the hit rate of a tiered LRU and the cache
+// expiry and eviction counts of the memory backend.
+func (s *Server) Stats(_ context.Context, _ *proto.Empty) (*proto.StatsResponse, error) {
+	stats := backend.CollectStats(s.backend)
+
+	res := &proto.StatsResponse{}
+
+	if t := stats.Tiered; t != nil {
+		res.Tiered = &proto.TieredStats{
+			Hits:      t.Hits,
+			Misses:    t.Misses,
+			Evictions: t.Evictions,
+			Size:      int64(t.Size),
+		}
+	}
+
+	if m := stats.Memory; m != nil {
+		res.Memory = &proto.MemoryStats{
+			CacheEntries: int64(m.CacheEntries),
+			Expired:      m.Expired,
+			Evicted:      m.Evicted,
+		}
+	}
+
+	return res, nil
+}
+
 // Cache
 func (s *Server) CacheGet(_ context.Context, req *proto.CacheGetRequest) (*proto.CacheGetResponse, error) {
 	val, err := s.backend.CacheGet(req.Key)
@@ -84,6 +153,10 @@ func (s *Server) CacheSet(_ context.Context, req *proto.CacheSetRequest) (*proto
 	return &proto.Empty{}, nil
 }
 
+func (s *Server) CacheList(_ context.Context, req *proto.ListRequest) (*proto.ListResponse, error) {
+	return listResponse(s.backend.CacheList(req.Prefix, req.Cursor, int(req.Limit)))
+}
+
 // Counter
 func (s *Server) CounterGet(_ context.Context, req *proto.GetCounterRequest) (*proto.GetCounterResponse, error) {
 	val, err := s.backend.CounterGet(req.Key)
@@ -107,6 +180,10 @@ func (s *Server) CounterIncrement(_ context.Context, req *proto.IncrementCounter
 	}, nil
 }
 
+func (s *Server) CounterList(_ context.Context, req *proto.ListRequest) (*proto.ListResponse, error) {
+	return listResponse(s.backend.CounterList(req.Prefix, req.Cursor, int(req.Limit)))
+}
+
 // Flag
 func (s *Server) FlagGet(_ context.Context, req *proto.FlagGetRequest) (*proto.FlagResponse, error) {
 	val, err := s.backend.FlagGet(req.Key)
@@ -127,6 +204,10 @@ func (s *Server) FlagSet(_ context.Context, req *proto.FlagSetRequest) (*proto.E
 	return &proto.Empty{}, nil
 }
 
+func (s *Server) FlagList(_ context.Context, req *proto.ListRequest) (*proto.ListResponse, error) {
+	return listResponse(s.backend.FlagList(req.Prefix, req.Cursor, int(req.Limit)))
+}
+
 // KV
 func (s *Server) KVGet(_ context.Context, location *proto.Location) (*proto.GetResponse, error) {
 	key := location.Key
@@ -141,9 +222,7 @@ func (s *Server) KVGet(_ context.Context, location *proto.Location) (*proto.GetR
 	}
 
 	res := &proto.GetResponse{
-		Value: &proto.Value{
-			Content: val.Content,
-		},
+		Value: val.Proto(),
 	}
 
 	return res, nil
@@ -152,12 +231,34 @@ func (s *Server) KVGet(_ context.Context, location *proto.Location) (*proto.GetR
 func (s *Server) KVPut(_ context.Context, req *proto.PutRequest) (*proto.Empty, error) {
 	key := req.Location.Key
 
-	val := &kv.Value{
-		Content: req.Value.Content,
+	val := kv.FromProto(req.Value)
+
+	if err := s.backend.KVPut(key, val, req.Ttl); err != nil {
+		return nil, kvStatus(key, err)
 	}
 
-	if err := s.backend.KVPut(key, val); err != nil {
-		return nil, err
+	return &proto.Empty{}, nil
+}
+
+func (s *Server) KVPutIfVersion(_ context.Context, req *proto.PutIfVersionRequest) (*proto.Empty, error) {
+	key := req.Location.Key
+
+	val := kv.FromProto(req.Value)
+
+	if err := s.backend.KVPutIfVersion(key, val, req.Version, req.Ttl); err != nil {
+		return nil, kvStatus(key, err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
+func (s *Server) KVPutIfAbsent(_ context.Context, req *proto.PutRequest) (*proto.Empty, error) {
+	key := req.Location.Key
+
+	val := kv.FromProto(req.Value)
+
+	if err := s.backend.KVPutIfAbsent(key, val, req.Ttl); err != nil {
+		return nil, kvStatus(key, err)
 	}
 
 	return &proto.Empty{}, nil
@@ -173,8 +274,113 @@ func (s *Server) KVDelete(_ context.Context, location *proto.Location) (*proto.E
 	return &proto.Empty{}, nil
 }
 
+func (s *Server) KVDeleteIfVersion(_ context.Context, req *proto.DeleteIfVersionRequest) (*proto.Empty, error) {
+	if err := s.backend.KVDeleteIfVersion(req.Location.Key, req.Version); err != nil {
+		return nil, kvStatus(req.Location.Key, err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
+func (s *Server) KVTTL(_ context.Context, location *proto.Location) (*proto.TTLResponse, error) {
+	key := location.Key
+
+	ttl, err := s.backend.KVTTL(key)
+	if err != nil {
+		if purple.IsNotFound(err) {
+			err = purple.NotFound(key).AsProtoStatus()
+		}
+
+		return nil, err
+	}
+
+	return &proto.TTLResponse{
+		Ttl: ttl,
+	}, nil
+}
+
+func (s *Server) KVExpire(_ context.Context, req *proto.ExpireRequest) (*proto.Empty, error) {
+	if err := s.backend.KVExpire(req.Location.Key, req.Ttl); err != nil {
+		return nil, kvStatus(req.Location.Key, err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
+func (s *Server) KVPersist(_ context.Context, location *proto.Location) (*proto.Empty, error) {
+	if err := s.backend.KVPersist(location.Key); err != nil {
+		return nil, kvStatus(location.Key, err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
+func (s *Server) KVList(_ context.Context, req *proto.ListRequest) (*proto.ListResponse, error) {
+	return listResponse(s.backend.KVList(req.Prefix, req.Cursor, int(req.Limit)))
+}
+
+func (s *Server) KVGetMany(_ context.Context, req *proto.GetManyRequest) (*proto.GetManyResponse, error) {
+	values, err := s.backend.KVGetMany(req.Keys)
+	if err != nil {
+		return nil, kvStatus("", err)
+	}
+
+	res := &proto.GetManyResponse{
+		Results: make([]*proto.GetResult, len(req.Keys)),
+	}
+
+	for i, key := range req.Keys {
+		res.Results[i] = &proto.GetResult{Key: key}
+
+		if values[i] != nil {
+			res.Results[i].Found, res.Results[i].Value = true, values[i].Proto()
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Server) KVPutMany(_ context.Context, req *proto.PutManyRequest) (*proto.Empty, error) {
+	entries := make([]*kv.Entry, len(req.Entries))
+
+	for i, e := range req.Entries {
+		entries[i] = &kv.Entry{
+			Key:   e.Location.Key,
+			Value: kv.FromProto(e.Value),
+			TTL:   e.Ttl,
+		}
+	}
+
+	if err := s.backend.KVPutMany(entries); err != nil {
+		return nil, kvStatus("", err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
+func (s *Server) KVDeleteMany(_ context.Context, req *proto.DeleteManyRequest) (*proto.DeleteManyResponse, error) {
+	existed, err := s.backend.KVDeleteMany(req.Keys)
+	if err != nil {
+		return nil, kvStatus("", err)
+	}
+
+	res := &proto.DeleteManyResponse{
+		Results: make([]*proto.DeleteResult, len(req.Keys)),
+	}
+
+	for i, key := range req.Keys {
+		res.Results[i] = &proto.DeleteResult{Key: key, Found: existed[i]}
+	}
+
+	return res, nil
+}
+
 // Sets
 func (s *Server) SetGet(_ context.Context, req *proto.GetSetRequest) (*proto.SetResponse, error) {
+	if req.Cursor != "" || req.Limit != 0 {
+		return s.setPage(req)
+	}
+
 	items, err := s.backend.SetGet(req.Set)
 	if err != nil {
 		if purple.IsNotFound(err) {
@@ -190,6 +396,10 @@ func (s *Server) SetGet(_ context.Context, req *proto.GetSetRequest) (*proto.Set
 }
 
 func (s *Server) SetAdd(_ context.Context, req *proto.ModifySetRequest) (*proto.SetResponse, error) {
+	if req.Summary {
+		return setSummaryResponse(s.backend.SetAddSummary(req.Set, req.Item))
+	}
+
 	items, err := s.backend.SetAdd(req.Set, req.Item)
 	if err != nil {
 		if purple.IsNotFound(err) {
@@ -205,6 +415,10 @@ func (s *Server) SetAdd(_ context.Context, req *proto.ModifySetRequest) (*proto.
 }
 
 func (s *Server) SetRemove(_ context.Context, req *proto.ModifySetRequest) (*proto.SetResponse, error) {
+	if req.Summary {
+		return setSummaryResponse(s.backend.SetRemoveSummary(req.Set, req.Item))
+	}
+
 	items, err := s.backend.SetRemove(req.Set, req.Item)
 	if err != nil {
 		if purple.IsNotFound(err) {
@@ -223,7 +437,257 @@ var emptySetRes = &proto.SetResponse{
 	Items: []string{},
 }
 
+// Like the sets themselves, the pages of sets that don't exist are empty.
+func (s *Server) setPage(req *proto.GetSetRequest) (*proto.SetResponse, error) {
+	items, next, err := s.backend.SetGetPage(req.Set, req.Cursor, int(req.Limit))
+	if err != nil {
+		if purple.IsNotFound(err) {
+			return emptySetRes, nil
+		} else if err == purple.ErrInvalidCursor {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, err
+	}
+
+	return &proto.SetResponse{
+		Items:      items,
+		NextCursor: next,
+	}, nil
+}
+
+// Removing an item from a set that doesn't exist changes nothing.
+func setSummaryResponse(summary *set.Summary, err error) (*proto.SetResponse, error) {
+	if err != nil {
+		if purple.IsNotFound(err) {
+			return &proto.SetResponse{}, nil
+		}
+
+		return nil, err
+	}
+
+	return &proto.SetResponse{
+		Changed: summary.Changed,
+		Size:    int64(summary.Size),
+	}, nil
+}
+
+func (s *Server) SetRandom(_ context.Context, req *proto.RandomSetRequest) (*proto.SetResponse, error) {
+	return setResponse(s.backend.SetRandom(req.Set, int(req.Count)))
+}
+
+func (s *Server) SetPop(_ context.Context, req *proto.PopSetRequest) (*proto.SetResponse, error) {
+	return setResponse(s.backend.SetPop(req.Set, int(req.Count)))
+}
+
+func (s *Server) SetContains(_ context.Context, req *proto.SetContainsRequest) (*proto.SetContainsResponse, error) {
+	contains, err := s.backend.SetContains(req.Set, req.Item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.SetContainsResponse{
+		Contains: contains,
+	}, nil
+}
+
+func (s *Server) SetSize(_ context.Context, req *proto.GetSetRequest) (*proto.SetSizeResponse, error) {
+	return setSizeResponse(s.backend.SetSize(req.Set))
+}
+
+func (s *Server) SetUnion(_ context.Context, req *proto.CombineSetsRequest) (*proto.SetResponse, error) {
+	return setResponse(s.backend.SetUnion(req.Sets...))
+}
+
+func (s *Server) SetIntersect(_ context.Context, req *proto.CombineSetsRequest) (*proto.SetResponse, error) {
+	return setResponse(s.backend.SetIntersect(req.Sets...))
+}
+
+func (s *Server) SetDiff(_ context.Context, req *proto.CombineSetsRequest) (*proto.SetResponse, error) {
+	return setResponse(s.backend.SetDiff(req.Sets...))
+}
+
+func (s *Server) SetUnionStore(_ context.Context, req *proto.StoreSetsRequest) (*proto.SetSizeResponse, error) {
+	return setSizeResponse(s.backend.SetUnionStore(req.Destination, req.Sets...))
+}
+
+func (s *Server) SetIntersectStore(_ context.Context, req *proto.StoreSetsRequest) (*proto.SetSizeResponse, error) {
+	return setSizeResponse(s.backend.SetIntersectStore(req.Destination, req.Sets...))
+}
+
+func (s *Server) SetDiffStore(_ context.Context, req *proto.StoreSetsRequest) (*proto.SetSizeResponse, error) {
+	return setSizeResponse(s.backend.SetDiffStore(req.Destination, req.Sets...))
+}
+
+// Converts the result of combining, sampling, or popping sets into a SetResponse.
+func setResponse(items []string, err error) (*proto.SetResponse, error) {
+	if err != nil {
+		return nil, setStatus(err)
+	}
+
+	return &proto.SetResponse{
+		Items: items,
+	}, nil
+}
+
+func setSizeResponse(size int, err error) (*proto.SetSizeResponse, error) {
+	if err != nil {
+		return nil, setStatus(err)
+	}
+
+	return &proto.SetSizeResponse{
+		Size: int64(size),
+	}, nil
+}
+
+// Missing set names and negative pop counts are InvalidArgument.
+func setStatus(err error) error {
+	if err == purple.ErrNoSets || err == purple.ErrNoKey || err == purple.ErrNegativeSetPopCount {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return err
+}
+
+func (s *Server) SetList(_ context.Context, req *proto.ListRequest) (*proto.ListResponse, error) {
+	return listResponse(s.backend.SetList(req.Prefix, req.Cursor, int(req.Limit)))
+}
+
+// Txn
+func (s *Server) Txn(_ context.Context, req *proto.TxnRequest) (*proto.TxnResponse, error) {
+	return txnResponse(s.backend.Txn(txnOps(req.Ops)))
+}
+
+func (s *Server) Begin(_ context.Context, _ *proto.Empty) (*proto.TxnSession, error) {
+	id, err := s.sessions.Begin(s.backend)
+	if err != nil {
+		return nil, txnStatus(err)
+	}
+
+	return &proto.TxnSession{Id: id}, nil
+}
+
+func (s *Server) Read(_ context.Context, req *proto.TxnSessionRequest) (*proto.TxnResponse, error) {
+	return txnResponse(s.sessions.Read(req.Id, txnOps(req.Ops)))
+}
+
+func (s *Server) Commit(_ context.Context, req *proto.TxnSessionRequest) (*proto.TxnResponse, error) {
+	return txnResponse(s.sessions.Commit(req.Id, txnOps(req.Ops)))
+}
+
+func (s *Server) Abort(_ context.Context, req *proto.TxnSession) (*proto.Empty, error) {
+	if err := s.sessions.Abort(req.Id); err != nil {
+		return nil, txnStatus(err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
+func txnOps(pbOps []*proto.TxnOp) []*txn.Op {
+	ops := make([]*txn.Op, len(pbOps))
+
+	for i, op := range pbOps {
+		ops[i] = &txn.Op{
+			Kind:   op.Kind,
+			Key:    op.Key,
+			TTL:    op.Ttl,
+			Amount: op.Amount,
+			Flag:   op.Flag,
+			Item:   op.Item,
+		}
+
+		if op.Value != nil {
+			ops[i].Value = kv.FromProto(op.Value)
+		}
+	}
+
+	return ops
+}
+
+func txnResponse(results []*txn.Result, err error) (*proto.TxnResponse, error) {
+	if err != nil {
+		return nil, txnStatus(err)
+	}
+
+	res := &proto.TxnResponse{
+		Results: make([]*proto.TxnResult, len(results)),
+	}
+
+	for i, r := range results {
+		res.Results[i] = &proto.TxnResult{
+			Found: r.Found,
+			Count: r.Count,
+			Flag:  r.Flag,
+			Items: r.Items,
+		}
+
+		if r.Value != nil {
+			res.Results[i].Value = r.Value.Proto()
+		}
+	}
+
+	return res, nil
+}
+
+// Converts the errors of transactions into statuses: conflicts, transactions that span backends, and Redis keys that
+// the operations can't be applied to are FailedPrecondition, unknown sessions NotFound, sessions beyond the maximum
+// ResourceExhausted, and invalid operations InvalidArgument.
+func txnStatus(err error) error {
+	if purple.IsConflict(err) {
+		return err.(purple.ConflictError).AsProtoStatus()
+	}
+
+	switch err {
+	case purple.ErrTxnSpansBackends, purple.ErrRedisWrongType, purple.ErrRedisCounterInvalid:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case purple.ErrTxnSessionNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case purple.ErrTooManyTxnSessions:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case purple.ErrTxnTooLarge, purple.ErrUnknownTxnOp, purple.ErrTxnSessionWrite, purple.ErrNoKey, purple.ErrNoValue,
+		purple.ErrNegativeKVTTL:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return err
+}
+
+// Converts the errors of KV writes into statuses: version conflicts are FailedPrecondition, missing keys NotFound, and
+// invalid TTLs and batches InvalidArgument.
+func kvStatus(key string, err error) error {
+	switch {
+	case purple.IsConflict(err):
+		return purple.Conflict(key).AsProtoStatus()
+	case purple.IsNotFound(err):
+		return purple.NotFound(key).AsProtoStatus()
+	case err == purple.ErrNegativeKVTTL || err == purple.ErrNonPositiveKVTTL || err == purple.ErrKVBatchTooLarge:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return err
+}
+
+// Converts a page of keys returned by one of the backend's List methods into a ListResponse.
+func listResponse(keys []string, next string, err error) (*proto.ListResponse, error) {
+	if err != nil {
+		if err == purple.ErrInvalidCursor {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, err
+	}
+
+	return &proto.ListResponse{
+		Keys:       keys,
+		NextCursor: next,
+	}, nil
+}
+
 func (s *Server) Start() error {
+	proto.RegisterAdminServer(s.srv, s)
+
+	s.log.Debug("registered gRPC admin service")
+
 	proto.RegisterCacheServer(s.srv, s)
 
 	s.log.Debug("registered gRPC cache service")
@@ -232,6 +696,10 @@ func (s *Server) Start() error {
 
 	s.log.Debug("registered gRPC counter service")
 
+	proto.RegisterFlagServer(s.srv, s)
+
+	s.log.Debug("registered gRPC flag service")
+
 	proto.RegisterKVServer(s.srv, s)
 
 	s.log.Debug("registered gRPC KV service")
@@ -240,6 +708,10 @@ func (s *Server) Start() error {
 
 	s.log.Debug("registered gRPC set service")
 
+	proto.RegisterTxnServer(s.srv, s)
+
+	s.log.Debug("registered gRPC transaction service")
+
 	lis, err := net.Listen("tcp", s.address)
 
 	if err != nil {
@@ -254,6 +726,8 @@ func (s *Server) Start() error {
 func (s *Server) ShutDown() error {
 	s.log.Debug("shutting down")
 
+	s.sessions.Close()
+
 	if err := s.backend.Close(); err != nil {
 		return err
 	}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/purpledb/purple/proto"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -102,7 +103,9 @@ func TestGrpcServer(t *testing.T) {
 				Key: "key",
 			},
 			Value: &proto.Value{
-				Content: []byte("some content"),
+				Content:     []byte("some content"),
+				ContentType: "text/plain",
+				Metadata:    map[string]string{"lang": "en"},
 			},
 		}
 
@@ -114,6 +117,63 @@ func TestGrpcServer(t *testing.T) {
 		is.NoError(err)
 		is.NotNil(val)
 		is.Equal(val.Value.Content, []byte("some content"))
+		is.Equal(val.Value.ContentType, "text/plain")
+		is.Equal(val.Value.Metadata, map[string]string{"lang": "en"})
+		is.NotZero(val.Value.Created)
+		is.Equal(val.Value.Created, val.Value.Updated)
+
+		version := val.Value.Version
+
+		_, err = srv.KVPutIfAbsent(ctx, putReq)
+		stat, ok = status.FromError(err)
+		is.True(ok)
+		is.Equal(stat.Code(), codes.FailedPrecondition)
+
+		casReq := &proto.PutIfVersionRequest{
+			Location: locationReq,
+			Value: &proto.Value{
+				Content: []byte("new content"),
+			},
+			Version: version,
+		}
+
+		empty, err = srv.KVPutIfVersion(ctx, casReq)
+		is.NoError(err)
+		is.NotNil(empty)
+
+		_, err = srv.KVPutIfVersion(ctx, casReq)
+		stat, ok = status.FromError(err)
+		is.True(ok)
+		is.Equal(stat.Code(), codes.FailedPrecondition)
+
+		_, err = srv.KVDeleteIfVersion(ctx, &proto.DeleteIfVersionRequest{Location: locationReq, Version: version})
+		stat, ok = status.FromError(err)
+		is.True(ok)
+		is.Equal(stat.Code(), codes.FailedPrecondition)
+
+		_, err = srv.KVPut(ctx, &proto.PutRequest{Location: locationReq, Value: putReq.Value, Ttl: 30})
+		is.NoError(err)
+
+		ttl, err := srv.KVTTL(ctx, locationReq)
+		is.NoError(err)
+		is.InDelta(30, ttl.Ttl, 1)
+
+		_, err = srv.KVPersist(ctx, locationReq)
+		is.NoError(err)
+
+		ttl, err = srv.KVTTL(ctx, locationReq)
+		is.NoError(err)
+		is.Equal(ttl.Ttl, int32(-1))
+
+		_, err = srv.KVExpire(ctx, &proto.ExpireRequest{Location: locationReq, Ttl: 0})
+		stat, ok = status.FromError(err)
+		is.True(ok)
+		is.Equal(stat.Code(), codes.InvalidArgument)
+
+		_, err = srv.KVExpire(ctx, &proto.ExpireRequest{Location: &proto.Location{Key: "missing"}, Ttl: 5})
+		stat, ok = status.FromError(err)
+		is.True(ok)
+		is.Equal(stat.Code(), codes.NotFound)
 
 		empty, err = srv.KVDelete(ctx, locationReq)
 		is.NoError(err)
@@ -125,6 +185,39 @@ func TestGrpcServer(t *testing.T) {
 		is.Equal(stat.Code(), codes.NotFound)
 	})
 
+	t.Run("KVBatch", func(_ *testing.T) {
+		_, err := srv.KVPutMany(ctx, &proto.PutManyRequest{
+			Entries: []*proto.PutRequest{
+				{Location: &proto.Location{Key: "one"}, Value: &proto.Value{Content: []byte("1")}},
+				{Location: &proto.Location{Key: "two"}, Value: &proto.Value{Content: []byte("2")}, Ttl: 30},
+			},
+		})
+		is.NoError(err)
+
+		got, err := srv.KVGetMany(ctx, &proto.GetManyRequest{Keys: []string{"one", "missing", "two"}})
+		is.NoError(err)
+		is.Len(got.Results, 3)
+		is.True(got.Results[0].Found)
+		is.Equal(got.Results[0].Value.Content, []byte("1"))
+		is.False(got.Results[1].Found)
+		is.Nil(got.Results[1].Value)
+		is.Equal(got.Results[2].Key, "two")
+		is.Equal(got.Results[2].Value.Content, []byte("2"))
+
+		deleted, err := srv.KVDeleteMany(ctx, &proto.DeleteManyRequest{Keys: []string{"one", "two", "missing"}})
+		is.NoError(err)
+		is.True(deleted.Results[0].Found)
+		is.True(deleted.Results[1].Found)
+		is.False(deleted.Results[2].Found)
+
+		_, err = srv.KVPutMany(ctx, &proto.PutManyRequest{
+			Entries: []*proto.PutRequest{{Location: &proto.Location{Key: "one"}, Value: &proto.Value{}, Ttl: -1}},
+		})
+		stat, ok := status.FromError(err)
+		is.True(ok)
+		is.Equal(stat.Code(), codes.InvalidArgument)
+	})
+
 	t.Run("Set", func(_ *testing.T) {
 		getReq := &proto.GetSetRequest{
 			Set: "set1",
@@ -156,7 +249,205 @@ func TestGrpcServer(t *testing.T) {
 		is.Equal(set.Items, []string{})
 	})
 
+	t.Run("List", func(_ *testing.T) {
+		for _, key := range []string{"list:1", "list:2", "list:3"} {
+			_, err := srv.KVPut(ctx, &proto.PutRequest{
+				Location: &proto.Location{Key: key},
+				Value:    &proto.Value{Content: []byte("content")},
+			})
+			is.NoError(err)
+		}
+
+		res, err := srv.KVList(ctx, &proto.ListRequest{Prefix: "list:", Limit: 2})
+		is.NoError(err)
+		is.Equal(res.Keys, []string{"list:1", "list:2"})
+		is.Equal(res.NextCursor, "list:2")
+
+		res, err = srv.KVList(ctx, &proto.ListRequest{Prefix: "list:", Cursor: res.NextCursor, Limit: 2})
+		is.NoError(err)
+		is.Equal(res.Keys, []string{"list:3"})
+		is.Empty(res.NextCursor)
+
+		res, err = srv.SetList(ctx, &proto.ListRequest{})
+		is.NoError(err)
+		is.Equal(res.Keys, []string{"set1"})
+	})
+
+	t.Run("Dump", func(_ *testing.T) {
+		stream := &dumpStream{}
+
+		is.NoError(srv.Dump(&proto.Empty{}, stream))
+		is.NotEmpty(stream.records)
+
+		services := make(map[string]bool)
+		for _, r := range stream.records {
+			services[r.Service] = true
+		}
+
+		is.True(services["counter"])
+		is.True(services["kv"])
+	})
+
+	t.Run("Stats", func(_ *testing.T) {
+		// The memory backend isn't tiered, so there are no LRU stats to report
+		res, err := srv.Stats(ctx, &proto.Empty{})
+		is.NoError(err)
+		is.Nil(res.Tiered)
+		is.NotNil(res.Memory)
+	})
+
+	t.Run("Txn", func(_ *testing.T) {
+		res, err := srv.Txn(ctx, &proto.TxnRequest{
+			Ops: []*proto.TxnOp{
+				{Kind: "set.add", Key: "team", Item: "alice"},
+				{Kind: "counter.incr", Key: "team-size", Amount: 1},
+				{Kind: "kv.put", Key: "team-doc", Value: &proto.Value{Content: []byte("doc")}},
+				{Kind: "kv.get", Key: "team-doc"},
+				{Kind: "kv.get", Key: "missing"},
+			},
+		})
+		is.NoError(err)
+		is.Len(res.Results, 5)
+		is.Equal(res.Results[0].Items, []string{"alice"})
+		is.Equal(res.Results[1].Count, int64(1))
+		is.True(res.Results[3].Found)
+		is.Equal(res.Results[3].Value.Content, []byte("doc"))
+		is.False(res.Results[4].Found)
+		is.Nil(res.Results[4].Value)
+
+		_, err = srv.Txn(ctx, &proto.TxnRequest{
+			Ops: []*proto.TxnOp{{Kind: "counter.reset", Key: "team-size"}},
+		})
+		stat, ok := status.FromError(err)
+		is.True(ok)
+		is.Equal(stat.Code(), codes.InvalidArgument)
+	})
+
+	t.Run("TxnSession", func(_ *testing.T) {
+		read := []*proto.TxnOp{{Kind: "counter.get", Key: "team-size"}}
+		write := []*proto.TxnOp{{Kind: "counter.incr", Key: "team-size", Amount: 1}}
+
+		sess, err := srv.Begin(ctx, &proto.Empty{})
+		is.NoError(err)
+		res, err := srv.Read(ctx, &proto.TxnSessionRequest{Id: sess.Id, Ops: read})
+		is.NoError(err)
+		is.Equal(res.Results[0].Count, int64(1))
+
+		_, err = srv.Read(ctx, &proto.TxnSessionRequest{Id: sess.Id, Ops: write})
+		stat, _ := status.FromError(err)
+		is.Equal(stat.Code(), codes.InvalidArgument)
+
+		res, err = srv.Commit(ctx, &proto.TxnSessionRequest{Id: sess.Id, Ops: write})
+		is.NoError(err)
+		is.Equal(res.Results[0].Count, int64(2))
+
+		// Committed sessions are gone
+		_, err = srv.Commit(ctx, &proto.TxnSessionRequest{Id: sess.Id, Ops: write})
+		stat, _ = status.FromError(err)
+		is.Equal(stat.Code(), codes.NotFound)
+
+		sess, err = srv.Begin(ctx, &proto.Empty{})
+		is.NoError(err)
+		_, err = srv.Read(ctx, &proto.TxnSessionRequest{Id: sess.Id, Ops: read})
+		is.NoError(err)
+		_, err = srv.CounterIncrement(ctx, &proto.IncrementCounterRequest{Key: "team-size", Amount: 1})
+		is.NoError(err)
+		_, err = srv.Commit(ctx, &proto.TxnSessionRequest{Id: sess.Id, Ops: write})
+		stat, _ = status.FromError(err)
+		is.Equal(stat.Code(), codes.FailedPrecondition)
+
+		sess, err = srv.Begin(ctx, &proto.Empty{})
+		is.NoError(err)
+		_, err = srv.Abort(ctx, sess)
+		is.NoError(err)
+		_, err = srv.Abort(ctx, sess)
+		stat, _ = status.FromError(err)
+		is.Equal(stat.Code(), codes.NotFound)
+	})
+
+	t.Run("SetAlgebra", func(_ *testing.T) {
+		for _, item := range []string{"alice", "bob"} {
+			_, err := srv.SetAdd(ctx, &proto.ModifySetRequest{Set: "admins", Item: item})
+			is.NoError(err)
+		}
+
+		contains, err := srv.SetContains(ctx, &proto.SetContainsRequest{Set: "admins", Item: "bob"})
+		is.NoError(err)
+		is.True(contains.Contains)
+
+		size, err := srv.SetSize(ctx, &proto.GetSetRequest{Set: "admins"})
+		is.NoError(err)
+		is.Equal(size.Size, int64(2))
+
+		res, err := srv.SetIntersect(ctx, &proto.CombineSetsRequest{Sets: []string{"admins", "team"}})
+		is.NoError(err)
+		is.Equal(res.Items, []string{"alice"})
+
+		size, err = srv.SetDiffStore(ctx, &proto.StoreSetsRequest{Destination: "others", Sets: []string{"admins", "team"}})
+		is.NoError(err)
+		is.Equal(size.Size, int64(1))
+
+		res, err = srv.SetGet(ctx, &proto.GetSetRequest{Set: "others"})
+		is.NoError(err)
+		is.Equal(res.Items, []string{"bob"})
+
+		_, err = srv.SetUnion(ctx, &proto.CombineSetsRequest{})
+		stat, _ := status.FromError(err)
+		is.Equal(stat.Code(), codes.InvalidArgument)
+	})
+
+	t.Run("SetSampling", func(_ *testing.T) {
+		for _, item := range []string{"a", "b", "c"} {
+			_, err := srv.SetAdd(ctx, &proto.ModifySetRequest{Set: "letters", Item: item})
+			is.NoError(err)
+		}
+
+		res, err := srv.SetGet(ctx, &proto.GetSetRequest{Set: "letters", Limit: 2})
+		is.NoError(err)
+		is.Equal(res.Items, []string{"a", "b"})
+		is.Equal(res.NextCursor, "b")
+
+		res, err = srv.SetGet(ctx, &proto.GetSetRequest{Set: "letters", Cursor: res.NextCursor, Limit: 2})
+		is.NoError(err)
+		is.Equal(res.Items, []string{"c"})
+		is.Empty(res.NextCursor)
+
+		res, err = srv.SetAdd(ctx, &proto.ModifySetRequest{Set: "letters", Item: "d", Summary: true})
+		is.NoError(err)
+		is.Empty(res.Items)
+		is.True(res.Changed)
+		is.Equal(res.Size, int64(4))
+
+		res, err = srv.SetRemove(ctx, &proto.ModifySetRequest{Set: "missing", Item: "d", Summary: true})
+		is.NoError(err)
+		is.False(res.Changed)
+		is.Zero(res.Size)
+
+		res, err = srv.SetRandom(ctx, &proto.RandomSetRequest{Set: "letters", Count: 2})
+		is.NoError(err)
+		is.Len(res.Items, 2)
+
+		res, err = srv.SetPop(ctx, &proto.PopSetRequest{Set: "letters", Count: 4})
+		is.NoError(err)
+		is.ElementsMatch(res.Items, []string{"a", "b", "c", "d"})
+
+		_, err = srv.SetPop(ctx, &proto.PopSetRequest{Set: "letters", Count: -1})
+		stat, _ := status.FromError(err)
+		is.Equal(stat.Code(), codes.InvalidArgument)
+	})
+
 	t.Run("Shutdown", func(_ *testing.T) {
 		is.NoError(srv.ShutDown())
 	})
 }
+
+// Collects the records sent by the Dump RPC without a network connection.
+type dumpStream struct {
+	grpc.ServerStream
+	records []*proto.DumpRecord
+}
+
+func (s *dumpStream) Send(r *proto.DumpRecord) error {
+	s.records = append(s.records, r)
+	return nil
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/internal/backend"
+	"github.com/purpledb/purple/internal/migrate"
+)
+
+// Dump streams every entry in the backend as newline-delimited JSON. Since the response is already underway, errors
+// that occur partway through can only be logged.
+func (h *Handler) Dump(c *gin.Context) {
+	log := h.logger("admin/dump")
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	if _, err := migrate.WriteDump(h.b, c.Writer); err != nil {
+		log.Error(err)
+	}
+}
+
+// Backup writes an online backup of the disk backend to its backup directory and responds with the backup file and
+// the version to pass as since for the next incremental backup.
+func (h *Handler) Backup(c *gin.Context) {
+	log := h.logger("admin/backup")
+
+	info, err := backend.Backup(h.b, getSince(c))
+	if err != nil {
+		if errors.Is(err, purple.ErrBackupNotSupported) || err == purple.ErrNoBackupDir {
+			res := gin.H{
+				"error": err.Error(),
+			}
+			c.AbortWithStatusJSON(http.StatusConflict, res)
+			return
+		} else {
+			log.Error(err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	res := gin.H{
+		"path":  info.Path,
+		"since": info.Since,
+		"next":  info.Next,
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// Stats responds with the statistics kept by the backends behind the server: the hit rate of a tiered LRU and the cache
+// expiry and eviction counts of the memory backend.
+// Statistics that none of the backends keep are left out.
+func (h *Handler) Stats(c *gin.Context) {
+	stats := backend.CollectStats(h.b)
+
+	res := gin.H{}
+
+	if t := stats.Tiered; t != nil {
+		res["tiered"] = gin.H{
+			"hits":      t.Hits,
+			"misses":    t.Misses,
+			"evictions": t.Evictions,
+			"size":      t.Size,
+		}
+	}
+
+	if m := stats.Memory; m != nil {
+		res["memory"] = gin.H{
+			"cache_entries": m.CacheEntries,
+			"expired":       m.Expired,
+			"evicted":       m.Evicted,
+		}
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -58,3 +58,7 @@ func (h *Handler) CachePut(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *Handler) CacheList(c *gin.Context) {
+	h.list(c, "cache/list", h.b.CacheList)
+}
@@ -43,3 +43,7 @@ func (h *Handler) CounterPut(c *gin.Context) {
 		"value":   count,
 	})
 }
+
+func (h *Handler) CounterList(c *gin.Context) {
+	h.list(c, "counter/list", h.b.CounterList)
+}
@@ -48,3 +48,7 @@ func (h *Handler) FlagSet(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *Handler) FlagList(c *gin.Context) {
+	h.list(c, "flag/list", h.b.FlagList)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/purpledb/purple"
 	"github.com/purpledb/purple/internal/backend"
 	"github.com/sirupsen/logrus"
 )
@@ -27,3 +28,32 @@ func (h *Handler) logger(op string) *logrus.Entry {
 func (h *Handler) Ping(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
+
+// Responds with the page of keys returned by one of the backend's List methods for the request's list parameters.
+func (h *Handler) list(c *gin.Context, op string, fn func(prefix, cursor string, limit int) ([]string, string, error)) {
+	log := h.logger(op)
+
+	params := getListParams(c)
+
+	keys, next, err := fn(params.prefix, params.cursor, params.limit)
+	if err != nil {
+		if err == purple.ErrInvalidCursor {
+			res := gin.H{
+				"error": err.Error(),
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		} else {
+			log.Error(err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	res := gin.H{
+		"keys": keys,
+		"next": next,
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/txn"
 )
 
 func SetTtl(c *gin.Context) {
@@ -30,6 +34,16 @@ func SetTtl(c *gin.Context) {
 	c.Set("ttl", int32(ttl))
 }
 
+// Like SetTtl, but the TTL is optional and defaults to 0, i.e. no expiry
+func SetKVTtl(c *gin.Context) {
+	if c.Query("ttl") == "" {
+		c.Set("ttl", int32(0))
+		return
+	}
+
+	SetTtl(c)
+}
+
 func getTtl(c *gin.Context) int32 {
 	return c.MustGet("ttl").(int32)
 }
@@ -60,8 +74,41 @@ func getIncr(c *gin.Context) int64 {
 	return c.MustGet("increment").(int64)
 }
 
+// The encoding of KV content that holds arbitrary bytes. Content is sent as plain text unless a request asks for it.
+const base64Encoding = "base64"
+
+// The KV value written by a request. Content is plain text unless encoding is base64, which allows it to hold arbitrary
+// bytes. As over gRPC, empty content is a value like any other.
 type valJs struct {
-	Content string `json:"content"`
+	Content     string            `json:"content"`
+	Encoding    string            `json:"encoding"`
+	ContentType string            `json:"content_type"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+// Returns the value with its content decoded.
+func (js *valJs) value() (*kv.Value, error) {
+	content, err := decodeContent(js.Content, js.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kv.Value{
+		Content:     content,
+		ContentType: js.ContentType,
+		Metadata:    js.Metadata,
+	}, nil
+}
+
+func decodeContent(content, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(content), nil
+	case base64Encoding:
+		return base64.StdEncoding.DecodeString(content)
+	}
+
+	return nil, fmt.Errorf("unknown content encoding %s", encoding)
 }
 
 func SetKVValue(c *gin.Context) {
@@ -75,19 +122,149 @@ func SetKVValue(c *gin.Context) {
 		return
 	}
 
-	if js.Content == "" {
+	val, err := js.value()
+	if err != nil {
+		res := gin.H{
+			"error": err.Error(),
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	c.Set("value", val)
+}
+
+func getKvValue(c *gin.Context) *kv.Value {
+	return c.MustGet("value").(*kv.Value)
+}
+
+type keysJs struct {
+	Keys []string `json:"keys"`
+}
+
+func SetKVKeys(c *gin.Context) {
+	var js keysJs
+
+	if err := c.ShouldBindJSON(&js); err != nil {
+		res := gin.H{
+			"error": err.Error(),
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	c.Set("keys", js.Keys)
+}
+
+func getKvKeys(c *gin.Context) []string {
+	return c.MustGet("keys").([]string)
+}
+
+type entryJs struct {
+	Key string `json:"key"`
+	valJs
+	TTL int32 `json:"ttl"`
+}
+
+type entriesJs struct {
+	Entries []*entryJs `json:"entries"`
+}
+
+// Like SetKVValue for each of the entries of a batch
+func SetKVEntries(c *gin.Context) {
+	var js entriesJs
+
+	if err := c.ShouldBindJSON(&js); err != nil {
+		res := gin.H{
+			"error": err.Error(),
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	entries := make([]*kv.Entry, len(js.Entries))
+
+	for i, e := range js.Entries {
+		if e.Key == "" {
+			res := gin.H{
+				"error": "key cannot be empty",
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		val, err := e.value()
+		if err != nil {
+			res := gin.H{
+				"error": err.Error(),
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		entries[i] = &kv.Entry{Key: e.Key, Value: val, TTL: e.TTL}
+	}
+
+	c.Set("entries", entries)
+}
+
+func getKvEntries(c *gin.Context) []*kv.Entry {
+	return c.MustGet("entries").([]*kv.Entry)
+}
+
+type txnJs struct {
+	Ops []*txnOpJs `json:"ops"`
+}
+
+func SetTxnOps(c *gin.Context) {
+	var js txnJs
+
+	if err := c.ShouldBindJSON(&js); err != nil {
 		res := gin.H{
-			"error": "content cannot be empty",
+			"error": err.Error(),
 		}
 		c.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
 
-	c.Set("value", &js)
+	ops := make([]*txn.Op, len(js.Ops))
+
+	for i, op := range js.Ops {
+		var err error
+
+		if ops[i], err = op.op(); err != nil {
+			res := gin.H{
+				"error": err.Error(),
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+	}
+
+	c.Set("ops", ops)
 }
 
-func getKvValue(c *gin.Context) *valJs {
-	return c.MustGet("value").(*valJs)
+func getTxnOps(c *gin.Context) []*txn.Op {
+	return c.MustGet("ops").([]*txn.Op)
+}
+
+// SetEncoding reads the encoding in which to respond with KV content, which is plain text unless base64 is asked for.
+func SetEncoding(c *gin.Context) {
+	encoding := c.Query("encoding")
+
+	if encoding != "" && encoding != base64Encoding {
+		res := gin.H{
+			"error": fmt.Sprintf("unknown content encoding %s", encoding),
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	c.Set("encoding", encoding)
+}
+
+func getEncoding(c *gin.Context) string {
+	return c.MustGet("encoding").(string)
 }
 
 func SetFlagValue(c *gin.Context) {
@@ -125,3 +302,179 @@ func SetItem(c *gin.Context) {
 func getItem(c *gin.Context) string {
 	return c.MustGet("item").(string)
 }
+
+// SetSummary reads whether a set mutation should respond with a summary rather than the set's items.
+func SetSummary(c *gin.Context) {
+	var summary bool
+
+	if summaryRaw := c.Query("summary"); summaryRaw != "" {
+		var err error
+
+		summary, err = strconv.ParseBool(summaryRaw)
+		if err != nil {
+			res := gin.H{
+				"error": fmt.Sprintf("could not parse %s into a boolean", summaryRaw),
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+	}
+
+	c.Set("summary", summary)
+}
+
+func getSummary(c *gin.Context) bool {
+	return c.MustGet("summary").(bool)
+}
+
+// SetCount reads how many set items to pick at random, which defaults to one.
+func SetCount(c *gin.Context) {
+	count := 1
+
+	if countRaw := c.Query("count"); countRaw != "" {
+		var err error
+
+		count, err = strconv.Atoi(countRaw)
+		if err != nil {
+			res := gin.H{
+				"error": fmt.Sprintf("could not parse %s into an integer", countRaw),
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+	}
+
+	c.Set("count", count)
+}
+
+func getCount(c *gin.Context) int {
+	return c.MustGet("count").(int)
+}
+
+type setsJs struct {
+	Sets []string `json:"sets"`
+	// If supplied, the result is stored in this set rather than returned
+	Destination string `json:"destination"`
+}
+
+func SetSetNames(c *gin.Context) {
+	var js setsJs
+
+	if err := c.ShouldBindJSON(&js); err != nil {
+		res := gin.H{
+			"error": err.Error(),
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	c.Set("sets", &js)
+}
+
+func getSetNames(c *gin.Context) *setsJs {
+	return c.MustGet("sets").(*setsJs)
+}
+
+type listParams struct {
+	prefix string
+	cursor string
+	limit  int
+}
+
+func SetListParams(c *gin.Context) {
+	params := &listParams{
+		prefix: c.Query("prefix"),
+		cursor: c.Query("cursor"),
+	}
+
+	if limitRaw := c.Query("limit"); limitRaw != "" {
+		limit, err := strconv.Atoi(limitRaw)
+		if err != nil {
+			res := gin.H{
+				"error": fmt.Sprintf("could not parse %s into an integer", limitRaw),
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		params.limit = limit
+	}
+
+	c.Set("list", params)
+}
+
+func getListParams(c *gin.Context) *listParams {
+	return c.MustGet("list").(*listParams)
+}
+
+// SetSince reads the version a backup starts at, which defaults to zero for a full backup.
+func SetSince(c *gin.Context) {
+	var since uint64
+
+	if sinceRaw := c.Query("since"); sinceRaw != "" {
+		var err error
+
+		since, err = strconv.ParseUint(sinceRaw, 10, 64)
+		if err != nil {
+			res := gin.H{
+				"error": fmt.Sprintf("could not parse %s into a version", sinceRaw),
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+	}
+
+	c.Set("since", since)
+}
+
+func getSince(c *gin.Context) uint64 {
+	return c.MustGet("since").(uint64)
+}
+
+// A condition on the current version of a KV value, taken from the If-Match or If-None-Match header
+type precondition struct {
+	// The version given in If-Match
+	version  uint64
+	ifMatch  bool
+	ifAbsent bool
+}
+
+func SetPrecondition(c *gin.Context) {
+	pre := &precondition{}
+
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		version, err := strconv.ParseUint(strings.Trim(ifMatch, `"`), 10, 64)
+		if err != nil {
+			res := gin.H{
+				"error": fmt.Sprintf("could not parse %s into a version", ifMatch),
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		pre.version, pre.ifMatch = version, true
+	}
+
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if ifNoneMatch != "*" || pre.ifMatch {
+			res := gin.H{
+				"error": "If-None-Match only supports * and can't be combined with If-Match",
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		pre.ifAbsent = true
+	}
+
+	c.Set("precondition", pre)
+}
+
+func getPrecondition(c *gin.Context) *precondition {
+	return c.MustGet("precondition").(*precondition)
+}
+
+// Formats a KV version as an ETag
+func etag(version uint64) string {
+	return strconv.Quote(strconv.FormatUint(version, 10))
+}
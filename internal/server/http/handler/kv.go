@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"encoding/base64"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/purpledb/purple"
-	"github.com/purpledb/purple/internal/services/kv"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/sirupsen/logrus"
 )
 
 func (h *Handler) KvGet(c *gin.Context) {
@@ -25,23 +27,56 @@ func (h *Handler) KvGet(c *gin.Context) {
 		}
 	}
 
+	c.Header("ETag", etag(val.Version))
+	c.JSON(http.StatusOK, valueRes(val, getEncoding(c)))
+}
+
+// The content of a value is returned as plain text under value, or base64-encoded if that's the requested encoding.
+func valueRes(val *kv.Value, encoding string) gin.H {
 	res := gin.H{
-		"value": string(val.Content),
+		"value":        string(val.Content),
+		"content_type": val.ContentType,
+		"metadata":     val.Metadata,
+		"version":      val.Version,
+		"created":      val.Created,
+		"updated":      val.Updated,
 	}
 
-	c.JSON(http.StatusOK, res)
+	if encoding == base64Encoding {
+		res["value"] = base64.StdEncoding.EncodeToString(val.Content)
+		res["encoding"] = base64Encoding
+	}
+
+	return res
 }
 
 func (h *Handler) KvPut(c *gin.Context) {
 	log := h.logger("kv/put")
 
-	key, val := c.Param("key"), getKvValue(c)
+	key, value, pre, ttl := c.Param("key"), getKvValue(c), getPrecondition(c), getTtl(c)
+
+	var err error
 
-	value := &kv.Value{
-		Content: []byte(val.Content),
+	switch {
+	case pre.ifMatch:
+		err = h.b.KVPutIfVersion(key, value, pre.version, ttl)
+	case pre.ifAbsent:
+		err = h.b.KVPutIfAbsent(key, value, ttl)
+	default:
+		err = h.b.KVPut(key, value, ttl)
 	}
 
-	if err := h.b.KVPut(key, value); err != nil {
+	if err != nil {
+		if purple.IsConflict(err) {
+			c.Status(http.StatusPreconditionFailed)
+			return
+		}
+
+		if err == purple.ErrNegativeKVTTL {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		log.Error(err)
 		c.Status(http.StatusInternalServerError)
 		return
@@ -53,9 +88,22 @@ func (h *Handler) KvPut(c *gin.Context) {
 func (h *Handler) KvDelete(c *gin.Context) {
 	log := h.logger("kv/delete")
 
-	key := c.Param("key")
+	key, pre := c.Param("key"), getPrecondition(c)
+
+	var err error
+
+	if pre.ifMatch {
+		err = h.b.KVDeleteIfVersion(key, pre.version)
+	} else {
+		err = h.b.KVDelete(key)
+	}
+
+	if err != nil {
+		if purple.IsConflict(err) {
+			c.Status(http.StatusPreconditionFailed)
+			return
+		}
 
-	if err := h.b.KVDelete(key); err != nil {
 		log.Error(err)
 		c.Status(http.StatusInternalServerError)
 		return
@@ -63,3 +111,137 @@ func (h *Handler) KvDelete(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *Handler) KvTTL(c *gin.Context) {
+	log := h.logger("kv/ttl")
+
+	key := c.Param("key")
+
+	ttl, err := h.b.KVTTL(key)
+	if err != nil {
+		if purple.IsNotFound(err) {
+			c.Status(http.StatusNotFound)
+			return
+		} else {
+			log.Error(err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	res := gin.H{
+		"ttl": ttl,
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) KvExpire(c *gin.Context) {
+	key, ttl := c.Param("key"), getTtl(c)
+
+	h.changeExpiry(c, "kv/expire", func() error {
+		return h.b.KVExpire(key, ttl)
+	})
+}
+
+func (h *Handler) KvPersist(c *gin.Context) {
+	key := c.Param("key")
+
+	h.changeExpiry(c, "kv/persist", func() error {
+		return h.b.KVPersist(key)
+	})
+}
+
+func (h *Handler) changeExpiry(c *gin.Context, op string, change func() error) {
+	log := h.logger(op)
+
+	if err := change(); err != nil {
+		switch {
+		case purple.IsNotFound(err):
+			c.Status(http.StatusNotFound)
+		case err == purple.ErrNonPositiveKVTTL:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Error(err)
+			c.Status(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) KvList(c *gin.Context) {
+	h.list(c, "kv/list", h.b.KVList)
+}
+
+// The batch handlers respond with a result per key, in the order the keys were sent.
+func (h *Handler) KvGetMany(c *gin.Context) {
+	log := h.logger("kv/get-many")
+
+	keys, encoding := getKvKeys(c), getEncoding(c)
+
+	values, err := h.b.KVGetMany(keys)
+	if err != nil {
+		batchError(c, log, err)
+		return
+	}
+
+	results := make([]gin.H, len(keys))
+
+	for i, key := range keys {
+		results[i] = gin.H{"key": key, "found": values[i] != nil}
+
+		if values[i] != nil {
+			for k, v := range valueRes(values[i], encoding) {
+				results[i][k] = v
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+func (h *Handler) KvPutMany(c *gin.Context) {
+	log := h.logger("kv/put-many")
+
+	entries := getKvEntries(c)
+
+	if err := h.b.KVPutMany(entries); err != nil {
+		batchError(c, log, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) KvDeleteMany(c *gin.Context) {
+	log := h.logger("kv/delete-many")
+
+	keys := getKvKeys(c)
+
+	existed, err := h.b.KVDeleteMany(keys)
+	if err != nil {
+		batchError(c, log, err)
+		return
+	}
+
+	results := make([]gin.H, len(keys))
+
+	for i, key := range keys {
+		results[i] = gin.H{"key": key, "found": existed[i]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+func batchError(c *gin.Context, log *logrus.Entry, err error) {
+	if err == purple.ErrKVBatchTooLarge || err == purple.ErrNegativeKVTTL {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Error(err)
+	c.Status(http.StatusInternalServerError)
+}
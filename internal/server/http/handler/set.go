@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/services/set"
 )
 
 func emptySetRes(set string) gin.H {
@@ -19,6 +20,11 @@ func (h *Handler) SetGet(c *gin.Context) {
 
 	key := c.Param("key")
 
+	if params := getListParams(c); params.cursor != "" || params.limit != 0 {
+		h.setPage(c, key, params)
+		return
+	}
+
 	items, err := h.b.SetGet(key)
 	if err != nil {
 		if purple.IsNotFound(err) {
@@ -42,6 +48,11 @@ func (h *Handler) SetGet(c *gin.Context) {
 func (h *Handler) SetPut(c *gin.Context) {
 	log := h.logger("set/increment")
 
+	if getSummary(c) {
+		h.setSummary(c, "set/add", h.b.SetAddSummary)
+		return
+	}
+
 	key, item := c.Param("key"), getItem(c)
 
 	items, err := h.b.SetAdd(key, item)
@@ -67,6 +78,11 @@ func (h *Handler) SetPut(c *gin.Context) {
 func (h *Handler) SetDelete(c *gin.Context) {
 	log := h.logger("set/remove")
 
+	if getSummary(c) {
+		h.setSummary(c, "set/remove", h.b.SetRemoveSummary)
+		return
+	}
+
 	key, item := c.Param("key"), getItem(c)
 
 	items, err := h.b.SetRemove(key, item)
@@ -88,3 +104,191 @@ func (h *Handler) SetDelete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, res)
 }
+
+// Like the sets themselves, the pages of sets that don't exist are empty.
+func (h *Handler) setPage(c *gin.Context, key string, params *listParams) {
+	log := h.logger("set/get")
+
+	items, next, err := h.b.SetGetPage(key, params.cursor, params.limit)
+	if err != nil {
+		if purple.IsNotFound(err) {
+			items, next = []string{}, ""
+		} else if err == purple.ErrInvalidCursor {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else {
+			log.Error(err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	res := gin.H{
+		"set":   key,
+		"items": items,
+		"next":  next,
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// Responds with whether the set changed and its size rather than its items. Removing an item from a set that doesn't
+// exist changes nothing.
+func (h *Handler) setSummary(c *gin.Context, op string, fn func(set, item string) (*set.Summary, error)) {
+	log := h.logger(op)
+
+	key, item := c.Param("key"), getItem(c)
+
+	summary, err := fn(key, item)
+	if err != nil {
+		if purple.IsNotFound(err) {
+			summary = &set.Summary{}
+		} else {
+			log.Error(err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	res := gin.H{
+		"set":     key,
+		"changed": summary.Changed,
+		"size":    summary.Size,
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) SetRandom(c *gin.Context) {
+	h.sampleSet(c, "set/random", h.b.SetRandom)
+}
+
+func (h *Handler) SetPop(c *gin.Context) {
+	h.sampleSet(c, "set/pop", h.b.SetPop)
+}
+
+// Responds with the items picked at random, which SetPop also removes.
+func (h *Handler) sampleSet(c *gin.Context, op string, fn func(set string, count int) ([]string, error)) {
+	log := h.logger(op)
+
+	key := c.Param("key")
+
+	items, err := fn(key, getCount(c))
+	if err != nil {
+		if err == purple.ErrNegativeSetPopCount {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		log.Error(err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	res := gin.H{
+		"set":   key,
+		"items": items,
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) SetContains(c *gin.Context) {
+	log := h.logger("set/contains")
+
+	key, item := c.Param("key"), getItem(c)
+
+	contains, err := h.b.SetContains(key, item)
+	if err != nil {
+		log.Error(err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	res := gin.H{
+		"set":      key,
+		"item":     item,
+		"contains": contains,
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) SetSize(c *gin.Context) {
+	log := h.logger("set/size")
+
+	key := c.Param("key")
+
+	size, err := h.b.SetSize(key)
+	if err != nil {
+		log.Error(err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	res := gin.H{
+		"set":  key,
+		"size": size,
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) SetUnion(c *gin.Context) {
+	h.combineSets(c, "set/union", h.b.SetUnion, h.b.SetUnionStore)
+}
+
+func (h *Handler) SetIntersect(c *gin.Context) {
+	h.combineSets(c, "set/intersect", h.b.SetIntersect, h.b.SetIntersectStore)
+}
+
+func (h *Handler) SetDiff(c *gin.Context) {
+	h.combineSets(c, "set/diff", h.b.SetDiff, h.b.SetDiffStore)
+}
+
+// Responds with the items that result from combining the sets or, if a destination is supplied, stores them there and
+// responds with the destination's size.
+func (h *Handler) combineSets(
+	c *gin.Context,
+	op string,
+	combine func(sets ...string) ([]string, error),
+	store func(dest string, sets ...string) (int, error),
+) {
+	log := h.logger(op)
+
+	js := getSetNames(c)
+
+	var (
+		res gin.H
+		err error
+	)
+
+	if js.Destination != "" {
+		var size int
+
+		size, err = store(js.Destination, js.Sets...)
+		res = gin.H{"set": js.Destination, "size": size}
+	} else {
+		var items []string
+
+		items, err = combine(js.Sets...)
+		res = gin.H{"items": items}
+	}
+
+	if err != nil {
+		if err == purple.ErrNoSets || err == purple.ErrNoKey {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		log.Error(err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) SetList(c *gin.Context) {
+	h.list(c, "set/list", h.b.SetList)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/services/txn"
+)
+
+// Txn applies the operations all or none and responds with a result per operation, holding only the fields that apply
+// to the operation's kind.
+func (h *Handler) Txn(c *gin.Context) {
+	log := h.logger("txn")
+
+	ops, encoding := getTxnOps(c), getEncoding(c)
+
+	results, err := h.b.Txn(ops)
+	if err != nil {
+		switch {
+		case purple.IsConflict(err), err == purple.ErrTxnSpansBackends, err == purple.ErrRedisWrongType,
+			err == purple.ErrRedisCounterInvalid:
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err == purple.ErrTxnTooLarge, err == purple.ErrUnknownTxnOp, err == purple.ErrNoKey, err == purple.ErrNoValue,
+			err == purple.ErrNegativeKVTTL:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Error(err)
+			c.Status(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	res := make([]gin.H, len(results))
+
+	for i, r := range results {
+		res[i] = txnResult(ops[i].Kind, r, encoding)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": res})
+}
+
+type txnOpJs struct {
+	Kind string `json:"kind"`
+	Key  string `json:"key"`
+	// The value and TTL written by kv.put
+	valJs
+	TTL    int32  `json:"ttl"`
+	Amount int64  `json:"amount"`
+	Flag   bool   `json:"flag"`
+	Item   string `json:"item"`
+}
+
+func (js *txnOpJs) op() (*txn.Op, error) {
+	op := &txn.Op{
+		Kind:   js.Kind,
+		Key:    js.Key,
+		TTL:    js.TTL,
+		Amount: js.Amount,
+		Flag:   js.Flag,
+		Item:   js.Item,
+	}
+
+	if js.Kind == txn.KVPut {
+		val, err := js.value()
+		if err != nil {
+			return nil, err
+		}
+
+		op.Value = val
+	}
+
+	return op, nil
+}
+
+func txnResult(kind string, r *txn.Result, encoding string) gin.H {
+	res := gin.H{}
+
+	switch kind {
+	case txn.KVGet:
+		res["found"] = r.Found
+
+		if r.Value != nil {
+			res = valueRes(r.Value, encoding)
+			res["found"] = true
+		}
+	case txn.KVDelete:
+		res["found"] = r.Found
+	case txn.CounterGet, txn.CounterIncr:
+		res["count"] = r.Count
+	case txn.FlagGet:
+		res["flag"] = r.Flag
+	case txn.SetGet, txn.SetRemove:
+		res["found"] = r.Found
+		fallthrough
+	case txn.SetAdd:
+		res["items"] = r.Items
+		if r.Items == nil {
+			res["items"] = []string{}
+		}
+	}
+
+	return res
+}
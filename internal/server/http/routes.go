@@ -10,6 +10,12 @@ func (s *Server) routes() *gin.Engine {
 
 	r.GET("/ping", s.h.Ping)
 
+	r.GET("/admin/dump", s.h.Dump)
+	r.POST("/admin/backup", handler.SetSince, s.h.Backup)
+	r.GET("/admin/stats", s.h.Stats)
+
+	r.GET("/cache", handler.SetListParams, s.h.CacheList)
+
 	cache := r.Group("/cache/:key")
 	{
 		cache.GET("", s.h.CacheGet)
@@ -21,6 +27,8 @@ func (s *Server) routes() *gin.Engine {
 		}
 	}
 
+	r.GET("/counters", handler.SetListParams, s.h.CounterList)
+
 	counters := r.Group("/counters/:key")
 	{
 		counters.GET("", s.h.CounterGet)
@@ -32,6 +40,8 @@ func (s *Server) routes() *gin.Engine {
 		}
 	}
 
+	r.GET("/flags", handler.SetListParams, s.h.FlagList)
+
 	flags := r.Group("/flags/:key")
 	{
 		flags.GET("", s.h.FlagGet)
@@ -43,29 +53,58 @@ func (s *Server) routes() *gin.Engine {
 		}
 	}
 
+	r.GET("/kv", handler.SetListParams, s.h.KvList)
+
 	kv := r.Group("/kv/:key")
 	{
-		kv.GET("", s.h.KvGet)
-		kv.DELETE("", s.h.KvDelete)
+		kv.GET("", handler.SetEncoding, s.h.KvGet)
+		kv.DELETE("", handler.SetPrecondition, s.h.KvDelete)
 
 		withVal := kv.Group("")
 		{
-			withVal.Use(handler.SetKVValue)
+			withVal.Use(handler.SetPrecondition, handler.SetKVTtl, handler.SetKVValue)
 			withVal.PUT("", s.h.KvPut)
 		}
+
+		kv.GET("/ttl", s.h.KvTTL)
+		kv.PUT("/ttl", handler.SetTtl, s.h.KvExpire)
+		kv.DELETE("/ttl", s.h.KvPersist)
+	}
+
+	kvBatch := r.Group("/kv-batch")
+	{
+		kvBatch.POST("/get", handler.SetKVKeys, handler.SetEncoding, s.h.KvGetMany)
+		kvBatch.PUT("", handler.SetKVEntries, s.h.KvPutMany)
+		kvBatch.POST("/delete", handler.SetKVKeys, s.h.KvDeleteMany)
 	}
 
+	r.GET("/sets", handler.SetListParams, s.h.SetList)
+
 	sets := r.Group("/sets/:key")
 	{
-		sets.GET("", s.h.SetGet)
+		sets.GET("", handler.SetListParams, s.h.SetGet)
+		sets.GET("/size", s.h.SetSize)
+		sets.GET("/contains", handler.SetItem, s.h.SetContains)
+		sets.GET("/random", handler.SetCount, s.h.SetRandom)
+		sets.POST("/pop", handler.SetCount, s.h.SetPop)
 
 		withItem := sets.Group("")
 		{
-			withItem.Use(handler.SetItem)
+			withItem.Use(handler.SetItem, handler.SetSummary)
 			withItem.PUT("", s.h.SetPut)
 			withItem.DELETE("", s.h.SetDelete)
 		}
 	}
 
+	setOps := r.Group("/set-ops")
+	{
+		setOps.Use(handler.SetSetNames)
+		setOps.POST("/union", s.h.SetUnion)
+		setOps.POST("/intersect", s.h.SetIntersect)
+		setOps.POST("/diff", s.h.SetDiff)
+	}
+
+	r.POST("/txn", handler.SetTxnOps, handler.SetEncoding, s.h.Txn)
+
 	return r
 }
@@ -23,6 +23,10 @@ type Server struct {
 
 // Instantiates a new purple HTTP server using the supplied ServerConfig object.
 func NewServer(cfg *purple.ServerConfig) (*Server, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	addr := fmt.Sprintf(":%d", cfg.Port)
 
 	bk, err := backend.NewBackend(cfg)
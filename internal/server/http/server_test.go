@@ -0,0 +1,79 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/purpledb/purple"
+	"github.com/stretchr/testify/assert"
+)
+
+var goodServerCfg = &purple.ServerConfig{
+	Port:    8080,
+	Backend: "memory",
+}
+
+func TestHttpServer(t *testing.T) {
+	is := assert.New(t)
+
+	srv, err := NewServer(goodServerCfg)
+	is.NoError(err)
+
+	routes := srv.routes()
+
+	do := func(method, path, body string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		routes.ServeHTTP(w, req)
+
+		var res map[string]interface{}
+		if w.Body.Len() > 0 {
+			is.NoError(json.Unmarshal(w.Body.Bytes(), &res))
+		}
+
+		return w.Code, res
+	}
+
+	t.Run("KV", func(_ *testing.T) {
+		// Content is plain text in both directions, as it has always been
+		code, _ := do(http.MethodPut, "/kv/key", `{"content":"some content"}`)
+		is.Equal(code, http.StatusNoContent)
+
+		code, res := do(http.MethodGet, "/kv/key", "")
+		is.Equal(code, http.StatusOK)
+		is.Equal(res["value"], "some content")
+		is.NotContains(res, "encoding")
+
+		// Base64 is only used when it's asked for
+		code, _ = do(http.MethodPut, "/kv/bytes", `{"content":"AAEC","encoding":"base64"}`)
+		is.Equal(code, http.StatusNoContent)
+
+		code, res = do(http.MethodGet, "/kv/bytes", "")
+		is.Equal(code, http.StatusOK)
+		is.Equal(res["value"], "\x00\x01\x02")
+
+		code, res = do(http.MethodGet, "/kv/bytes?encoding=base64", "")
+		is.Equal(code, http.StatusOK)
+		is.Equal(res["value"], "AAEC")
+		is.Equal(res["encoding"], "base64")
+
+		// Empty content is accepted, as it is over gRPC and by imports
+		code, _ = do(http.MethodPut, "/kv/empty", `{"content":""}`)
+		is.Equal(code, http.StatusNoContent)
+
+		code, res = do(http.MethodGet, "/kv/empty", "")
+		is.Equal(code, http.StatusOK)
+		is.Equal(res["value"], "")
+
+		code, _ = do(http.MethodPut, "/kv/key", `{"content":"abc","encoding":"rot13"}`)
+		is.Equal(code, http.StatusBadRequest)
+		code, _ = do(http.MethodPut, "/kv/key", `{"content":"not base64!","encoding":"base64"}`)
+		is.Equal(code, http.StatusBadRequest)
+		code, _ = do(http.MethodGet, "/kv/key?encoding=rot13", "")
+		is.Equal(code, http.StatusBadRequest)
+	})
+}
@@ -204,7 +204,7 @@ func init() {
 func init() { proto.RegisterFile("cache.proto", fileDescriptor_5fca3b110c9bbf3a) }
 
 var fileDescriptor_5fca3b110c9bbf3a = []byte{
-	// 210 bytes of a gzipped FileDescriptorProto
+	// 229 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4e, 0x4e, 0x4c, 0xce,
 	0x48, 0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0x53, 0x52, 0x3c, 0xc9, 0xf9, 0xb9,
 	0xb9, 0xf9, 0x79, 0x10, 0x41, 0x25, 0x63, 0x2e, 0x4e, 0x67, 0x90, 0x1a, 0xcf, 0x92, 0xd4, 0x5c,
@@ -214,11 +214,12 @@ var fileDescriptor_5fca3b110c9bbf3a = []byte{
 	0x2b, 0x4e, 0xc5, 0xae, 0x57, 0x49, 0x99, 0x8b, 0x1f, 0xa1, 0xb2, 0xb0, 0x34, 0xb5, 0xb8, 0x04,
 	0x64, 0x5c, 0x76, 0x6a, 0x25, 0x54, 0x19, 0x88, 0xa9, 0xe4, 0x09, 0x55, 0x14, 0x8c, 0x47, 0x91,
 	0x90, 0x0a, 0x17, 0x4b, 0x66, 0x49, 0x6a, 0x2e, 0xd8, 0x19, 0xdc, 0x46, 0x02, 0x10, 0xe7, 0xeb,
-	0xc1, 0xdd, 0x1e, 0x04, 0x96, 0x35, 0x2a, 0xe3, 0x62, 0x05, 0x0b, 0x09, 0x59, 0x73, 0x71, 0xc0,
-	0x2c, 0x16, 0x12, 0x43, 0x56, 0x8c, 0x70, 0x89, 0x94, 0x38, 0x86, 0x38, 0xd4, 0x2f, 0x06, 0x50,
-	0xcd, 0xc1, 0xe8, 0x9a, 0x11, 0x2e, 0x94, 0xe2, 0x81, 0x8a, 0xbb, 0xe6, 0x16, 0x94, 0x54, 0x26,
-	0xb1, 0x81, 0x39, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x16, 0x5b, 0xd8, 0x46, 0x71, 0x01,
-	0x00, 0x00,
+	0xc1, 0xdd, 0x1e, 0x04, 0x96, 0x35, 0x5a, 0xc3, 0xc8, 0xc5, 0x0a, 0x16, 0x13, 0xb2, 0xe6, 0xe2,
+	0x80, 0xd9, 0x2c, 0x24, 0x86, 0xac, 0x1a, 0xe1, 0x14, 0x29, 0x71, 0x0c, 0x71, 0xa8, 0x67, 0x0c,
+	0xa0, 0x9a, 0x83, 0xd1, 0x35, 0x23, 0x9c, 0x28, 0xc5, 0x03, 0x15, 0x77, 0xcd, 0x2d, 0x28, 0xa9,
+	0x14, 0x32, 0x81, 0x86, 0xa3, 0x4f, 0x66, 0x71, 0x89, 0x90, 0x10, 0x54, 0x0a, 0xc4, 0x81, 0x29,
+	0x17, 0x46, 0x11, 0x83, 0xd8, 0x93, 0xc4, 0x06, 0x16, 0x33, 0x06, 0x0c, 0x00, 0x48, 0x5e, 0x70,
+	0x99, 0xa8, 0x01, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -235,6 +236,7 @@ const _ = grpc.SupportPackageIsVersion4
 type CacheClient interface {
 	CacheGet(ctx context.Context, in *CacheGetRequest, opts ...grpc.CallOption) (*CacheGetResponse, error)
 	CacheSet(ctx context.Context, in *CacheSetRequest, opts ...grpc.CallOption) (*Empty, error)
+	CacheList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 }
 
 type cacheClient struct {
@@ -263,10 +265,20 @@ func (c *cacheClient) CacheSet(ctx context.Context, in *CacheSetRequest, opts ..
 	return out, nil
 }
 
+func (c *cacheClient) CacheList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/proto.Cache/CacheList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CacheServer is the server API for Cache service.
 type CacheServer interface {
 	CacheGet(context.Context, *CacheGetRequest) (*CacheGetResponse, error)
 	CacheSet(context.Context, *CacheSetRequest) (*Empty, error)
+	CacheList(context.Context, *ListRequest) (*ListResponse, error)
 }
 
 func RegisterCacheServer(s *grpc.Server, srv CacheServer) {
@@ -309,6 +321,24 @@ func _Cache_CacheSet_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Cache_CacheList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServer).CacheList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Cache/CacheList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServer).CacheList(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Cache_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Cache",
 	HandlerType: (*CacheServer)(nil),
@@ -321,6 +351,10 @@ var _Cache_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CacheSet",
 			Handler:    _Cache_CacheSet_Handler,
 		},
+		{
+			MethodName: "CacheList",
+			Handler:    _Cache_CacheList_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cache.proto",
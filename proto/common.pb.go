@@ -51,16 +51,127 @@ func (m *Empty) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Empty proto.InternalMessageInfo
 
+type ListRequest struct {
+	Prefix               string   `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Cursor               string   `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListRequest) Reset()         { *m = ListRequest{} }
+func (m *ListRequest) String() string { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()    {}
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_555bd8c177793206, []int{1}
+}
+
+func (m *ListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRequest.Unmarshal(m, b)
+}
+func (m *ListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRequest.Marshal(b, m, deterministic)
+}
+func (m *ListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRequest.Merge(m, src)
+}
+func (m *ListRequest) XXX_Size() int {
+	return xxx_messageInfo_ListRequest.Size(m)
+}
+func (m *ListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRequest proto.InternalMessageInfo
+
+func (m *ListRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *ListRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *ListRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type ListResponse struct {
+	Keys                 []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	NextCursor           string   `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListResponse) Reset()         { *m = ListResponse{} }
+func (m *ListResponse) String() string { return proto.CompactTextString(m) }
+func (*ListResponse) ProtoMessage()    {}
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_555bd8c177793206, []int{2}
+}
+
+func (m *ListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListResponse.Unmarshal(m, b)
+}
+func (m *ListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListResponse.Marshal(b, m, deterministic)
+}
+func (m *ListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListResponse.Merge(m, src)
+}
+func (m *ListResponse) XXX_Size() int {
+	return xxx_messageInfo_ListResponse.Size(m)
+}
+func (m *ListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListResponse proto.InternalMessageInfo
+
+func (m *ListResponse) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *ListResponse) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Empty)(nil), "proto.Empty")
+	proto.RegisterType((*ListRequest)(nil), "proto.ListRequest")
+	proto.RegisterType((*ListResponse)(nil), "proto.ListResponse")
 }
 
 func init() { proto.RegisterFile("common.proto", fileDescriptor_555bd8c177793206) }
 
 var fileDescriptor_555bd8c177793206 = []byte{
-	// 55 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x49, 0xce, 0xcf, 0xcd,
-	0xcd, 0xcf, 0xd3, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0x53, 0x4a, 0xec, 0x5c, 0xac,
-	0xae, 0xb9, 0x05, 0x25, 0x95, 0x49, 0x6c, 0x60, 0xbe, 0x31, 0x20, 0x00, 0x00, 0xff, 0xff, 0x73,
-	0x66, 0x7b, 0x2b, 0x26, 0x00, 0x00, 0x00,
+	// 163 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x8e, 0xbf, 0x0a, 0xc2, 0x30,
+	0x10, 0x87, 0x89, 0x35, 0x95, 0x5e, 0x3b, 0x05, 0x91, 0x6c, 0x96, 0x4e, 0x9d, 0x5c, 0x7c, 0x84,
+	0xe2, 0xe6, 0x14, 0x1f, 0x40, 0xb0, 0x44, 0x08, 0x9a, 0x3f, 0xe6, 0xae, 0xd0, 0xbe, 0xbd, 0x34,
+	0x71, 0x71, 0xba, 0xfb, 0xbe, 0xe1, 0xe3, 0x07, 0xcd, 0xe8, 0xad, 0xf5, 0xee, 0x14, 0xa2, 0x27,
+	0x2f, 0x78, 0x3a, 0xdd, 0x0e, 0xf8, 0xc5, 0x06, 0x5a, 0xba, 0x1b, 0xd4, 0x57, 0x83, 0xa4, 0xf4,
+	0x67, 0xd2, 0x48, 0xe2, 0x00, 0x65, 0x88, 0xfa, 0x69, 0x66, 0xc9, 0x5a, 0xd6, 0x57, 0xea, 0x47,
+	0xab, 0x1f, 0xa7, 0x88, 0x3e, 0xca, 0x4d, 0xf6, 0x99, 0xc4, 0x1e, 0xf8, 0xdb, 0x58, 0x43, 0xb2,
+	0x68, 0x59, 0xcf, 0x55, 0x86, 0x6e, 0x80, 0x26, 0x47, 0x31, 0x78, 0x87, 0x5a, 0x08, 0xd8, 0xbe,
+	0xf4, 0x82, 0x92, 0xb5, 0x45, 0x5f, 0xa9, 0xf4, 0x8b, 0x23, 0xd4, 0x4e, 0xcf, 0x74, 0xff, 0xcb,
+	0xc2, 0xaa, 0x86, 0x64, 0x1e, 0x65, 0x5a, 0x7a, 0xfe, 0x0e, 0x00, 0x11, 0x84, 0x69, 0x82, 0xc0,
+	0x00, 0x00, 0x00,
 }
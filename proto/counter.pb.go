@@ -156,19 +156,21 @@ func init() {
 func init() { proto.RegisterFile("counter.proto", fileDescriptor_75dcd656fce7132f) }
 
 var fileDescriptor_75dcd656fce7132f = []byte{
-	// 184 bytes of a gzipped FileDescriptorProto
+	// 212 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4d, 0xce, 0x2f, 0xcd,
-	0x2b, 0x49, 0x2d, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0x53, 0x4a, 0xce, 0x5c,
-	0xe2, 0x9e, 0x79, 0xc9, 0x45, 0xa9, 0xb9, 0xa9, 0x79, 0x25, 0xce, 0x10, 0x05, 0x41, 0xa9, 0x85,
-	0xa5, 0xa9, 0xc5, 0x25, 0x42, 0x02, 0x5c, 0xcc, 0xd9, 0xa9, 0x95, 0x12, 0x8c, 0x0a, 0x8c, 0x1a,
-	0x9c, 0x41, 0x20, 0xa6, 0x90, 0x18, 0x17, 0x5b, 0x62, 0x2e, 0x48, 0x91, 0x04, 0x93, 0x02, 0xa3,
-	0x06, 0x73, 0x10, 0x94, 0xa7, 0xa4, 0xca, 0x25, 0xe8, 0x9e, 0x4a, 0x50, 0xbb, 0x92, 0x16, 0x97,
-	0x10, 0xb2, 0xb2, 0xe2, 0x82, 0xfc, 0xbc, 0xe2, 0x54, 0x21, 0x11, 0x2e, 0xd6, 0xb2, 0xc4, 0x9c,
-	0xd2, 0x54, 0xb0, 0x4a, 0xe6, 0x20, 0x08, 0xc7, 0x68, 0x36, 0x23, 0x17, 0x3b, 0x54, 0xa5, 0x90,
-	0x23, 0x17, 0x17, 0x94, 0xe9, 0x9e, 0x5a, 0x22, 0x24, 0x01, 0xf1, 0x80, 0x1e, 0x86, 0x8d, 0x52,
-	0x92, 0x58, 0x64, 0xa0, 0x96, 0xf8, 0x72, 0x09, 0x40, 0x85, 0xe0, 0xbe, 0x15, 0x92, 0x83, 0x2a,
-	0xc7, 0xe1, 0x7f, 0x3c, 0xc6, 0x25, 0xb1, 0x81, 0x65, 0x8c, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff,
-	0xc5, 0x96, 0x37, 0xdc, 0x54, 0x01, 0x00, 0x00,
+	0x2b, 0x49, 0x2d, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0x53, 0x52, 0x3c, 0xc9,
+	0xf9, 0xb9, 0xb9, 0xf9, 0x79, 0x10, 0x41, 0x25, 0x67, 0x2e, 0x71, 0xcf, 0xbc, 0xe4, 0xa2, 0xd4,
+	0xdc, 0xd4, 0xbc, 0x12, 0x67, 0x88, 0xf2, 0xa0, 0xd4, 0xc2, 0xd2, 0xd4, 0xe2, 0x12, 0x21, 0x01,
+	0x2e, 0xe6, 0xec, 0xd4, 0x4a, 0x09, 0x46, 0x05, 0x46, 0x0d, 0xce, 0x20, 0x10, 0x53, 0x48, 0x8c,
+	0x8b, 0x2d, 0x31, 0x17, 0xa4, 0x48, 0x82, 0x49, 0x81, 0x51, 0x83, 0x39, 0x08, 0xca, 0x53, 0x52,
+	0xe5, 0x12, 0x74, 0x4f, 0x25, 0xa8, 0x5d, 0x49, 0x8b, 0x4b, 0x08, 0x59, 0x59, 0x71, 0x41, 0x7e,
+	0x5e, 0x71, 0xaa, 0x90, 0x08, 0x17, 0x6b, 0x59, 0x62, 0x4e, 0x69, 0x2a, 0x58, 0x25, 0x73, 0x10,
+	0x84, 0x63, 0x74, 0x99, 0x91, 0x8b, 0x1d, 0xaa, 0x52, 0xc8, 0x91, 0x8b, 0x0b, 0xca, 0x74, 0x4f,
+	0x2d, 0x11, 0x92, 0x80, 0xb8, 0x5c, 0x0f, 0xc3, 0x46, 0x29, 0x49, 0x2c, 0x32, 0x50, 0x4b, 0x7c,
+	0xb9, 0x04, 0xa0, 0x42, 0x70, 0xdf, 0x0a, 0xc9, 0x41, 0x95, 0xe3, 0xf0, 0x3f, 0x3e, 0xe3, 0xcc,
+	0xb8, 0xb8, 0xa1, 0x42, 0x3e, 0x99, 0xc5, 0x25, 0x42, 0x42, 0x50, 0x95, 0x20, 0x0e, 0x4c, 0xb7,
+	0x30, 0x8a, 0x18, 0x44, 0x5f, 0x12, 0x1b, 0x58, 0xcc, 0x18, 0x30, 0x00, 0x76, 0xcc, 0xae, 0x53,
+	0x9a, 0x01, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -185,6 +187,7 @@ const _ = grpc.SupportPackageIsVersion4
 type CounterClient interface {
 	CounterGet(ctx context.Context, in *GetCounterRequest, opts ...grpc.CallOption) (*GetCounterResponse, error)
 	CounterIncrement(ctx context.Context, in *IncrementCounterRequest, opts ...grpc.CallOption) (*GetCounterResponse, error)
+	CounterList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 }
 
 type counterClient struct {
@@ -213,10 +216,20 @@ func (c *counterClient) CounterIncrement(ctx context.Context, in *IncrementCount
 	return out, nil
 }
 
+func (c *counterClient) CounterList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/proto.Counter/CounterList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CounterServer is the server API for Counter service.
 type CounterServer interface {
 	CounterGet(context.Context, *GetCounterRequest) (*GetCounterResponse, error)
 	CounterIncrement(context.Context, *IncrementCounterRequest) (*GetCounterResponse, error)
+	CounterList(context.Context, *ListRequest) (*ListResponse, error)
 }
 
 func RegisterCounterServer(s *grpc.Server, srv CounterServer) {
@@ -259,6 +272,24 @@ func _Counter_CounterIncrement_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Counter_CounterList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CounterServer).CounterList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Counter/CounterList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CounterServer).CounterList(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Counter_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Counter",
 	HandlerType: (*CounterServer)(nil),
@@ -271,6 +302,10 @@ var _Counter_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CounterIncrement",
 			Handler:    _Counter_CounterIncrement_Handler,
 		},
+		{
+			MethodName: "CounterList",
+			Handler:    _Counter_CounterList_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "counter.proto",
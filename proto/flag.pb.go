@@ -156,7 +156,7 @@ func init() {
 func init() { proto.RegisterFile("flag.proto", fileDescriptor_01fdf51d06af45bb) }
 
 var fileDescriptor_01fdf51d06af45bb = []byte{
-	// 175 bytes of a gzipped FileDescriptorProto
+	// 195 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4a, 0xcb, 0x49, 0x4c,
 	0xd7, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0x53, 0x52, 0x3c, 0xc9, 0xf9, 0xb9, 0xb9,
 	0xf9, 0x79, 0x10, 0x41, 0x25, 0x25, 0x2e, 0x3e, 0xb7, 0x9c, 0xc4, 0x74, 0xf7, 0xd4, 0x92, 0xa0,
@@ -164,10 +164,12 @@ var fileDescriptor_01fdf51d06af45bb = []byte{
 	0x46, 0x0d, 0xce, 0x20, 0x10, 0x53, 0xc9, 0x02, 0xa2, 0x26, 0x18, 0x8f, 0x1a, 0x21, 0x11, 0x2e,
 	0xd6, 0xb2, 0xc4, 0x9c, 0xd2, 0x54, 0x09, 0x26, 0x05, 0x46, 0x0d, 0x8e, 0x20, 0x08, 0x47, 0x49,
 	0x85, 0x8b, 0x07, 0xa4, 0x33, 0x28, 0xb5, 0xb8, 0x20, 0x3f, 0xaf, 0x38, 0x15, 0xa1, 0x8a, 0x11,
-	0x49, 0x95, 0x51, 0x2e, 0x17, 0x0b, 0x48, 0x95, 0x90, 0x29, 0x17, 0x3b, 0xd4, 0x2d, 0x42, 0xa2,
-	0x10, 0xe7, 0xe9, 0xa1, 0xba, 0x4d, 0x4a, 0x18, 0x49, 0x18, 0x6e, 0xa8, 0x1e, 0x44, 0x5b, 0x30,
-	0x9a, 0x36, 0x84, 0x73, 0xa5, 0x78, 0xa0, 0xc2, 0xae, 0xb9, 0x05, 0x25, 0x95, 0x49, 0x6c, 0x60,
-	0x8e, 0x31, 0x20, 0x00, 0x00, 0xff, 0xff, 0xb1, 0xfe, 0xaa, 0xb1, 0x1c, 0x01, 0x00, 0x00,
+	0x49, 0x95, 0xd1, 0x22, 0x46, 0x2e, 0x16, 0x90, 0x32, 0x21, 0x53, 0x2e, 0x76, 0xa8, 0x63, 0x84,
+	0x44, 0x21, 0xee, 0xd3, 0x43, 0x75, 0x9c, 0x94, 0x30, 0x92, 0x30, 0xdc, 0x54, 0x3d, 0x88, 0xb6,
+	0x60, 0x34, 0x6d, 0x08, 0xf7, 0x4a, 0xf1, 0x40, 0x85, 0x5d, 0x73, 0x0b, 0x4a, 0x2a, 0x85, 0x8c,
+	0xb9, 0x38, 0x40, 0xf2, 0x3e, 0x99, 0xc5, 0x25, 0x42, 0x42, 0x50, 0x19, 0x10, 0x07, 0xdd, 0x12,
+	0x88, 0x18, 0xc4, 0x92, 0x24, 0x36, 0xb0, 0x98, 0x31, 0x60, 0x00, 0xae, 0x52, 0x0a, 0x5d, 0x52,
+	0x01, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -184,6 +186,7 @@ const _ = grpc.SupportPackageIsVersion4
 type FlagClient interface {
 	FlagGet(ctx context.Context, in *FlagGetRequest, opts ...grpc.CallOption) (*FlagResponse, error)
 	FlagSet(ctx context.Context, in *FlagSetRequest, opts ...grpc.CallOption) (*Empty, error)
+	FlagList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 }
 
 type flagClient struct {
@@ -212,10 +215,20 @@ func (c *flagClient) FlagSet(ctx context.Context, in *FlagSetRequest, opts ...gr
 	return out, nil
 }
 
+func (c *flagClient) FlagList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/proto.Flag/FlagList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FlagServer is the server API for Flag service.
 type FlagServer interface {
 	FlagGet(context.Context, *FlagGetRequest) (*FlagResponse, error)
 	FlagSet(context.Context, *FlagSetRequest) (*Empty, error)
+	FlagList(context.Context, *ListRequest) (*ListResponse, error)
 }
 
 func RegisterFlagServer(s *grpc.Server, srv FlagServer) {
@@ -258,6 +271,24 @@ func _Flag_FlagSet_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Flag_FlagList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlagServer).FlagList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Flag/FlagList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlagServer).FlagList(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Flag_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Flag",
 	HandlerType: (*FlagServer)(nil),
@@ -270,6 +301,10 @@ var _Flag_serviceDesc = grpc.ServiceDesc{
 			MethodName: "FlagSet",
 			Handler:    _Flag_FlagSet_Handler,
 		},
+		{
+			MethodName: "FlagList",
+			Handler:    _Flag_FlagList_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "flag.proto",
@@ -62,7 +62,13 @@ func (m *Location) GetKey() string {
 }
 
 type Value struct {
-	Content              []byte   `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Content     []byte            `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Version     uint64            `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	ContentType string            `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Metadata    map[string]string `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Unix time in nanoseconds
+	Created              int64    `protobuf:"varint,5,opt,name=created,proto3" json:"created,omitempty"`
+	Updated              int64    `protobuf:"varint,6,opt,name=updated,proto3" json:"updated,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -100,6 +106,41 @@ func (m *Value) GetContent() []byte {
 	return nil
 }
 
+func (m *Value) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Value) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *Value) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *Value) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Value) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
 type GetResponse struct {
 	Value                *Value   `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -140,11 +181,13 @@ func (m *GetResponse) GetValue() *Value {
 }
 
 type PutRequest struct {
-	Location             *Location `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
-	Value                *Value    `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+	Location *Location `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Value    *Value    `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Seconds until the key expires, or 0 if it doesn't
+	Ttl                  int32    `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *PutRequest) Reset()         { *m = PutRequest{} }
@@ -186,32 +229,577 @@ func (m *PutRequest) GetValue() *Value {
 	return nil
 }
 
+func (m *PutRequest) GetTtl() int32 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+type PutIfVersionRequest struct {
+	Location             *Location `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Value                *Value    `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Version              uint64    `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Ttl                  int32     `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *PutIfVersionRequest) Reset()         { *m = PutIfVersionRequest{} }
+func (m *PutIfVersionRequest) String() string { return proto.CompactTextString(m) }
+func (*PutIfVersionRequest) ProtoMessage()    {}
+func (*PutIfVersionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{4}
+}
+
+func (m *PutIfVersionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PutIfVersionRequest.Unmarshal(m, b)
+}
+func (m *PutIfVersionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PutIfVersionRequest.Marshal(b, m, deterministic)
+}
+func (m *PutIfVersionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PutIfVersionRequest.Merge(m, src)
+}
+func (m *PutIfVersionRequest) XXX_Size() int {
+	return xxx_messageInfo_PutIfVersionRequest.Size(m)
+}
+func (m *PutIfVersionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PutIfVersionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PutIfVersionRequest proto.InternalMessageInfo
+
+func (m *PutIfVersionRequest) GetLocation() *Location {
+	if m != nil {
+		return m.Location
+	}
+	return nil
+}
+
+func (m *PutIfVersionRequest) GetValue() *Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *PutIfVersionRequest) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *PutIfVersionRequest) GetTtl() int32 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+type ExpireRequest struct {
+	Location             *Location `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Ttl                  int32     `protobuf:"varint,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ExpireRequest) Reset()         { *m = ExpireRequest{} }
+func (m *ExpireRequest) String() string { return proto.CompactTextString(m) }
+func (*ExpireRequest) ProtoMessage()    {}
+func (*ExpireRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{5}
+}
+
+func (m *ExpireRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExpireRequest.Unmarshal(m, b)
+}
+func (m *ExpireRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExpireRequest.Marshal(b, m, deterministic)
+}
+func (m *ExpireRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExpireRequest.Merge(m, src)
+}
+func (m *ExpireRequest) XXX_Size() int {
+	return xxx_messageInfo_ExpireRequest.Size(m)
+}
+func (m *ExpireRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExpireRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExpireRequest proto.InternalMessageInfo
+
+func (m *ExpireRequest) GetLocation() *Location {
+	if m != nil {
+		return m.Location
+	}
+	return nil
+}
+
+func (m *ExpireRequest) GetTtl() int32 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+type TTLResponse struct {
+	// Seconds until the key expires, or -1 if it doesn't
+	Ttl                  int32    `protobuf:"varint,1,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TTLResponse) Reset()         { *m = TTLResponse{} }
+func (m *TTLResponse) String() string { return proto.CompactTextString(m) }
+func (*TTLResponse) ProtoMessage()    {}
+func (*TTLResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{6}
+}
+
+func (m *TTLResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TTLResponse.Unmarshal(m, b)
+}
+func (m *TTLResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TTLResponse.Marshal(b, m, deterministic)
+}
+func (m *TTLResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TTLResponse.Merge(m, src)
+}
+func (m *TTLResponse) XXX_Size() int {
+	return xxx_messageInfo_TTLResponse.Size(m)
+}
+func (m *TTLResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TTLResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TTLResponse proto.InternalMessageInfo
+
+func (m *TTLResponse) GetTtl() int32 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+type DeleteIfVersionRequest struct {
+	Location             *Location `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Version              uint64    `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *DeleteIfVersionRequest) Reset()         { *m = DeleteIfVersionRequest{} }
+func (m *DeleteIfVersionRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteIfVersionRequest) ProtoMessage()    {}
+func (*DeleteIfVersionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{7}
+}
+
+func (m *DeleteIfVersionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteIfVersionRequest.Unmarshal(m, b)
+}
+func (m *DeleteIfVersionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteIfVersionRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteIfVersionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteIfVersionRequest.Merge(m, src)
+}
+func (m *DeleteIfVersionRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteIfVersionRequest.Size(m)
+}
+func (m *DeleteIfVersionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteIfVersionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteIfVersionRequest proto.InternalMessageInfo
+
+func (m *DeleteIfVersionRequest) GetLocation() *Location {
+	if m != nil {
+		return m.Location
+	}
+	return nil
+}
+
+func (m *DeleteIfVersionRequest) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type GetManyRequest struct {
+	Keys                 []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetManyRequest) Reset()         { *m = GetManyRequest{} }
+func (m *GetManyRequest) String() string { return proto.CompactTextString(m) }
+func (*GetManyRequest) ProtoMessage()    {}
+func (*GetManyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{8}
+}
+
+func (m *GetManyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetManyRequest.Unmarshal(m, b)
+}
+func (m *GetManyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetManyRequest.Marshal(b, m, deterministic)
+}
+func (m *GetManyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetManyRequest.Merge(m, src)
+}
+func (m *GetManyRequest) XXX_Size() int {
+	return xxx_messageInfo_GetManyRequest.Size(m)
+}
+func (m *GetManyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetManyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetManyRequest proto.InternalMessageInfo
+
+func (m *GetManyRequest) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type GetManyResponse struct {
+	// One result per requested key, in the same order
+	Results              []*GetResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *GetManyResponse) Reset()         { *m = GetManyResponse{} }
+func (m *GetManyResponse) String() string { return proto.CompactTextString(m) }
+func (*GetManyResponse) ProtoMessage()    {}
+func (*GetManyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{9}
+}
+
+func (m *GetManyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetManyResponse.Unmarshal(m, b)
+}
+func (m *GetManyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetManyResponse.Marshal(b, m, deterministic)
+}
+func (m *GetManyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetManyResponse.Merge(m, src)
+}
+func (m *GetManyResponse) XXX_Size() int {
+	return xxx_messageInfo_GetManyResponse.Size(m)
+}
+func (m *GetManyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetManyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetManyResponse proto.InternalMessageInfo
+
+func (m *GetManyResponse) GetResults() []*GetResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type GetResult struct {
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Found bool   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	// Unset if the key wasn't found
+	Value                *Value   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetResult) Reset()         { *m = GetResult{} }
+func (m *GetResult) String() string { return proto.CompactTextString(m) }
+func (*GetResult) ProtoMessage()    {}
+func (*GetResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{10}
+}
+
+func (m *GetResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetResult.Unmarshal(m, b)
+}
+func (m *GetResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetResult.Marshal(b, m, deterministic)
+}
+func (m *GetResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetResult.Merge(m, src)
+}
+func (m *GetResult) XXX_Size() int {
+	return xxx_messageInfo_GetResult.Size(m)
+}
+func (m *GetResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetResult proto.InternalMessageInfo
+
+func (m *GetResult) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *GetResult) GetFound() bool {
+	if m != nil {
+		return m.Found
+	}
+	return false
+}
+
+func (m *GetResult) GetValue() *Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type PutManyRequest struct {
+	Entries              []*PutRequest `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *PutManyRequest) Reset()         { *m = PutManyRequest{} }
+func (m *PutManyRequest) String() string { return proto.CompactTextString(m) }
+func (*PutManyRequest) ProtoMessage()    {}
+func (*PutManyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{11}
+}
+
+func (m *PutManyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PutManyRequest.Unmarshal(m, b)
+}
+func (m *PutManyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PutManyRequest.Marshal(b, m, deterministic)
+}
+func (m *PutManyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PutManyRequest.Merge(m, src)
+}
+func (m *PutManyRequest) XXX_Size() int {
+	return xxx_messageInfo_PutManyRequest.Size(m)
+}
+func (m *PutManyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PutManyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PutManyRequest proto.InternalMessageInfo
+
+func (m *PutManyRequest) GetEntries() []*PutRequest {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type DeleteManyRequest struct {
+	Keys                 []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteManyRequest) Reset()         { *m = DeleteManyRequest{} }
+func (m *DeleteManyRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteManyRequest) ProtoMessage()    {}
+func (*DeleteManyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{12}
+}
+
+func (m *DeleteManyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteManyRequest.Unmarshal(m, b)
+}
+func (m *DeleteManyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteManyRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteManyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteManyRequest.Merge(m, src)
+}
+func (m *DeleteManyRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteManyRequest.Size(m)
+}
+func (m *DeleteManyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteManyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteManyRequest proto.InternalMessageInfo
+
+func (m *DeleteManyRequest) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type DeleteManyResponse struct {
+	// One result per requested key, in the same order
+	Results              []*DeleteResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *DeleteManyResponse) Reset()         { *m = DeleteManyResponse{} }
+func (m *DeleteManyResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteManyResponse) ProtoMessage()    {}
+func (*DeleteManyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{13}
+}
+
+func (m *DeleteManyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteManyResponse.Unmarshal(m, b)
+}
+func (m *DeleteManyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteManyResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteManyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteManyResponse.Merge(m, src)
+}
+func (m *DeleteManyResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteManyResponse.Size(m)
+}
+func (m *DeleteManyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteManyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteManyResponse proto.InternalMessageInfo
+
+func (m *DeleteManyResponse) GetResults() []*DeleteResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type DeleteResult struct {
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Whether the key existed before it was deleted
+	Found                bool     `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteResult) Reset()         { *m = DeleteResult{} }
+func (m *DeleteResult) String() string { return proto.CompactTextString(m) }
+func (*DeleteResult) ProtoMessage()    {}
+func (*DeleteResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2216fe83c9c12408, []int{14}
+}
+
+func (m *DeleteResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteResult.Unmarshal(m, b)
+}
+func (m *DeleteResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteResult.Marshal(b, m, deterministic)
+}
+func (m *DeleteResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteResult.Merge(m, src)
+}
+func (m *DeleteResult) XXX_Size() int {
+	return xxx_messageInfo_DeleteResult.Size(m)
+}
+func (m *DeleteResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteResult proto.InternalMessageInfo
+
+func (m *DeleteResult) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *DeleteResult) GetFound() bool {
+	if m != nil {
+		return m.Found
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*Location)(nil), "proto.Location")
 	proto.RegisterType((*Value)(nil), "proto.Value")
+	proto.RegisterMapType((map[string]string)(nil), "proto.Value.MetadataEntry")
 	proto.RegisterType((*GetResponse)(nil), "proto.GetResponse")
 	proto.RegisterType((*PutRequest)(nil), "proto.PutRequest")
+	proto.RegisterType((*PutIfVersionRequest)(nil), "proto.PutIfVersionRequest")
+	proto.RegisterType((*ExpireRequest)(nil), "proto.ExpireRequest")
+	proto.RegisterType((*TTLResponse)(nil), "proto.TTLResponse")
+	proto.RegisterType((*DeleteIfVersionRequest)(nil), "proto.DeleteIfVersionRequest")
+	proto.RegisterType((*GetManyRequest)(nil), "proto.GetManyRequest")
+	proto.RegisterType((*GetManyResponse)(nil), "proto.GetManyResponse")
+	proto.RegisterType((*GetResult)(nil), "proto.GetResult")
+	proto.RegisterType((*PutManyRequest)(nil), "proto.PutManyRequest")
+	proto.RegisterType((*DeleteManyRequest)(nil), "proto.DeleteManyRequest")
+	proto.RegisterType((*DeleteManyResponse)(nil), "proto.DeleteManyResponse")
+	proto.RegisterType((*DeleteResult)(nil), "proto.DeleteResult")
 }
 
 func init() { proto.RegisterFile("kv.proto", fileDescriptor_2216fe83c9c12408) }
 
 var fileDescriptor_2216fe83c9c12408 = []byte{
-	// 238 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x8e, 0x3f, 0x4f, 0xc3, 0x30,
-	0x10, 0xc5, 0x95, 0xa2, 0x80, 0xb9, 0x46, 0x02, 0x6e, 0xaa, 0x22, 0x06, 0xf0, 0x54, 0x04, 0xaa,
-	0x44, 0xf9, 0x0a, 0xa0, 0x0e, 0x61, 0xa8, 0x3c, 0x78, 0x63, 0x28, 0xd1, 0x0d, 0xa8, 0x89, 0x2f,
-	0x90, 0x73, 0xa5, 0x7e, 0x02, 0xbe, 0x36, 0x8a, 0xe3, 0x10, 0xfe, 0xa8, 0x93, 0x7d, 0xf7, 0xde,
-	0xfb, 0xdd, 0x03, 0xb5, 0xdd, 0x2d, 0x9a, 0x0f, 0x16, 0xc6, 0x34, 0x3c, 0x79, 0x56, 0x72, 0x5d,
-	0xb3, 0xeb, 0x97, 0xfa, 0x12, 0xd4, 0x33, 0x97, 0x1b, 0x79, 0x63, 0x87, 0xe7, 0x70, 0xb4, 0xa5,
-	0xfd, 0x2c, 0xb9, 0x4a, 0xe6, 0xa7, 0xa6, 0xfb, 0xea, 0x6b, 0x48, 0xed, 0xa6, 0xf2, 0x84, 0x33,
-	0x38, 0x29, 0xd9, 0x09, 0x39, 0x09, 0x72, 0x66, 0x86, 0x51, 0xdf, 0xc3, 0x74, 0x45, 0x62, 0xa8,
-	0x6d, 0xd8, 0xb5, 0x84, 0x1a, 0xd2, 0x5d, 0x97, 0x08, 0xb6, 0xe9, 0x32, 0xeb, 0xcf, 0x2c, 0x02,
-	0xc5, 0xf4, 0x92, 0x7e, 0x01, 0x58, 0x7b, 0x31, 0xf4, 0xee, 0xa9, 0x15, 0xbc, 0x05, 0x55, 0xc5,
-	0x06, 0x31, 0x74, 0x16, 0x43, 0x43, 0x31, 0xf3, 0x6d, 0x18, 0xf1, 0x93, 0x83, 0xf8, 0xe5, 0x67,
-	0x02, 0x93, 0xc2, 0xe2, 0x1d, 0xa4, 0x85, 0x5d, 0x91, 0xe0, 0x5f, 0x5c, 0x8e, 0x71, 0xf1, 0xb3,
-	0xf7, 0xbc, 0x73, 0xaf, 0xbd, 0xe0, 0x45, 0x14, 0xc7, 0x86, 0xf9, 0x70, 0xe5, 0xa9, 0x6e, 0x64,
-	0x8f, 0x37, 0xa0, 0x0a, 0xfb, 0x48, 0x15, 0x09, 0xfd, 0x47, 0xff, 0xb2, 0xbe, 0x1e, 0x87, 0xe1,
-	0xe1, 0x2b, 0x00, 0x00, 0xff, 0xff, 0xb3, 0x0a, 0x9a, 0x23, 0x84, 0x01, 0x00, 0x00,
+	// 678 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x53, 0xdd, 0x6a, 0xdb, 0x4c,
+	0x10, 0x45, 0x96, 0x95, 0xd8, 0x63, 0xe7, 0x6f, 0x93, 0x2f, 0xe8, 0x13, 0x2d, 0x75, 0x45, 0xa1,
+	0x6e, 0xd2, 0x9a, 0xc4, 0x85, 0x50, 0x52, 0x02, 0x2d, 0x69, 0x08, 0xc5, 0x4e, 0x31, 0x8b, 0x51,
+	0x2f, 0x83, 0x12, 0x4f, 0xc0, 0xc4, 0x96, 0x54, 0x69, 0x15, 0xaa, 0x27, 0xe8, 0x7d, 0x5f, 0xa2,
+	0xaf, 0x59, 0xf6, 0xcf, 0xfa, 0x49, 0x42, 0x42, 0x4b, 0xaf, 0xa4, 0xd9, 0x39, 0x67, 0xe6, 0xec,
+	0x99, 0x59, 0x68, 0x5c, 0xdf, 0xf4, 0xa2, 0x38, 0x64, 0x21, 0xb1, 0xc4, 0xc7, 0x69, 0x5f, 0x86,
+	0xf3, 0x79, 0x18, 0xc8, 0x43, 0xf7, 0x09, 0x34, 0x86, 0xe1, 0xa5, 0xcf, 0xa6, 0x61, 0x40, 0xd6,
+	0xc1, 0xbc, 0xc6, 0xcc, 0x36, 0x3a, 0x46, 0xb7, 0x49, 0xf9, 0xaf, 0xfb, 0xa3, 0x06, 0x96, 0xe7,
+	0xcf, 0x52, 0x24, 0x36, 0x2c, 0x5f, 0x86, 0x01, 0xc3, 0x80, 0x89, 0x7c, 0x9b, 0xea, 0x90, 0x67,
+	0x6e, 0x30, 0x4e, 0xa6, 0x61, 0x60, 0xd7, 0x3a, 0x46, 0xb7, 0x4e, 0x75, 0x48, 0x9e, 0x43, 0x5b,
+	0x81, 0xce, 0x59, 0x16, 0xa1, 0x6d, 0x8a, 0xc2, 0x2d, 0x75, 0x36, 0xce, 0x22, 0x24, 0x07, 0xd0,
+	0x98, 0x23, 0xf3, 0x27, 0x3e, 0xf3, 0xed, 0x7a, 0xc7, 0xec, 0xb6, 0xfa, 0x8e, 0x14, 0xd6, 0x13,
+	0x6d, 0x7b, 0x67, 0x2a, 0x79, 0x12, 0xb0, 0x38, 0xa3, 0x0b, 0xac, 0x90, 0x13, 0xa3, 0xcf, 0x70,
+	0x62, 0x5b, 0x1d, 0xa3, 0x6b, 0x52, 0x1d, 0xf2, 0x4c, 0x1a, 0x4d, 0x44, 0x66, 0x49, 0x66, 0x54,
+	0xe8, 0xbc, 0x87, 0x95, 0x52, 0xb9, 0xdb, 0xf7, 0x25, 0x5b, 0x60, 0xdd, 0xf0, 0xbe, 0xe2, 0x26,
+	0x4d, 0x2a, 0x83, 0xc3, 0xda, 0x3b, 0xc3, 0xdd, 0x87, 0xd6, 0x29, 0x32, 0x8a, 0x49, 0x14, 0x06,
+	0x09, 0x12, 0x57, 0x03, 0x39, 0xb9, 0xd5, 0x6f, 0x17, 0x45, 0x2b, 0x9a, 0x1b, 0x02, 0x8c, 0x52,
+	0x46, 0xf1, 0x5b, 0x8a, 0x09, 0x23, 0xbb, 0xd0, 0x98, 0x29, 0xa3, 0x15, 0x69, 0x4d, 0x91, 0xb4,
+	0xff, 0x74, 0x01, 0x20, 0x6e, 0x51, 0xc7, 0xdd, 0xe5, 0xb9, 0x7a, 0xc6, 0x66, 0xc2, 0x54, 0x8b,
+	0xf2, 0x5f, 0xf7, 0xa7, 0x01, 0x9b, 0xa3, 0x94, 0x7d, 0xbe, 0xf2, 0xe4, 0x00, 0xfe, 0x59, 0xeb,
+	0xc2, 0xc8, 0xcd, 0xf2, 0xc8, 0x95, 0xa8, 0x7a, 0x2e, 0xea, 0x0b, 0xac, 0x9c, 0x7c, 0x8f, 0xa6,
+	0x31, 0xfe, 0x91, 0x1a, 0x55, 0xaf, 0x96, 0xd7, 0x7b, 0x06, 0xad, 0xf1, 0x78, 0xb8, 0x18, 0x84,
+	0x02, 0x18, 0x39, 0xe0, 0x1c, 0xb6, 0x3f, 0xe1, 0x0c, 0x19, 0xfe, 0x9d, 0x0f, 0xf7, 0xae, 0xb5,
+	0xfb, 0x02, 0x56, 0x4f, 0x91, 0x9d, 0xf9, 0x41, 0xa6, 0x0b, 0x13, 0xa8, 0x5f, 0x63, 0x96, 0xd8,
+	0x46, 0xc7, 0xec, 0x36, 0xa9, 0xf8, 0x77, 0x8f, 0x60, 0x6d, 0x81, 0x52, 0x5a, 0x77, 0x60, 0x39,
+	0xc6, 0x24, 0x9d, 0x31, 0x89, 0x6c, 0xf5, 0xd7, 0x55, 0x7b, 0xb9, 0x59, 0xe9, 0x8c, 0x51, 0x0d,
+	0x70, 0xbf, 0x42, 0x73, 0x71, 0x7a, 0xf7, 0xa2, 0x5e, 0x85, 0x69, 0x30, 0x11, 0xda, 0x1a, 0x54,
+	0x06, 0xf9, 0xec, 0xcc, 0xfb, 0xb7, 0xf2, 0x08, 0x56, 0x47, 0x69, 0x49, 0xfd, 0x2e, 0x2c, 0x63,
+	0xc0, 0xe2, 0x29, 0x6a, 0x59, 0x1b, 0x8a, 0x97, 0x6f, 0x2f, 0xd5, 0x08, 0xf7, 0x25, 0x6c, 0x48,
+	0x77, 0x1f, 0xba, 0xff, 0x31, 0x90, 0x22, 0x50, 0x59, 0xf0, 0xa6, 0x6a, 0xc1, 0xa6, 0xea, 0x25,
+	0xb1, 0x55, 0x17, 0x0e, 0xa0, 0x5d, 0x4c, 0x3c, 0xd6, 0x88, 0xfe, 0x2f, 0x0b, 0x6a, 0x03, 0x8f,
+	0xbc, 0x06, 0x6b, 0xe0, 0x9d, 0x22, 0x23, 0xd5, 0x39, 0x3b, 0xa4, 0xe4, 0xbc, 0xd4, 0xd6, 0xe5,
+	0xe8, 0x51, 0xca, 0xc8, 0xed, 0xfb, 0x3b, 0xda, 0xca, 0x93, 0x79, 0xc4, 0x32, 0x72, 0x08, 0xab,
+	0x02, 0xb9, 0xd8, 0x30, 0xe2, 0xe4, 0x94, 0xea, 0xda, 0x55, 0xb8, 0x7b, 0xb0, 0xa2, 0xb8, 0x1f,
+	0x2f, 0x12, 0x0c, 0x1e, 0xd1, 0xed, 0x15, 0x34, 0x06, 0x9e, 0xb4, 0xe1, 0xf6, 0x45, 0xca, 0xd0,
+	0x0f, 0xb0, 0xa1, 0xa1, 0xb9, 0xb6, 0xa7, 0x25, 0x8b, 0x1f, 0x90, 0x27, 0x2c, 0x1b, 0x8f, 0x87,
+	0xf7, 0x5b, 0x56, 0x7c, 0x7d, 0x3d, 0x2e, 0x4d, 0x3e, 0x6f, 0xb2, 0xa5, 0xeb, 0x14, 0x5f, 0x7b,
+	0xa5, 0xfa, 0x0e, 0x34, 0x07, 0xde, 0x88, 0xf7, 0x4f, 0xd8, 0x43, 0x77, 0xd9, 0x87, 0xa5, 0x81,
+	0x37, 0xe4, 0x40, 0xdd, 0x99, 0x07, 0xba, 0xee, 0x66, 0xe9, 0x4c, 0xc9, 0x39, 0xe4, 0xe5, 0xd5,
+	0xab, 0x23, 0xff, 0xe5, 0x23, 0x2e, 0xec, 0xaa, 0xb3, 0x5d, 0x3d, 0x56, 0xdc, 0x3d, 0x21, 0x2d,
+	0x2d, 0x73, 0xcb, 0x2f, 0xa5, 0x22, 0xf0, 0x18, 0xda, 0xda, 0x6c, 0x41, 0xb2, 0x4b, 0x3e, 0x17,
+	0x79, 0xff, 0xdf, 0x91, 0x91, 0x6d, 0x2f, 0x96, 0x44, 0xe6, 0xed, 0xef, 0x01, 0x00, 0x15, 0xa2,
+	0x64, 0xc6, 0xa7, 0x07, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -228,7 +816,17 @@ const _ = grpc.SupportPackageIsVersion4
 type KVClient interface {
 	KVGet(ctx context.Context, in *Location, opts ...grpc.CallOption) (*GetResponse, error)
 	KVPut(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*Empty, error)
+	KVPutIfVersion(ctx context.Context, in *PutIfVersionRequest, opts ...grpc.CallOption) (*Empty, error)
+	KVPutIfAbsent(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*Empty, error)
 	KVDelete(ctx context.Context, in *Location, opts ...grpc.CallOption) (*Empty, error)
+	KVDeleteIfVersion(ctx context.Context, in *DeleteIfVersionRequest, opts ...grpc.CallOption) (*Empty, error)
+	KVTTL(ctx context.Context, in *Location, opts ...grpc.CallOption) (*TTLResponse, error)
+	KVExpire(ctx context.Context, in *ExpireRequest, opts ...grpc.CallOption) (*Empty, error)
+	KVPersist(ctx context.Context, in *Location, opts ...grpc.CallOption) (*Empty, error)
+	KVList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	KVGetMany(ctx context.Context, in *GetManyRequest, opts ...grpc.CallOption) (*GetManyResponse, error)
+	KVPutMany(ctx context.Context, in *PutManyRequest, opts ...grpc.CallOption) (*Empty, error)
+	KVDeleteMany(ctx context.Context, in *DeleteManyRequest, opts ...grpc.CallOption) (*DeleteManyResponse, error)
 }
 
 type kVClient struct {
@@ -257,6 +855,24 @@ func (c *kVClient) KVPut(ctx context.Context, in *PutRequest, opts ...grpc.CallO
 	return out, nil
 }
 
+func (c *kVClient) KVPutIfVersion(ctx context.Context, in *PutIfVersionRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVPutIfVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kVClient) KVPutIfAbsent(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVPutIfAbsent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *kVClient) KVDelete(ctx context.Context, in *Location, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/proto.KV/KVDelete", in, out, opts...)
@@ -266,11 +882,93 @@ func (c *kVClient) KVDelete(ctx context.Context, in *Location, opts ...grpc.Call
 	return out, nil
 }
 
+func (c *kVClient) KVDeleteIfVersion(ctx context.Context, in *DeleteIfVersionRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVDeleteIfVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kVClient) KVTTL(ctx context.Context, in *Location, opts ...grpc.CallOption) (*TTLResponse, error) {
+	out := new(TTLResponse)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVTTL", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kVClient) KVExpire(ctx context.Context, in *ExpireRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVExpire", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kVClient) KVPersist(ctx context.Context, in *Location, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVPersist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kVClient) KVList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kVClient) KVGetMany(ctx context.Context, in *GetManyRequest, opts ...grpc.CallOption) (*GetManyResponse, error) {
+	out := new(GetManyResponse)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVGetMany", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kVClient) KVPutMany(ctx context.Context, in *PutManyRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVPutMany", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kVClient) KVDeleteMany(ctx context.Context, in *DeleteManyRequest, opts ...grpc.CallOption) (*DeleteManyResponse, error) {
+	out := new(DeleteManyResponse)
+	err := c.cc.Invoke(ctx, "/proto.KV/KVDeleteMany", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // KVServer is the server API for KV service.
 type KVServer interface {
 	KVGet(context.Context, *Location) (*GetResponse, error)
 	KVPut(context.Context, *PutRequest) (*Empty, error)
+	KVPutIfVersion(context.Context, *PutIfVersionRequest) (*Empty, error)
+	KVPutIfAbsent(context.Context, *PutRequest) (*Empty, error)
 	KVDelete(context.Context, *Location) (*Empty, error)
+	KVDeleteIfVersion(context.Context, *DeleteIfVersionRequest) (*Empty, error)
+	KVTTL(context.Context, *Location) (*TTLResponse, error)
+	KVExpire(context.Context, *ExpireRequest) (*Empty, error)
+	KVPersist(context.Context, *Location) (*Empty, error)
+	KVList(context.Context, *ListRequest) (*ListResponse, error)
+	KVGetMany(context.Context, *GetManyRequest) (*GetManyResponse, error)
+	KVPutMany(context.Context, *PutManyRequest) (*Empty, error)
+	KVDeleteMany(context.Context, *DeleteManyRequest) (*DeleteManyResponse, error)
 }
 
 func RegisterKVServer(s *grpc.Server, srv KVServer) {
@@ -313,6 +1011,42 @@ func _KV_KVPut_Handler(srv interface{}, ctx context.Context, dec func(interface{
 	return interceptor(ctx, in, info, handler)
 }
 
+func _KV_KVPutIfVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutIfVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVPutIfVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVPutIfVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVPutIfVersion(ctx, req.(*PutIfVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KV_KVPutIfAbsent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVPutIfAbsent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVPutIfAbsent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVPutIfAbsent(ctx, req.(*PutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _KV_KVDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Location)
 	if err := dec(in); err != nil {
@@ -331,6 +1065,150 @@ func _KV_KVDelete_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _KV_KVDeleteIfVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteIfVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVDeleteIfVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVDeleteIfVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVDeleteIfVersion(ctx, req.(*DeleteIfVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KV_KVTTL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Location)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVTTL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVTTL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVTTL(ctx, req.(*Location))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KV_KVExpire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpireRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVExpire(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVExpire",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVExpire(ctx, req.(*ExpireRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KV_KVPersist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Location)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVPersist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVPersist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVPersist(ctx, req.(*Location))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KV_KVList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVList(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KV_KVGetMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetManyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVGetMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVGetMany",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVGetMany(ctx, req.(*GetManyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KV_KVPutMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutManyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVPutMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVPutMany",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVPutMany(ctx, req.(*PutManyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KV_KVDeleteMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteManyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KVServer).KVDeleteMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.KV/KVDeleteMany",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KVServer).KVDeleteMany(ctx, req.(*DeleteManyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _KV_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.KV",
 	HandlerType: (*KVServer)(nil),
@@ -343,10 +1221,50 @@ var _KV_serviceDesc = grpc.ServiceDesc{
 			MethodName: "KVPut",
 			Handler:    _KV_KVPut_Handler,
 		},
+		{
+			MethodName: "KVPutIfVersion",
+			Handler:    _KV_KVPutIfVersion_Handler,
+		},
+		{
+			MethodName: "KVPutIfAbsent",
+			Handler:    _KV_KVPutIfAbsent_Handler,
+		},
 		{
 			MethodName: "KVDelete",
 			Handler:    _KV_KVDelete_Handler,
 		},
+		{
+			MethodName: "KVDeleteIfVersion",
+			Handler:    _KV_KVDeleteIfVersion_Handler,
+		},
+		{
+			MethodName: "KVTTL",
+			Handler:    _KV_KVTTL_Handler,
+		},
+		{
+			MethodName: "KVExpire",
+			Handler:    _KV_KVExpire_Handler,
+		},
+		{
+			MethodName: "KVPersist",
+			Handler:    _KV_KVPersist_Handler,
+		},
+		{
+			MethodName: "KVList",
+			Handler:    _KV_KVList_Handler,
+		},
+		{
+			MethodName: "KVGetMany",
+			Handler:    _KV_KVGetMany_Handler,
+		},
+		{
+			MethodName: "KVPutMany",
+			Handler:    _KV_KVPutMany_Handler,
+		},
+		{
+			MethodName: "KVDeleteMany",
+			Handler:    _KV_KVDeleteMany_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "kv.proto",
@@ -23,7 +23,10 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type GetSetRequest struct {
-	Set                  string   `protobuf:"bytes,1,opt,name=set,proto3" json:"set,omitempty"`
+	Set string `protobuf:"bytes,1,opt,name=set,proto3" json:"set,omitempty"`
+	// SetGet returns a page of items rather than the whole set if either of these is set
+	Cursor               string   `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -61,9 +64,25 @@ func (m *GetSetRequest) GetSet() string {
 	return ""
 }
 
+func (m *GetSetRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *GetSetRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
 type ModifySetRequest struct {
-	Set                  string   `protobuf:"bytes,1,opt,name=set,proto3" json:"set,omitempty"`
-	Item                 string   `protobuf:"bytes,2,opt,name=item,proto3" json:"item,omitempty"`
+	Set  string `protobuf:"bytes,1,opt,name=set,proto3" json:"set,omitempty"`
+	Item string `protobuf:"bytes,2,opt,name=item,proto3" json:"item,omitempty"`
+	// Respond with changed and size rather than the set's items
+	Summary              bool     `protobuf:"varint,3,opt,name=summary,proto3" json:"summary,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -108,8 +127,20 @@ func (m *ModifySetRequest) GetItem() string {
 	return ""
 }
 
+func (m *ModifySetRequest) GetSummary() bool {
+	if m != nil {
+		return m.Summary
+	}
+	return false
+}
+
 type SetResponse struct {
-	Items                []string `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Items []string `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// The cursor of the next page when paginating, empty if there are no further items
+	NextCursor string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	// Only set for summaries
+	Changed              bool     `protobuf:"varint,3,opt,name=changed,proto3" json:"changed,omitempty"`
+	Size                 int64    `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -147,28 +178,384 @@ func (m *SetResponse) GetItems() []string {
 	return nil
 }
 
+func (m *SetResponse) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
+func (m *SetResponse) GetChanged() bool {
+	if m != nil {
+		return m.Changed
+	}
+	return false
+}
+
+func (m *SetResponse) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type RandomSetRequest struct {
+	Set string `protobuf:"bytes,1,opt,name=set,proto3" json:"set,omitempty"`
+	// As with SRANDMEMBER, a negative count returns that many items, which may repeat
+	Count                int64    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RandomSetRequest) Reset()         { *m = RandomSetRequest{} }
+func (m *RandomSetRequest) String() string { return proto.CompactTextString(m) }
+func (*RandomSetRequest) ProtoMessage()    {}
+func (*RandomSetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d650fd95c5da449, []int{3}
+}
+
+func (m *RandomSetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RandomSetRequest.Unmarshal(m, b)
+}
+func (m *RandomSetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RandomSetRequest.Marshal(b, m, deterministic)
+}
+func (m *RandomSetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RandomSetRequest.Merge(m, src)
+}
+func (m *RandomSetRequest) XXX_Size() int {
+	return xxx_messageInfo_RandomSetRequest.Size(m)
+}
+func (m *RandomSetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RandomSetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RandomSetRequest proto.InternalMessageInfo
+
+func (m *RandomSetRequest) GetSet() string {
+	if m != nil {
+		return m.Set
+	}
+	return ""
+}
+
+func (m *RandomSetRequest) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type PopSetRequest struct {
+	Set                  string   `protobuf:"bytes,1,opt,name=set,proto3" json:"set,omitempty"`
+	Count                int64    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PopSetRequest) Reset()         { *m = PopSetRequest{} }
+func (m *PopSetRequest) String() string { return proto.CompactTextString(m) }
+func (*PopSetRequest) ProtoMessage()    {}
+func (*PopSetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d650fd95c5da449, []int{4}
+}
+
+func (m *PopSetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PopSetRequest.Unmarshal(m, b)
+}
+func (m *PopSetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PopSetRequest.Marshal(b, m, deterministic)
+}
+func (m *PopSetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PopSetRequest.Merge(m, src)
+}
+func (m *PopSetRequest) XXX_Size() int {
+	return xxx_messageInfo_PopSetRequest.Size(m)
+}
+func (m *PopSetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PopSetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PopSetRequest proto.InternalMessageInfo
+
+func (m *PopSetRequest) GetSet() string {
+	if m != nil {
+		return m.Set
+	}
+	return ""
+}
+
+func (m *PopSetRequest) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type SetContainsRequest struct {
+	Set                  string   `protobuf:"bytes,1,opt,name=set,proto3" json:"set,omitempty"`
+	Item                 string   `protobuf:"bytes,2,opt,name=item,proto3" json:"item,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetContainsRequest) Reset()         { *m = SetContainsRequest{} }
+func (m *SetContainsRequest) String() string { return proto.CompactTextString(m) }
+func (*SetContainsRequest) ProtoMessage()    {}
+func (*SetContainsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d650fd95c5da449, []int{5}
+}
+
+func (m *SetContainsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetContainsRequest.Unmarshal(m, b)
+}
+func (m *SetContainsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetContainsRequest.Marshal(b, m, deterministic)
+}
+func (m *SetContainsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetContainsRequest.Merge(m, src)
+}
+func (m *SetContainsRequest) XXX_Size() int {
+	return xxx_messageInfo_SetContainsRequest.Size(m)
+}
+func (m *SetContainsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetContainsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetContainsRequest proto.InternalMessageInfo
+
+func (m *SetContainsRequest) GetSet() string {
+	if m != nil {
+		return m.Set
+	}
+	return ""
+}
+
+func (m *SetContainsRequest) GetItem() string {
+	if m != nil {
+		return m.Item
+	}
+	return ""
+}
+
+type SetContainsResponse struct {
+	Contains             bool     `protobuf:"varint,1,opt,name=contains,proto3" json:"contains,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetContainsResponse) Reset()         { *m = SetContainsResponse{} }
+func (m *SetContainsResponse) String() string { return proto.CompactTextString(m) }
+func (*SetContainsResponse) ProtoMessage()    {}
+func (*SetContainsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d650fd95c5da449, []int{6}
+}
+
+func (m *SetContainsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetContainsResponse.Unmarshal(m, b)
+}
+func (m *SetContainsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetContainsResponse.Marshal(b, m, deterministic)
+}
+func (m *SetContainsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetContainsResponse.Merge(m, src)
+}
+func (m *SetContainsResponse) XXX_Size() int {
+	return xxx_messageInfo_SetContainsResponse.Size(m)
+}
+func (m *SetContainsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetContainsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetContainsResponse proto.InternalMessageInfo
+
+func (m *SetContainsResponse) GetContains() bool {
+	if m != nil {
+		return m.Contains
+	}
+	return false
+}
+
+type SetSizeResponse struct {
+	Size                 int64    `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetSizeResponse) Reset()         { *m = SetSizeResponse{} }
+func (m *SetSizeResponse) String() string { return proto.CompactTextString(m) }
+func (*SetSizeResponse) ProtoMessage()    {}
+func (*SetSizeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d650fd95c5da449, []int{7}
+}
+
+func (m *SetSizeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetSizeResponse.Unmarshal(m, b)
+}
+func (m *SetSizeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetSizeResponse.Marshal(b, m, deterministic)
+}
+func (m *SetSizeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetSizeResponse.Merge(m, src)
+}
+func (m *SetSizeResponse) XXX_Size() int {
+	return xxx_messageInfo_SetSizeResponse.Size(m)
+}
+func (m *SetSizeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetSizeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetSizeResponse proto.InternalMessageInfo
+
+func (m *SetSizeResponse) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type CombineSetsRequest struct {
+	Sets                 []string `protobuf:"bytes,1,rep,name=sets,proto3" json:"sets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CombineSetsRequest) Reset()         { *m = CombineSetsRequest{} }
+func (m *CombineSetsRequest) String() string { return proto.CompactTextString(m) }
+func (*CombineSetsRequest) ProtoMessage()    {}
+func (*CombineSetsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d650fd95c5da449, []int{8}
+}
+
+func (m *CombineSetsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CombineSetsRequest.Unmarshal(m, b)
+}
+func (m *CombineSetsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CombineSetsRequest.Marshal(b, m, deterministic)
+}
+func (m *CombineSetsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CombineSetsRequest.Merge(m, src)
+}
+func (m *CombineSetsRequest) XXX_Size() int {
+	return xxx_messageInfo_CombineSetsRequest.Size(m)
+}
+func (m *CombineSetsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CombineSetsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CombineSetsRequest proto.InternalMessageInfo
+
+func (m *CombineSetsRequest) GetSets() []string {
+	if m != nil {
+		return m.Sets
+	}
+	return nil
+}
+
+type StoreSetsRequest struct {
+	// Replaced by the result, or deleted if the result is empty
+	Destination          string   `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	Sets                 []string `protobuf:"bytes,2,rep,name=sets,proto3" json:"sets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StoreSetsRequest) Reset()         { *m = StoreSetsRequest{} }
+func (m *StoreSetsRequest) String() string { return proto.CompactTextString(m) }
+func (*StoreSetsRequest) ProtoMessage()    {}
+func (*StoreSetsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d650fd95c5da449, []int{9}
+}
+
+func (m *StoreSetsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StoreSetsRequest.Unmarshal(m, b)
+}
+func (m *StoreSetsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StoreSetsRequest.Marshal(b, m, deterministic)
+}
+func (m *StoreSetsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StoreSetsRequest.Merge(m, src)
+}
+func (m *StoreSetsRequest) XXX_Size() int {
+	return xxx_messageInfo_StoreSetsRequest.Size(m)
+}
+func (m *StoreSetsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StoreSetsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StoreSetsRequest proto.InternalMessageInfo
+
+func (m *StoreSetsRequest) GetDestination() string {
+	if m != nil {
+		return m.Destination
+	}
+	return ""
+}
+
+func (m *StoreSetsRequest) GetSets() []string {
+	if m != nil {
+		return m.Sets
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*GetSetRequest)(nil), "proto.GetSetRequest")
 	proto.RegisterType((*ModifySetRequest)(nil), "proto.ModifySetRequest")
 	proto.RegisterType((*SetResponse)(nil), "proto.SetResponse")
+	proto.RegisterType((*RandomSetRequest)(nil), "proto.RandomSetRequest")
+	proto.RegisterType((*PopSetRequest)(nil), "proto.PopSetRequest")
+	proto.RegisterType((*SetContainsRequest)(nil), "proto.SetContainsRequest")
+	proto.RegisterType((*SetContainsResponse)(nil), "proto.SetContainsResponse")
+	proto.RegisterType((*SetSizeResponse)(nil), "proto.SetSizeResponse")
+	proto.RegisterType((*CombineSetsRequest)(nil), "proto.CombineSetsRequest")
+	proto.RegisterType((*StoreSetsRequest)(nil), "proto.StoreSetsRequest")
 }
 
 func init() { proto.RegisterFile("set.proto", fileDescriptor_2d650fd95c5da449) }
 
 var fileDescriptor_2d650fd95c5da449 = []byte{
-	// 187 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2c, 0x4e, 0x2d, 0xd1,
-	0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0x53, 0x4a, 0x8a, 0x5c, 0xbc, 0xee, 0xa9, 0x25,
-	0xc1, 0xa9, 0x25, 0x41, 0xa9, 0x85, 0xa5, 0xa9, 0xc5, 0x25, 0x42, 0x02, 0x5c, 0xcc, 0xc5, 0xa9,
-	0x25, 0x12, 0x8c, 0x0a, 0x8c, 0x1a, 0x9c, 0x41, 0x20, 0xa6, 0x92, 0x05, 0x97, 0x80, 0x6f, 0x7e,
-	0x4a, 0x66, 0x5a, 0x25, 0x3e, 0x55, 0x42, 0x42, 0x5c, 0x2c, 0x99, 0x25, 0xa9, 0xb9, 0x12, 0x4c,
-	0x60, 0x21, 0x30, 0x5b, 0x49, 0x99, 0x8b, 0x1b, 0xac, 0xa7, 0xb8, 0x20, 0x3f, 0xaf, 0x38, 0x55,
-	0x48, 0x84, 0x8b, 0x15, 0x24, 0x5c, 0x2c, 0xc1, 0xa8, 0xc0, 0xac, 0xc1, 0x19, 0x04, 0xe1, 0x18,
-	0xad, 0x62, 0xe4, 0x62, 0x0e, 0x4e, 0x2d, 0x11, 0x32, 0xe2, 0x62, 0x0b, 0x4e, 0x2d, 0x71, 0x4f,
-	0x2d, 0x11, 0x12, 0x81, 0x38, 0x51, 0x0f, 0xc5, 0x61, 0x52, 0x42, 0x50, 0x51, 0x64, 0x13, 0x4d,
-	0xc1, 0x7a, 0x1c, 0x53, 0x52, 0x84, 0xc4, 0xa1, 0xb2, 0xe8, 0x2e, 0xc5, 0xaa, 0xcd, 0x82, 0x8b,
-	0x13, 0xcc, 0xcd, 0xcd, 0x2f, 0x4b, 0x25, 0x49, 0x67, 0x12, 0x1b, 0x58, 0xc8, 0x18, 0x10, 0x00,
-	0x00, 0xff, 0xff, 0xbc, 0x2a, 0xb9, 0x2f, 0x49, 0x01, 0x00, 0x00,
+	// 527 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x52, 0x61, 0x6b, 0x13, 0x41,
+	0x10, 0xe5, 0x7a, 0x4d, 0x9a, 0x4c, 0x12, 0x8c, 0xdb, 0x52, 0xcf, 0x7c, 0x31, 0x1c, 0x08, 0xf9,
+	0x54, 0x30, 0x22, 0xad, 0x05, 0x51, 0x49, 0xa0, 0x0a, 0x8a, 0x65, 0x17, 0x3f, 0x4b, 0x7a, 0x37,
+	0xd1, 0x05, 0x6f, 0x37, 0xde, 0x4e, 0xc4, 0xf6, 0x17, 0xfa, 0xb3, 0xe4, 0xf6, 0xf6, 0x2e, 0x77,
+	0xf1, 0x5a, 0x48, 0x3e, 0xdd, 0xbe, 0xb9, 0x7d, 0x6f, 0xdf, 0xcc, 0x3c, 0xe8, 0x1a, 0xa4, 0xb3,
+	0x55, 0xaa, 0x49, 0xb3, 0x96, 0xfd, 0x8c, 0xfa, 0x91, 0x4e, 0x12, 0xad, 0xf2, 0x62, 0xf8, 0x05,
+	0x06, 0x57, 0x48, 0x02, 0x89, 0xe3, 0xaf, 0x35, 0x1a, 0x62, 0x43, 0xf0, 0x0d, 0x52, 0xe0, 0x8d,
+	0xbd, 0x49, 0x97, 0x67, 0x47, 0x76, 0x0a, 0xed, 0x68, 0x9d, 0x1a, 0x9d, 0x06, 0x07, 0xb6, 0xe8,
+	0x10, 0x3b, 0x81, 0xd6, 0x4f, 0x99, 0x48, 0x0a, 0xfc, 0xb1, 0x37, 0x69, 0xf1, 0x1c, 0x84, 0x1c,
+	0x86, 0x9f, 0x75, 0x2c, 0x97, 0xb7, 0x0f, 0x6a, 0x32, 0x38, 0x94, 0x84, 0x89, 0x53, 0xb4, 0x67,
+	0x16, 0xc0, 0x91, 0x59, 0x27, 0xc9, 0x22, 0xbd, 0xb5, 0x8a, 0x1d, 0x5e, 0xc0, 0x30, 0x85, 0x9e,
+	0x55, 0x33, 0x2b, 0xad, 0x0c, 0x66, 0x0f, 0x67, 0x04, 0x13, 0x78, 0x63, 0x7f, 0xd2, 0xe5, 0x39,
+	0x60, 0xcf, 0xa0, 0xa7, 0xf0, 0x0f, 0x7d, 0xab, 0x79, 0x85, 0xac, 0x34, 0xcb, 0xfd, 0x06, 0x70,
+	0x14, 0xfd, 0x58, 0xa8, 0xef, 0x18, 0x17, 0xfa, 0x0e, 0x66, 0x6e, 0x8c, 0xbc, 0xc3, 0xe0, 0x70,
+	0xec, 0x4d, 0x7c, 0x6e, 0xcf, 0xe1, 0x25, 0x0c, 0xf9, 0x42, 0xc5, 0x3a, 0x79, 0xb0, 0x8f, 0x13,
+	0x68, 0x45, 0x7a, 0xad, 0xc8, 0x3e, 0xe7, 0xf3, 0x1c, 0x84, 0xe7, 0x30, 0xb8, 0xd6, 0xab, 0x3d,
+	0x88, 0x97, 0xc0, 0x04, 0xd2, 0x4c, 0x2b, 0x5a, 0x48, 0x65, 0x76, 0x1a, 0x5f, 0xf8, 0x02, 0x8e,
+	0x6b, 0x5c, 0x37, 0xac, 0x11, 0x74, 0x22, 0x57, 0xb3, 0x0a, 0x1d, 0x5e, 0xe2, 0xf0, 0x39, 0x3c,
+	0x12, 0x48, 0x42, 0xde, 0x61, 0x79, 0xbd, 0x18, 0x85, 0x57, 0x19, 0xc5, 0x04, 0xd8, 0x4c, 0x27,
+	0x37, 0x52, 0xa1, 0x40, 0x2a, 0x5d, 0x65, 0x37, 0x91, 0x8a, 0x25, 0xd8, 0x73, 0xf8, 0x01, 0x86,
+	0x82, 0x74, 0x5a, 0xbb, 0x37, 0x86, 0x5e, 0x8c, 0x86, 0xa4, 0x5a, 0x90, 0xd4, 0xca, 0x75, 0x51,
+	0x2d, 0x95, 0x4a, 0x07, 0x1b, 0xa5, 0xe9, 0xdf, 0x36, 0xf8, 0x02, 0x89, 0x4d, 0xa1, 0x2d, 0x90,
+	0xae, 0xb2, 0x89, 0xe5, 0x89, 0x3d, 0xab, 0xc5, 0x75, 0xc4, 0x5c, 0xb5, 0x9a, 0x8f, 0x57, 0x96,
+	0xf3, 0x3e, 0x8e, 0xd9, 0x13, 0xf7, 0x77, 0x3b, 0x91, 0x8d, 0xb4, 0x0b, 0xe8, 0x5a, 0x98, 0xe8,
+	0xdf, 0xb8, 0x17, 0xd3, 0xc6, 0xa5, 0x64, 0x6e, 0xa7, 0xa7, 0x91, 0x99, 0xb7, 0x77, 0xad, 0x57,
+	0x65, 0x7b, 0xb5, 0xe0, 0x34, 0x72, 0xe6, 0xd0, 0xab, 0x2c, 0x9a, 0x3d, 0xdd, 0x5c, 0xd9, 0x0a,
+	0xce, 0x68, 0xd4, 0xf4, 0xcb, 0xa9, 0x9c, 0xc3, 0x91, 0xdb, 0xfd, 0x3d, 0x93, 0x3d, 0xdd, 0x90,
+	0x6b, 0x09, 0x79, 0x0d, 0x1d, 0x81, 0xf4, 0x55, 0x65, 0x9b, 0x2b, 0xde, 0xfe, 0x3f, 0x1e, 0x8d,
+	0xce, 0xdf, 0x40, 0x5f, 0x20, 0x7d, 0x54, 0x84, 0xa9, 0xc1, 0x88, 0x76, 0xa5, 0x5f, 0x58, 0xcb,
+	0x73, 0xb9, 0x5c, 0xee, 0xca, 0x7c, 0x07, 0x83, 0xc2, 0xb3, 0xcd, 0x67, 0xb9, 0xa4, 0xed, 0xb4,
+	0xde, 0xdb, 0xf5, 0x1c, 0x1e, 0x57, 0xad, 0xef, 0xa9, 0xf2, 0x16, 0xfa, 0xae, 0x83, 0x3d, 0x05,
+	0xa6, 0x76, 0x04, 0x9f, 0xa4, 0x21, 0x56, 0xf4, 0x99, 0x81, 0x82, 0x76, 0x5c, 0xab, 0xe5, 0x9c,
+	0x9b, 0xb6, 0xad, 0xbd, 0xfc, 0x37, 0x00, 0x5f, 0x18, 0xc7, 0x09, 0x0b, 0x06, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -186,6 +573,18 @@ type SetClient interface {
 	SetGet(ctx context.Context, in *GetSetRequest, opts ...grpc.CallOption) (*SetResponse, error)
 	SetAdd(ctx context.Context, in *ModifySetRequest, opts ...grpc.CallOption) (*SetResponse, error)
 	SetRemove(ctx context.Context, in *ModifySetRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	SetRandom(ctx context.Context, in *RandomSetRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	SetPop(ctx context.Context, in *PopSetRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	SetContains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error)
+	SetSize(ctx context.Context, in *GetSetRequest, opts ...grpc.CallOption) (*SetSizeResponse, error)
+	SetUnion(ctx context.Context, in *CombineSetsRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	SetIntersect(ctx context.Context, in *CombineSetsRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	SetDiff(ctx context.Context, in *CombineSetsRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	// The store variants return the size of the destination set
+	SetUnionStore(ctx context.Context, in *StoreSetsRequest, opts ...grpc.CallOption) (*SetSizeResponse, error)
+	SetIntersectStore(ctx context.Context, in *StoreSetsRequest, opts ...grpc.CallOption) (*SetSizeResponse, error)
+	SetDiffStore(ctx context.Context, in *StoreSetsRequest, opts ...grpc.CallOption) (*SetSizeResponse, error)
+	SetList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 }
 
 type setClient struct {
@@ -223,11 +622,122 @@ func (c *setClient) SetRemove(ctx context.Context, in *ModifySetRequest, opts ..
 	return out, nil
 }
 
+func (c *setClient) SetRandom(ctx context.Context, in *RandomSetRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetRandom", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetPop(ctx context.Context, in *PopSetRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetPop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetContains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error) {
+	out := new(SetContainsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetContains", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetSize(ctx context.Context, in *GetSetRequest, opts ...grpc.CallOption) (*SetSizeResponse, error) {
+	out := new(SetSizeResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetSize", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetUnion(ctx context.Context, in *CombineSetsRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetUnion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetIntersect(ctx context.Context, in *CombineSetsRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetIntersect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetDiff(ctx context.Context, in *CombineSetsRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetDiff", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetUnionStore(ctx context.Context, in *StoreSetsRequest, opts ...grpc.CallOption) (*SetSizeResponse, error) {
+	out := new(SetSizeResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetUnionStore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetIntersectStore(ctx context.Context, in *StoreSetsRequest, opts ...grpc.CallOption) (*SetSizeResponse, error) {
+	out := new(SetSizeResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetIntersectStore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetDiffStore(ctx context.Context, in *StoreSetsRequest, opts ...grpc.CallOption) (*SetSizeResponse, error) {
+	out := new(SetSizeResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetDiffStore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *setClient) SetList(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/proto.Set/SetList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SetServer is the server API for Set service.
 type SetServer interface {
 	SetGet(context.Context, *GetSetRequest) (*SetResponse, error)
 	SetAdd(context.Context, *ModifySetRequest) (*SetResponse, error)
 	SetRemove(context.Context, *ModifySetRequest) (*SetResponse, error)
+	SetRandom(context.Context, *RandomSetRequest) (*SetResponse, error)
+	SetPop(context.Context, *PopSetRequest) (*SetResponse, error)
+	SetContains(context.Context, *SetContainsRequest) (*SetContainsResponse, error)
+	SetSize(context.Context, *GetSetRequest) (*SetSizeResponse, error)
+	SetUnion(context.Context, *CombineSetsRequest) (*SetResponse, error)
+	SetIntersect(context.Context, *CombineSetsRequest) (*SetResponse, error)
+	SetDiff(context.Context, *CombineSetsRequest) (*SetResponse, error)
+	// The store variants return the size of the destination set
+	SetUnionStore(context.Context, *StoreSetsRequest) (*SetSizeResponse, error)
+	SetIntersectStore(context.Context, *StoreSetsRequest) (*SetSizeResponse, error)
+	SetDiffStore(context.Context, *StoreSetsRequest) (*SetSizeResponse, error)
+	SetList(context.Context, *ListRequest) (*ListResponse, error)
 }
 
 func RegisterSetServer(s *grpc.Server, srv SetServer) {
@@ -288,6 +798,204 @@ func _Set_SetRemove_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Set_SetRandom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RandomSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetRandom(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetRandom",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetRandom(ctx, req.(*RandomSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetPop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PopSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetPop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetPop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetPop(ctx, req.(*PopSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetContains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetContainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetContains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetContains",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetContains(ctx, req.(*SetContainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetSize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetSize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetSize",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetSize(ctx, req.(*GetSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetUnion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CombineSetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetUnion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetUnion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetUnion(ctx, req.(*CombineSetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetIntersect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CombineSetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetIntersect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetIntersect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetIntersect(ctx, req.(*CombineSetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetDiff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CombineSetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetDiff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetDiff",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetDiff(ctx, req.(*CombineSetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetUnionStore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreSetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetUnionStore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetUnionStore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetUnionStore(ctx, req.(*StoreSetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetIntersectStore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreSetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetIntersectStore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetIntersectStore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetIntersectStore(ctx, req.(*StoreSetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetDiffStore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreSetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetDiffStore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetDiffStore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetDiffStore(ctx, req.(*StoreSetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Set_SetList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SetServer).SetList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Set/SetList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SetServer).SetList(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Set_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Set",
 	HandlerType: (*SetServer)(nil),
@@ -304,6 +1012,50 @@ var _Set_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SetRemove",
 			Handler:    _Set_SetRemove_Handler,
 		},
+		{
+			MethodName: "SetRandom",
+			Handler:    _Set_SetRandom_Handler,
+		},
+		{
+			MethodName: "SetPop",
+			Handler:    _Set_SetPop_Handler,
+		},
+		{
+			MethodName: "SetContains",
+			Handler:    _Set_SetContains_Handler,
+		},
+		{
+			MethodName: "SetSize",
+			Handler:    _Set_SetSize_Handler,
+		},
+		{
+			MethodName: "SetUnion",
+			Handler:    _Set_SetUnion_Handler,
+		},
+		{
+			MethodName: "SetIntersect",
+			Handler:    _Set_SetIntersect_Handler,
+		},
+		{
+			MethodName: "SetDiff",
+			Handler:    _Set_SetDiff_Handler,
+		},
+		{
+			MethodName: "SetUnionStore",
+			Handler:    _Set_SetUnionStore_Handler,
+		},
+		{
+			MethodName: "SetIntersectStore",
+			Handler:    _Set_SetIntersectStore_Handler,
+		},
+		{
+			MethodName: "SetDiffStore",
+			Handler:    _Set_SetDiffStore_Handler,
+		},
+		{
+			MethodName: "SetList",
+			Handler:    _Set_SetList_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "set.proto",
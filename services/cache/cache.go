@@ -6,6 +6,8 @@ type (
 	Cache interface {
 		CacheGet(key string) (string, error)
 		CacheSet(key, value string, ttl int32) error
+		CacheTTL(key string) (int32, error)
+		CacheList(prefix, cursor string, limit int) ([]string, string, error)
 	}
 
 	Item struct {
@@ -3,4 +3,5 @@ package counter
 type Counter interface {
 	CounterIncrement(key string, amount int64) (int64, error)
 	CounterGet(key string) (int64, error)
+	CounterList(prefix, cursor string, limit int) ([]string, string, error)
 }
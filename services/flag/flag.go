@@ -3,4 +3,5 @@ package flag
 type Flag interface {
 	FlagGet(key string) (bool, error)
 	FlagSet(key string, value bool) error
+	FlagList(prefix, cursor string, limit int) ([]string, string, error)
 }
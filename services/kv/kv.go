@@ -0,0 +1,253 @@
+package kv
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/proto"
+)
+
+const (
+	// NoTTL is the TTL reported by KVTTL for keys that don't expire.
+	NoTTL int32 = -1
+	// MaxBatchSize is the maximum number of keys in a single batch operation.
+	MaxBatchSize = 1000
+)
+
+type (
+	// KV stores versioned values. Every write gives the value a new version that's greater than any version the backend
+	// has handed out for the key before, so a version identifies one write of a value. The conditional operations fail
+	// with a purple.ConflictError when the key's current version isn't the expected one.
+	//
+	// Writes take a TTL in seconds after which the key expires, or 0 for a key that doesn't expire. Writing a key
+	// replaces its previous expiry, and changing a key's expiry gives it a new version.
+	KV interface {
+		KVGet(key string) (*Value, error)
+		KVPut(key string, value *Value, ttl int32) error
+		// Writes the value only if the key's current version is version
+		KVPutIfVersion(key string, value *Value, version uint64, ttl int32) error
+		// Writes the value only if the key doesn't exist
+		KVPutIfAbsent(key string, value *Value, ttl int32) error
+		KVDelete(key string) error
+		// Deletes the key only if its current version is version
+		KVDeleteIfVersion(key string, version uint64) error
+		// Returns the remaining TTL of the key in seconds, or NoTTL if it doesn't expire
+		KVTTL(key string) (int32, error)
+		// Makes the key expire ttl seconds from now, which must be positive
+		KVExpire(key string, ttl int32) error
+		// Removes the key's expiry
+		KVPersist(key string) error
+		KVList(prefix, cursor string, limit int) ([]string, string, error)
+		// Returns the values of the keys in the same order, with nil for keys that don't exist
+		KVGetMany(keys []string) ([]*Value, error)
+		// Writes all of the entries or none of them
+		KVPutMany(entries []*Entry) error
+		// Deletes the keys and reports for each of them whether it existed
+		KVDeleteMany(keys []string) ([]bool, error)
+	}
+
+	// Entry is a key to write with KVPutMany, along with its value and TTL.
+	Entry struct {
+		Key   string
+		Value *Value
+		TTL   int32
+	}
+
+	Value struct {
+		Content []byte `json:"content"`
+		// The media type of the content, e.g. application/json
+		ContentType string `json:"content_type,omitempty"`
+		// User-defined attributes of the value
+		Metadata map[string]string `json:"metadata,omitempty"`
+		// The version and timestamps are set by the backend when the value is read; they're ignored when writing
+		Version uint64 `json:"version"`
+		// When the key was first written since it last didn't exist
+		Created time.Time `json:"created"`
+		// When the value was last written
+		Updated time.Time `json:"updated"`
+	}
+
+	// The part of a stored value that's encoded as JSON ahead of the content
+	header struct {
+		ContentType string            `json:"t,omitempty"`
+		Metadata    map[string]string `json:"m,omitempty"`
+		Created     int64             `json:"c"`
+		Updated     int64             `json:"u"`
+	}
+)
+
+func (v *Value) Proto() *proto.Value {
+	return &proto.Value{
+		Content:     v.Content,
+		ContentType: v.ContentType,
+		Metadata:    v.Metadata,
+		Version:     v.Version,
+		Created:     UnixNano(v.Created),
+		Updated:     UnixNano(v.Updated),
+	}
+}
+
+// FromProto converts a value sent over gRPC into the content type, metadata, and content to write.
+func FromProto(v *proto.Value) *Value {
+	return &Value{
+		Content:     v.GetContent(),
+		ContentType: v.GetContentType(),
+		Metadata:    v.GetMetadata(),
+	}
+}
+
+// Stamped returns a copy of the value to store in place of current, which is nil if the key doesn't exist, written at
+// now. The creation time carries over from current, and the metadata is copied so that the caller can't modify the
+// stored value. The version is left for the backend to set.
+func (v *Value) Stamped(current *Value, now time.Time) *Value {
+	now = now.Round(0).UTC()
+
+	stored := &Value{
+		Content:     v.Content,
+		ContentType: v.ContentType,
+		Created:     now,
+		Updated:     now,
+	}
+
+	if len(v.Metadata) > 0 {
+		stored.Metadata = make(map[string]string, len(v.Metadata))
+
+		for k, val := range v.Metadata {
+			stored.Metadata[k] = val
+		}
+	}
+
+	if current != nil {
+		stored.Created = current.Created
+	}
+
+	return stored
+}
+
+// AsBytes encodes everything but the version, which backends keep separately: a length-prefixed JSON header with the
+// content type, metadata, and timestamps, followed by the raw content.
+func (v *Value) AsBytes() ([]byte, error) {
+	h, err := json.Marshal(&header{
+		ContentType: v.ContentType,
+		Metadata:    v.Metadata,
+		Created:     UnixNano(v.Created),
+		Updated:     UnixNano(v.Updated),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bs := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(h)+len(v.Content))
+	bs = append(bs[:binary.PutUvarint(bs, uint64(len(h)))], h...)
+
+	return append(bs, v.Content...), nil
+}
+
+// BytesToValue decodes a value encoded by AsBytes.
+func BytesToValue(bs []byte) (*Value, error) {
+	n, read := binary.Uvarint(bs)
+	if read <= 0 || uint64(len(bs)-read) < n {
+		return nil, purple.ErrInvalidKVValue
+	}
+
+	var h header
+
+	if err := json.Unmarshal(bs[read:read+int(n)], &h); err != nil {
+		return nil, err
+	}
+
+	return &Value{
+		Content:     append([]byte{}, bs[read+int(n):]...),
+		ContentType: h.ContentType,
+		Metadata:    h.Metadata,
+		Created:     FromUnixNano(h.Created),
+		Updated:     FromUnixNano(h.Updated),
+	}, nil
+}
+
+// CheckTTL validates the TTL of a write.
+func CheckTTL(ttl int32) error {
+	if ttl < 0 {
+		return purple.ErrNegativeKVTTL
+	}
+
+	return nil
+}
+
+// CheckBatch validates the number of keys in a batch operation.
+func CheckBatch(size int) error {
+	if size > MaxBatchSize {
+		return purple.ErrKVBatchTooLarge
+	}
+
+	return nil
+}
+
+// CheckEntries validates the entries passed to KVPutMany.
+func CheckEntries(entries []*Entry) error {
+	if err := CheckBatch(len(entries)); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := CheckTTL(e.TTL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckExpireTTL validates the TTL passed to KVExpire.
+func CheckExpireTTL(ttl int32) error {
+	if ttl <= 0 {
+		return purple.ErrNonPositiveKVTTL
+	}
+
+	return nil
+}
+
+// Expiry returns when a key written at now with the TTL expires, or the zero time if the TTL is 0.
+func Expiry(ttl int32, now time.Time) time.Time {
+	if ttl == 0 {
+		return time.Time{}
+	}
+
+	return now.Add(time.Duration(ttl) * time.Second)
+}
+
+// Remaining converts an expiry into the remaining TTL reported by KVTTL. The TTL is rounded up so that it's at least 1
+// until the key has expired, and is NoTTL for the zero time.
+func Remaining(expires, now time.Time) int32 {
+	if expires.IsZero() {
+		return NoTTL
+	}
+
+	remaining := (expires.Sub(now) + time.Second - 1) / time.Second
+	if remaining < 1 {
+		remaining = 1
+	}
+
+	return int32(remaining)
+}
+
+// UnixNano converts a timestamp into Unix time in nanoseconds, which is how timestamps are stored and sent over gRPC.
+// The zero time converts to 0.
+func UnixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+// FromUnixNano is the inverse of UnixNano.
+func FromUnixNano(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, ns).UTC()
+}
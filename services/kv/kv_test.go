@@ -0,0 +1,71 @@
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/proto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKVTypes(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("Value", func(t *testing.T) {
+		content := []byte("some test content")
+
+		val := &Value{
+			Content: content,
+			Version: 3,
+		}
+
+		is.Equal(val.Proto(), &proto.Value{Content: content, Version: 3})
+		is.Equal(val.Content, content)
+	})
+
+	t.Run("Stamped", func(t *testing.T) {
+		created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		now := created.Add(time.Hour)
+
+		val := &Value{Content: []byte("content"), Metadata: map[string]string{"a": "b"}}
+
+		stored := val.Stamped(nil, created)
+		is.Equal(stored.Created, created)
+		is.Equal(stored.Updated, created)
+
+		stored = val.Stamped(stored, now)
+		is.Equal(stored.Created, created)
+		is.Equal(stored.Updated, now)
+
+		// The stored metadata doesn't change along with the caller's
+		val.Metadata["a"] = "c"
+		is.Equal(stored.Metadata, map[string]string{"a": "b"})
+
+		is.Equal(stored.Proto().Created, created.UnixNano())
+		is.Zero((&Value{}).Proto().Created)
+	})
+
+	t.Run("Encoding", func(t *testing.T) {
+		now := time.Date(2020, 1, 1, 0, 0, 0, 1, time.UTC)
+
+		val := &Value{
+			Content:     []byte{0, 1, 2},
+			ContentType: "application/octet-stream",
+			Metadata:    map[string]string{"a": "b"},
+			Created:     now,
+			Updated:     now,
+		}
+
+		bs, err := val.AsBytes()
+		is.NoError(err)
+
+		decoded, err := BytesToValue(bs)
+		is.NoError(err)
+		is.Equal(decoded, val)
+
+		_, err = BytesToValue(bs[:1])
+		is.Equal(err, purple.ErrInvalidKVValue)
+	})
+}
@@ -0,0 +1,96 @@
+// Package services defines the interface that every purple backend implements and the registry that backends are
+// selected from. Backends outside this module implement Service and call Register in an init function.
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/services/cache"
+	"github.com/purpledb/purple/services/counter"
+	"github.com/purpledb/purple/services/flag"
+	"github.com/purpledb/purple/services/kv"
+	"github.com/purpledb/purple/services/set"
+	"github.com/purpledb/purple/services/txn"
+)
+
+type (
+	// Service is implemented by every backend
+	Service interface {
+		cache.Cache
+		counter.Counter
+		flag.Flag
+		kv.KV
+		set.Set
+		txn.Txn
+
+		Close() error
+		Flush() error
+		Name() string
+	}
+
+	// Factory instantiates a backend Service from the server config. Built-in backends read their settings from their
+	// own typed section of the config (e.g. cfg.Disk) while other backends read theirs from cfg.Section(name).
+	Factory func(cfg *purple.ServerConfig) (Service, error)
+)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+func init() {
+	purple.SetBackendRegistry(Registered)
+}
+
+// Register makes a backend available under the supplied name, which can then be selected using the --backend flag.
+// Backends are usually registered in an init function. Register panics if the name is already taken or the factory
+// is nil.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("services: Register factory is nil")
+	}
+
+	if _, dup := factories[name]; dup {
+		panic(fmt.Sprintf("services: Register called twice for backend %s", name))
+	}
+
+	factories[name] = factory
+}
+
+// Registered reports whether a backend is registered under the supplied name.
+func Registered(name string) bool {
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Lookup returns the factory registered under the supplied name.
+func Lookup(name string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	factory, ok := factories[name]
+
+	return factory, ok
+}
+
+// Names returns the sorted names of all registered backends.
+func Names() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+
+	for name := range factories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,75 @@
+package set
+
+import "github.com/purpledb/purple"
+
+// The operations that combine sets
+const (
+	Union     = "union"
+	Intersect = "intersect"
+	Diff      = "diff"
+)
+
+// Sets that don't exist are treated as empty by every operation except SetGet and SetRemove, which report them as not
+// found (Redis and SQLite excepted, as they don't tell empty sets from missing ones): SetGetPage returns an empty page,
+// SetRemoveSummary reports no change and a size of 0, and SetRandom and SetPop return no items. The operations that
+// combine sets return their items in lexicographic order (Redis excepted).
+type Set interface {
+	SetGet(set string) ([]string, error)
+	// Up to limit items that sort after cursor, along with the cursor of the next page, which is empty once there are
+	// no further items. Redis pages through sets with SSCAN, so its items aren't sorted and its cursors are opaque.
+	SetGetPage(set, cursor string, limit int) ([]string, string, error)
+	SetAdd(set, item string) ([]string, error)
+	SetRemove(set, item string) ([]string, error)
+	// Like SetAdd and SetRemove, but only report whether the set changed and how many items it has afterwards
+	SetAddSummary(set, item string) (*Summary, error)
+	SetRemoveSummary(set, item string) (*Summary, error)
+	// As with SRANDMEMBER, up to count distinct random items or, if count is negative, -count items that may repeat
+	SetRandom(set string, count int) ([]string, error)
+	// As with SPOP, removes up to count random items and returns them
+	SetPop(set string, count int) ([]string, error)
+	SetContains(set, item string) (bool, error)
+	SetSize(set string) (int, error)
+	// The items that are in any of the sets
+	SetUnion(sets ...string) ([]string, error)
+	// The items that are in all of the sets
+	SetIntersect(sets ...string) ([]string, error)
+	// The items of the first set that are in none of the others
+	SetDiff(sets ...string) ([]string, error)
+	// The store variants replace the destination set with the result and return its size. As in Redis, an empty
+	// result deletes the destination.
+	SetUnionStore(dest string, sets ...string) (int, error)
+	SetIntersectStore(dest string, sets ...string) (int, error)
+	SetDiffStore(dest string, sets ...string) (int, error)
+	SetList(prefix, cursor string, limit int) ([]string, string, error)
+}
+
+// Summary describes the outcome of adding or removing an item without listing the set's items.
+type Summary struct {
+	// Whether the item was added or removed, as opposed to already being in the set or not being in it
+	Changed bool
+	Size    int
+}
+
+// CheckPopCount checks the number of items to pop.
+func CheckPopCount(count int) error {
+	if count < 0 {
+		return purple.ErrNegativeSetPopCount
+	}
+
+	return nil
+}
+
+// CheckSets checks the names of the sets to combine and of the destination, if there is one.
+func CheckSets(sets []string, dest ...string) error {
+	if len(sets) == 0 {
+		return purple.ErrNoSets
+	}
+
+	for _, s := range append(sets, dest...) {
+		if s == "" {
+			return purple.ErrNoKey
+		}
+	}
+
+	return nil
+}
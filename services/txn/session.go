@@ -0,0 +1,139 @@
+package txn
+
+import (
+	"github.com/purpledb/purple"
+)
+
+type (
+	// Session is an optimistic transaction. Its reads are made right away, while its writes are held back by the
+	// client and applied by Commit, which fails with a purple.ConflictError if anything the session read has changed
+	// since. Sessions aren't safe for concurrent use.
+	Session interface {
+		// Read runs validated read operations and remembers what they read.
+		Read(ops []*Op) ([]*Result, error)
+		// Commit applies validated operations if none of the session's reads is out of date. The session is over
+		// afterwards, whether or not the commit succeeds.
+		Commit(ops []*Op) ([]*Result, error)
+		// Abort ends the session without applying anything.
+		Abort()
+	}
+
+	// Read is a read made by a session along with its result.
+	Read struct {
+		Op     *Op
+		Result *Result
+	}
+)
+
+// NewSession returns a session for backends without conflict detection of their own. The session reads using read,
+// usually the backend's Txn, and commits using commit, which must check the reads (see Commit and Check) and apply the
+// operations atomically.
+func NewSession(
+	read func(ops []*Op) ([]*Result, error),
+	commit func(reads []*Read, ops []*Op) ([]*Result, error),
+) Session {
+	return &session{
+		read:   read,
+		commit: commit,
+	}
+}
+
+type session struct {
+	read   func(ops []*Op) ([]*Result, error)
+	commit func(reads []*Read, ops []*Op) ([]*Result, error)
+	reads  []*Read
+}
+
+func (s *session) Read(ops []*Op) ([]*Result, error) {
+	results, err := s.read(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		s.reads = append(s.reads, &Read{Op: op, Result: results[i]})
+	}
+
+	return results, nil
+}
+
+func (s *session) Commit(ops []*Op) ([]*Result, error) {
+	return s.commit(s.reads, ops)
+}
+
+// Nothing is held between reads, so there's nothing to release.
+func (s *session) Abort() {}
+
+// Commit reads again what a session read within a backend's transaction and applies the operations if nothing has
+// changed. Like Apply, the backend must discard the transaction if it fails.
+func Commit(st Store, reads []*Read, ops []*Op) ([]*Result, error) {
+	current, err := Apply(st, ReadOps(reads))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Check(reads, current); err != nil {
+		return nil, err
+	}
+
+	return Apply(st, ops)
+}
+
+// ReadOps returns the operations of the reads.
+func ReadOps(reads []*Read) []*Op {
+	ops := make([]*Op, len(reads))
+
+	for i, r := range reads {
+		ops[i] = r.Op
+	}
+
+	return ops
+}
+
+// Check compares the results of the reads with the results of making them again, in the same order, and returns a
+// conflict error for the first key that has changed. KV values are compared by version and everything else by value.
+func Check(reads []*Read, current []*Result) error {
+	for i, r := range reads {
+		if !unchanged(r.Op.Kind, r.Result, current[i]) {
+			return purple.Conflict(r.Op.Key)
+		}
+	}
+
+	return nil
+}
+
+func unchanged(kind string, then, now *Result) bool {
+	switch kind {
+	case KVGet:
+		return then.Found == now.Found && (!then.Found || then.Value.Version == now.Value.Version)
+	case CounterGet:
+		return then.Count == now.Count
+	case FlagGet:
+		return then.Flag == now.Flag
+	case SetGet:
+		return then.Found == now.Found && sameItems(then.Items, now.Items)
+	}
+
+	return false
+}
+
+// Some backends return the items of sets in no particular order.
+func sameItems(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	in := make(map[string]bool, len(a))
+
+	for _, item := range a {
+		in[item] = true
+	}
+
+	for _, item := range b {
+		if !in[item] {
+			return false
+		}
+	}
+
+	return true
+}
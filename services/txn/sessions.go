@@ -0,0 +1,215 @@
+package txn
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/purpledb/purple"
+)
+
+// SessionTimeout is how long a session may go unused before it's aborted.
+const SessionTimeout = time.Minute
+
+// Sessions keeps track of a server's open sessions by ID. Sessions that have been idle for longer than the timeout are
+// aborted, either when they're next used or when another session begins. Once the maximum number of sessions is open,
+// no more can begin until some of them end, which keeps clients from holding ever more read sets in memory.
+type Sessions struct {
+	mu       sync.Mutex
+	sessions map[string]*openSession
+	timeout  time.Duration
+	// Zero means no limit
+	max int
+}
+
+type openSession struct {
+	// Held while the session is in use, as sessions aren't safe for concurrent use
+	mu sync.Mutex
+	s  Session
+	// When the session was last used, guarded by Sessions.mu
+	used time.Time
+	done bool
+}
+
+func NewSessions(timeout time.Duration, max int) *Sessions {
+	return &Sessions{
+		sessions: make(map[string]*openSession),
+		timeout:  timeout,
+		max:      max,
+	}
+}
+
+// Begin starts a session on the backend and returns its ID, unless the maximum number of sessions is open.
+func (ss *Sessions) Begin(t Txn) (string, error) {
+	ss.abortIdle()
+
+	if ss.full() {
+		return "", purple.ErrTooManyTxnSessions
+	}
+
+	s, err := t.TxnBegin()
+	if err != nil {
+		return "", err
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		s.Abort()
+		return "", err
+	}
+
+	ss.mu.Lock()
+
+	// Other sessions may have begun while this one did
+	if ss.max > 0 && len(ss.sessions) >= ss.max {
+		ss.mu.Unlock()
+		s.Abort()
+		return "", purple.ErrTooManyTxnSessions
+	}
+
+	ss.sessions[id] = &openSession{s: s, used: time.Now()}
+	ss.mu.Unlock()
+
+	return id, nil
+}
+
+func (ss *Sessions) full() bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	return ss.max > 0 && len(ss.sessions) >= ss.max
+}
+
+// Read runs read operations within the session.
+func (ss *Sessions) Read(id string, ops []*Op) ([]*Result, error) {
+	if err := ValidateReads(ops); err != nil {
+		return nil, err
+	}
+
+	o, err := ss.get(id, false)
+	if err != nil {
+		return nil, err
+	}
+	defer o.mu.Unlock()
+
+	return o.s.Read(ops)
+}
+
+// Commit ends the session by applying the operations, unless the session's reads are out of date. Invalid operations
+// end the session too.
+func (ss *Sessions) Commit(id string, ops []*Op) ([]*Result, error) {
+	o, err := ss.get(id, true)
+	if err != nil {
+		return nil, err
+	}
+	defer o.mu.Unlock()
+
+	o.done = true
+
+	if err := Validate(ops); err != nil {
+		o.s.Abort()
+		return nil, err
+	}
+
+	return o.s.Commit(ops)
+}
+
+// Abort ends the session without applying anything.
+func (ss *Sessions) Abort(id string) error {
+	o, err := ss.get(id, true)
+	if err != nil {
+		return err
+	}
+
+	o.abort()
+	o.mu.Unlock()
+
+	return nil
+}
+
+// Close aborts every open session.
+func (ss *Sessions) Close() {
+	ss.mu.Lock()
+	open := ss.sessions
+	ss.sessions = make(map[string]*openSession)
+	ss.mu.Unlock()
+
+	for _, o := range open {
+		o.mu.Lock()
+		o.abort()
+		o.mu.Unlock()
+	}
+}
+
+// Returns the session locked, after removing it if remove is true. Sessions that have been idle for too long are
+// aborted and reported as not found.
+func (ss *Sessions) get(id string, remove bool) (*openSession, error) {
+	ss.mu.Lock()
+
+	o, ok := ss.sessions[id]
+	if !ok {
+		ss.mu.Unlock()
+		return nil, purple.ErrTxnSessionNotFound
+	}
+
+	idle := time.Since(o.used) > ss.timeout
+
+	if idle || remove {
+		delete(ss.sessions, id)
+	} else {
+		o.used = time.Now()
+	}
+
+	ss.mu.Unlock()
+
+	o.mu.Lock()
+
+	// A concurrent call may have ended the session while this one waited for it
+	if o.done || idle {
+		o.abort()
+		o.mu.Unlock()
+		return nil, purple.ErrTxnSessionNotFound
+	}
+
+	return o, nil
+}
+
+func (ss *Sessions) abortIdle() {
+	var idle []*openSession
+
+	ss.mu.Lock()
+
+	for id, o := range ss.sessions {
+		if time.Since(o.used) > ss.timeout {
+			idle = append(idle, o)
+			delete(ss.sessions, id)
+		}
+	}
+
+	ss.mu.Unlock()
+
+	for _, o := range idle {
+		o.mu.Lock()
+		o.abort()
+		o.mu.Unlock()
+	}
+}
+
+// The caller must hold the session's lock.
+func (o *openSession) abort() {
+	if !o.done {
+		o.done = true
+		o.s.Abort()
+	}
+}
+
+func newSessionID() (string, error) {
+	bs := make([]byte, 16)
+
+	if _, err := rand.Read(bs); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bs), nil
+}
@@ -0,0 +1,151 @@
+package txn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/purpledb/purple"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Hands out sessions that count how often they're used.
+type fakeTxn struct {
+	sessions []*fakeSession
+}
+
+func (f *fakeTxn) Txn(ops []*Op) ([]*Result, error) {
+	return make([]*Result, len(ops)), nil
+}
+
+func (f *fakeTxn) TxnBegin() (Session, error) {
+	s := &fakeSession{}
+	f.sessions = append(f.sessions, s)
+	return s, nil
+}
+
+type fakeSession struct {
+	reads, commits, aborts int
+}
+
+func (s *fakeSession) Read(ops []*Op) ([]*Result, error) {
+	s.reads++
+	return make([]*Result, len(ops)), nil
+}
+
+func (s *fakeSession) Commit(ops []*Op) ([]*Result, error) {
+	s.commits++
+	return make([]*Result, len(ops)), nil
+}
+
+func (s *fakeSession) Abort() {
+	s.aborts++
+}
+
+func TestSessions(t *testing.T) {
+	is := assert.New(t)
+
+	read := []*Op{{Kind: CounterGet, Key: "count"}}
+	write := []*Op{{Kind: CounterIncr, Key: "count", Amount: 1}}
+
+	t.Run("Lifecycle", func(t *testing.T) {
+		f := &fakeTxn{}
+		ss := NewSessions(time.Minute, 0)
+
+		id, err := ss.Begin(f)
+		is.NoError(err)
+		is.Len(id, 32)
+
+		_, err = ss.Read(id, read)
+		is.NoError(err)
+		_, err = ss.Read(id, write)
+		is.Equal(err, purple.ErrTxnSessionWrite)
+		_, err = ss.Commit(id, write)
+		is.NoError(err)
+
+		_, err = ss.Read(id, read)
+		is.Equal(err, purple.ErrTxnSessionNotFound)
+		_, err = ss.Commit(id, write)
+		is.Equal(err, purple.ErrTxnSessionNotFound)
+		is.Equal(ss.Abort(id), purple.ErrTxnSessionNotFound)
+
+		is.Equal(f.sessions[0].reads, 1)
+		is.Equal(f.sessions[0].commits, 1)
+		is.Equal(f.sessions[0].aborts, 0)
+
+		// Invalid commits end the session without applying anything
+		id, err = ss.Begin(f)
+		is.NoError(err)
+		_, err = ss.Commit(id, []*Op{{Kind: "counter.reset", Key: "count"}})
+		is.Equal(err, purple.ErrUnknownTxnOp)
+		is.Equal(f.sessions[1].commits, 0)
+		is.Equal(f.sessions[1].aborts, 1)
+		is.Equal(ss.Abort(id), purple.ErrTxnSessionNotFound)
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		f := &fakeTxn{}
+		ss := NewSessions(10*time.Millisecond, 0)
+
+		idle, err := ss.Begin(f)
+		is.NoError(err)
+		expired, err := ss.Begin(f)
+		is.NoError(err)
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, err = ss.Read(expired, read)
+		is.Equal(err, purple.ErrTxnSessionNotFound)
+		is.Equal(f.sessions[1].aborts, 1)
+
+		// Beginning another session aborts the idle ones
+		open, err := ss.Begin(f)
+		is.NoError(err)
+		is.Equal(f.sessions[0].aborts, 1)
+		_, err = ss.Read(idle, read)
+		is.Equal(err, purple.ErrTxnSessionNotFound)
+		is.Equal(f.sessions[0].aborts, 1)
+
+		ss.Close()
+		is.Equal(f.sessions[2].aborts, 1)
+		is.Equal(ss.Abort(open), purple.ErrTxnSessionNotFound)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		f := &fakeTxn{}
+		ss := NewSessions(time.Minute, 2)
+
+		first, err := ss.Begin(f)
+		is.NoError(err)
+		_, err = ss.Begin(f)
+		is.NoError(err)
+
+		// No session is started on the backend once the limit is reached
+		_, err = ss.Begin(f)
+		is.Equal(err, purple.ErrTooManyTxnSessions)
+		is.Len(f.sessions, 2)
+
+		// Ending a session makes room for another
+		is.NoError(ss.Abort(first))
+		_, err = ss.Begin(f)
+		is.NoError(err)
+		_, err = ss.Begin(f)
+		is.Equal(err, purple.ErrTooManyTxnSessions)
+
+		ss.Close()
+	})
+}
+
+func TestCheck(t *testing.T) {
+	is := assert.New(t)
+
+	reads := []*Read{
+		{Op: &Op{Kind: SetGet, Key: "set"}, Result: &Result{Found: true, Items: []string{"a", "b"}}},
+		{Op: &Op{Kind: CounterGet, Key: "count"}, Result: &Result{Count: 1}},
+	}
+
+	// The items of sets may come back in any order
+	is.NoError(Check(reads, []*Result{{Found: true, Items: []string{"b", "a"}}, {Count: 1}}))
+	is.Equal(Check(reads, []*Result{{Found: true, Items: []string{"a"}}, {Count: 1}}), purple.Conflict("set"))
+	is.Equal(Check(reads, []*Result{{Found: true, Items: []string{"a", "b"}}, {Count: 2}}), purple.Conflict("count"))
+}
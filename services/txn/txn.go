@@ -0,0 +1,179 @@
+package txn
+
+import (
+	"github.com/purpledb/purple"
+	"github.com/purpledb/purple/services/kv"
+)
+
+// The kinds of operations a transaction can hold
+const (
+	KVGet       = "kv.get"
+	KVPut       = "kv.put"
+	KVDelete    = "kv.delete"
+	CounterGet  = "counter.get"
+	CounterIncr = "counter.incr"
+	FlagGet     = "flag.get"
+	FlagSet     = "flag.set"
+	SetGet      = "set.get"
+	SetAdd      = "set.add"
+	SetRemove   = "set.remove"
+)
+
+// MaxOperations is the maximum number of operations in a transaction.
+const MaxOperations = 1000
+
+type (
+	// Txn applies an ordered list of operations on KV values, counters, flags, and sets all or none. Each operation
+	// sees the effects of the operations before it, and other clients see either all of them or none.
+	Txn interface {
+		Txn(ops []*Op) ([]*Result, error)
+		// TxnBegin starts an optimistic transaction session (see Session).
+		TxnBegin() (Session, error)
+	}
+
+	Op struct {
+		// One of the operation kinds above
+		Kind string
+		// The key, counter, flag, or set that the operation applies to
+		Key string
+		// The value and TTL written by kv.put
+		Value *kv.Value
+		TTL   int32
+		// The amount added by counter.incr
+		Amount int64
+		// The value written by flag.set
+		Flag bool
+		// The item added by set.add or removed by set.remove
+		Item string
+	}
+
+	// Result holds the outcome of an operation. Only the fields that apply to its kind are set.
+	Result struct {
+		// Whether the key existed, for kv.get, kv.delete, set.get, and set.remove. Missing keys don't fail the
+		// transaction.
+		Found bool
+		// The value read by kv.get
+		Value *kv.Value
+		// The counter's value after counter.get or counter.incr
+		Count int64
+		// The flag's value after flag.get
+		Flag bool
+		// The set's items after set.get, set.add, or set.remove
+		Items []string
+	}
+
+	// Store is a backend's view of a running transaction, which Apply runs the operations against. Reads see the
+	// transaction's own writes.
+	Store interface {
+		// Returns nil if the key doesn't exist
+		KVGet(key string) (*kv.Value, error)
+		KVPut(key string, value *kv.Value, ttl int32) error
+		// Reports whether the key existed
+		KVDelete(key string) (bool, error)
+		CounterGet(key string) (int64, error)
+		CounterIncrement(key string, amount int64) (int64, error)
+		FlagGet(key string) (bool, error)
+		FlagSet(key string, value bool) error
+		// Reports whether the set exists along with its items
+		SetGet(set string) ([]string, bool, error)
+		SetAdd(set, item string) ([]string, error)
+		// Leaves a set that doesn't exist alone and reports whether it existed
+		SetRemove(set, item string) ([]string, bool, error)
+	}
+)
+
+// Validate checks a transaction before any of it is applied, so that backends can rely on its operations being well
+// formed.
+func Validate(ops []*Op) error {
+	if len(ops) > MaxOperations {
+		return purple.ErrTxnTooLarge
+	}
+
+	for _, op := range ops {
+		if op.Key == "" {
+			return purple.ErrNoKey
+		}
+
+		switch op.Kind {
+		case KVPut:
+			if op.Value == nil {
+				return purple.ErrNoValue
+			}
+
+			if err := kv.CheckTTL(op.TTL); err != nil {
+				return err
+			}
+		case KVGet, KVDelete, CounterGet, CounterIncr, FlagGet, FlagSet, SetGet, SetAdd, SetRemove:
+		default:
+			return purple.ErrUnknownTxnOp
+		}
+	}
+
+	return nil
+}
+
+// ValidateReads is like Validate, but also rejects writes, which sessions only accept when they're committed.
+func ValidateReads(ops []*Op) error {
+	if err := Validate(ops); err != nil {
+		return err
+	}
+
+	for _, op := range ops {
+		if !isRead(op.Kind) {
+			return purple.ErrTxnSessionWrite
+		}
+	}
+
+	return nil
+}
+
+func isRead(kind string) bool {
+	return kind == KVGet || kind == CounterGet || kind == FlagGet || kind == SetGet
+}
+
+// Apply runs validated operations in order against a backend's transaction and collects their results. It stops at the
+// first error, after which the backend must discard the transaction.
+func Apply(st Store, ops []*Op) ([]*Result, error) {
+	results := make([]*Result, len(ops))
+
+	for i, op := range ops {
+		var (
+			r   = &Result{}
+			err error
+		)
+
+		switch op.Kind {
+		case KVGet:
+			r.Value, err = st.KVGet(op.Key)
+			r.Found = r.Value != nil
+		case KVPut:
+			err = st.KVPut(op.Key, op.Value, op.TTL)
+		case KVDelete:
+			r.Found, err = st.KVDelete(op.Key)
+		case CounterGet:
+			r.Count, err = st.CounterGet(op.Key)
+		case CounterIncr:
+			r.Count, err = st.CounterIncrement(op.Key, op.Amount)
+		case FlagGet:
+			r.Flag, err = st.FlagGet(op.Key)
+		case FlagSet:
+			err = st.FlagSet(op.Key, op.Flag)
+		case SetGet:
+			r.Items, r.Found, err = st.SetGet(op.Key)
+		case SetAdd:
+			r.Items, err = st.SetAdd(op.Key, op.Item)
+		case SetRemove:
+			r.Items, r.Found, err = st.SetRemove(op.Key, op.Item)
+		default:
+			err = purple.ErrUnknownTxnOp
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		results[i] = r
+	}
+
+	return results, nil
+}